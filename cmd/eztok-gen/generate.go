@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// Holds the options of Generate.
+type GenerateOptions struct {
+	// The package of the generated Go code.
+	Package string
+	// The prefix of the generated Tokenizer type and its tables.
+	Name string
+	// The name of the spec, mentioned in the header of the generated Go code.
+	SpecName string
+}
+
+// Returns formatted Go code declaring a DFA-based Tokenizer for spec.
+func Generate(spec *eztok.LexerSpec, options GenerateOptions) ([]byte, error) {
+	gen := &generator{strings.Builder{}, options, lowerFirst(options.Name)}
+	if err := gen.generate(spec); err != nil {
+		return nil, err
+	}
+	code, err := format.Source([]byte(gen.code.String()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot format the generated code: %v", err)
+	}
+	return code, nil
+}
+
+// Holds the state of a call to Generate.
+type generator struct {
+	code    strings.Builder
+	options GenerateOptions
+	// The prefix of the unexported declarations.
+	prefix string
+}
+
+// Writes formatted code.
+func (gen *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&gen.code, format, args...)
+}
+
+// Writes the code of the Tokenizer for spec.
+func (gen *generator) generate(spec *eztok.LexerSpec) error {
+	// Build a DFA per mode, and lay their states out one after another.
+	modeIndices := map[string]int{}
+	for i, mode := range spec.Modes {
+		modeIndices[mode.Name] = i
+	}
+	dfas := make([]*eztok.DFA, len(spec.Modes))
+	modeStarts := make([]int, len(spec.Modes))
+	ruleStarts := make([]int, len(spec.Modes))
+	stateCount, ruleCount := 0, 0
+	for i, mode := range spec.Modes {
+		patterns := make([]string, len(mode.Rules))
+		for j, rule := range mode.Rules {
+			patterns[j] = rule.Regex()
+		}
+		dfa, err := eztok.BuildDFA(patterns...)
+		if err != nil {
+			return fmt.Errorf("cannot build the DFA of mode '%v': %v", mode.Name, err)
+		}
+		dfas[i] = dfa
+		modeStarts[i], ruleStarts[i] = stateCount, ruleCount
+		stateCount += len(dfa.States)
+		ruleCount += len(mode.Rules)
+	}
+
+	name, prefix := gen.options.Name, gen.prefix
+	gen.printf("// Code generated by eztok-gen from %v; DO NOT EDIT.\n\n", gen.options.SpecName)
+	gen.printf("package %v\n\n", gen.options.Package)
+	gen.printf("import (\n\"fmt\"\n\"io\"\n\n\"github.com/tpillow/eztok/pkg/eztok\"\n)\n\n")
+
+	gen.printf("// A Tokenizer generated from the lexer spec %v. Produces the same Token\n", gen.options.SpecName)
+	gen.printf("// objects as an eztok.SpecTokenizer of the spec.\n")
+	gen.printf("type %vTokenizer struct{}\n\n", name)
+	gen.printf("// Returns a new %vTokenizer.\n", name)
+	gen.printf("func New%vTokenizer() *%vTokenizer {\nreturn &%vTokenizer{}\n}\n\n", name, name, name)
+
+	gen.printf(`// For as long as eztok.IsEOF does not return true, applies the rule of the
+// current mode with the longest match. A rule that does not skip its match
+// generates a Token whose Origin is the start of the match. Returns an error if
+// no rule matches, or a rule pops the initial mode.
+func (tizer *%[1]vTokenizer) Tokenize(ctx eztok.Context) ([]*eztok.Token, error) {
+	toks := []*eztok.Token{}
+	modeStack := []int{0}
+	for !eztok.IsEOF(ctx) {
+		mode := modeStack[len(modeStack)-1]
+		ruleIndex, value, err := %[2]vMatch(%[2]vModeStarts[mode], %[2]vModeRuleStarts[mode], ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ruleIndex < 0 {
+			if err := %[2]vContextErr(ctx); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("unexpected rune '%%c' at %%v", ctx.PeekRune(0), ctx.GetNextOrigin().ToString())
+		}
+		rule := &%[2]vRules[ruleIndex]
+		origin := ctx.GetNextOrigin()
+		eztok.SkipRunes(ctx, len(value))
+		if !rule.skip {
+			tok := eztok.NewToken(rule.tokenType, string(value))
+			tok.Origin = origin
+			toks = append(toks, tok)
+		}
+
+		switch rule.action {
+		case eztok.LexerActionPush:
+			modeStack = append(modeStack, rule.pushMode)
+		case eztok.LexerActionPop:
+			if len(modeStack) <= 1 {
+				return nil, fmt.Errorf("cannot pop the initial mode '%%v' at %%v", %[3]q, origin.ToString())
+			}
+			modeStack = modeStack[:len(modeStack)-1]
+		}
+	}
+	if err := %[2]vContextErr(ctx); err != nil {
+		return nil, err
+	}
+	return toks, nil
+}
+
+// Returns the index of the rule with the longest match against the next runes
+// in the Context, starting from the DFA state start, and the matched runes.
+// Ties go to the earliest rule. Returns a rule index of -1 if no rule matches.
+// Returns an error if the DFA could still match a longer run of runes after the
+// maximum lookahead of the Context.
+func %[2]vMatch(start int, ruleStart int, ctx eztok.Context) (int, []rune, error) {
+	reader := eztok.NewPeekRuneReader(ctx)
+	runes := []rune{}
+	bestRule, bestCount := -1, 0
+	for state := start; ; {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return -1, nil, err
+		}
+		state = %[2]vNext(state, r)
+		if state < 0 {
+			break
+		}
+		runes = append(runes, r)
+		if accept := %[2]vAccepts[state]; accept >= 0 {
+			bestRule, bestCount = ruleStart+accept, len(runes)
+		}
+	}
+	return bestRule, runes[:bestCount], nil
+}
+
+// Returns the DFA state that follows state on the rune r, or -1 if there is
+// none.
+func %[2]vNext(state int, r rune) int {
+	if r >= 0 && r < 128 {
+		return int(%[2]vASCII[state][r]) - 1
+	}
+	transitions := %[2]vTransitions[state]
+	lo, hi := 0, len(transitions)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if transitions[mid].hi < r {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(transitions) && transitions[lo].lo <= r {
+		return transitions[lo].next
+	}
+	return -1
+}
+
+// Returns the error held by ctx if it is an eztok.FallibleContext. Returns nil
+// otherwise.
+func %[2]vContextErr(ctx eztok.Context) error {
+	if fallible, ok := ctx.(eztok.FallibleContext); ok {
+		return fallible.Err()
+	}
+	return nil
+}
+
+// Represents a rule of the lexer spec.
+type %[2]vRule struct {
+	tokenType eztok.TokenType
+	skip      bool
+	action    eztok.LexerAction
+	pushMode  int
+}
+
+// Represents a transition between DFA states on the runes lo to hi.
+type %[2]vTransition struct {
+	lo, hi rune
+	next   int
+}
+
+`, name, prefix, spec.Modes[0].Name)
+
+	gen.printf("// The rules of every mode, one mode after another.\n")
+	gen.printf("var %vRules = []%vRule{\n", prefix, prefix)
+	for _, mode := range spec.Modes {
+		for _, rule := range mode.Rules {
+			gen.printf("{%q, %v, %v, %v}, // %v: %v\n", string(rule.TokenType), rule.Kind == eztok.LexerRuleSkip,
+				lexerActionName(rule.Action), modeIndices[rule.PushMode], rule.Origin.ToString(),
+				strings.ReplaceAll(rule.Regex(), "\n", `\n`))
+		}
+	}
+	gen.printf("}\n\n")
+
+	gen.printf("// The first DFA state of each mode.\n")
+	gen.printf("var %vModeStarts = %#v\n\n", prefix, modeStarts)
+	gen.printf("// The index in %vRules of the first rule of each mode.\n", prefix)
+	gen.printf("var %vModeRuleStarts = %#v\n\n", prefix, ruleStarts)
+
+	gen.printf("// The index of the rule (within its mode) accepted by each DFA state, or -1.\n")
+	gen.printf("var %vAccepts = []int{", prefix)
+	for _, dfa := range dfas {
+		for _, state := range dfa.States {
+			if len(state.Accepts) > 0 {
+				gen.printf("%v, ", state.Accepts[0])
+			} else {
+				gen.printf("-1, ")
+			}
+		}
+	}
+	gen.printf("}\n\n")
+
+	// The states of every mode share the ASCII table, so its type must fit the
+	// total number of states.
+	asciiType := "int16"
+	if stateCount > math.MaxInt16 {
+		asciiType = "int32"
+	}
+	gen.printf("// The next DFA state (plus 1, so 0 means none) of each DFA state on each\n// ASCII rune.\n")
+	gen.printf("var %vASCII = [][128]%v{\n", prefix, asciiType)
+	for i, dfa := range dfas {
+		for _, state := range dfa.States {
+			gen.printf("{")
+			for _, transition := range state.Transitions {
+				for r := transition.Lo; r <= transition.Hi && r < 128; r++ {
+					gen.printf("%v: %v, ", r, modeStarts[i]+transition.Next+1)
+				}
+			}
+			gen.printf("},\n")
+		}
+	}
+	gen.printf("}\n\n")
+
+	gen.printf("// The transitions of each DFA state on non-ASCII runes, sorted by rune.\n")
+	gen.printf("var %vTransitions = [][]%vTransition{\n", prefix, prefix)
+	for i, dfa := range dfas {
+		for _, state := range dfa.States {
+			gen.printf("{")
+			for _, transition := range state.Transitions {
+				if transition.Hi >= 128 {
+					lo := transition.Lo
+					if lo < 128 {
+						lo = 128
+					}
+					gen.printf("{%v, %v, %v}, ", lo, transition.Hi, modeStarts[i]+transition.Next)
+				}
+			}
+			gen.printf("},\n")
+		}
+	}
+	gen.printf("}\n")
+	return nil
+}
+
+// Returns the Go expression of action.
+func lexerActionName(action eztok.LexerAction) string {
+	switch action {
+	case eztok.LexerActionPush:
+		return "eztok.LexerActionPush"
+	case eztok.LexerActionPop:
+		return "eztok.LexerActionPop"
+	}
+	return "eztok.LexerActionNone"
+}
+
+// Returns str with its first rune in lower case.
+func lowerFirst(str string) string {
+	for i, r := range str {
+		return string(unicode.ToLower(r)) + str[i+len(string(r)):]
+	}
+	return str
+}
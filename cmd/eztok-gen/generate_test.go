@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// A lexer spec whose modes are pushed and popped by nested parentheses and
+// strings.
+const generatorTestSpec = `
+skip     /[ \t\n]+/
+keywords keyword if
+regex    identifier /[a-z]+/
+literal  open "(" push paren
+literal  close ")" pop
+literal  quote "\"" push string
+
+[paren]
+skip     " "
+regex    number /[0-9]+/
+literal  open "(" push paren
+literal  close ")" pop
+literal  quote "\"" push string
+
+[string]
+regex    text /[^"]+/
+literal  quote "\"" pop
+`
+
+// The inputs tokenized by TestGeneratedTokenizer, each within a Context whose
+// maximum lookahead is generatorTestLookahead.
+var generatorTestInputs = []string{
+	"",
+	"if iff i\nf",
+	"f(1 (2)3)x",
+	`a "b c" d`,
+	`"x(" (1 "y")`,
+	"((((1)))) z",
+	"(1",
+	"abcdefgh",
+	"(1234567)",
+	"abcdefghi",
+	"(123456789)",
+	`"long string"`,
+	"a+b",
+	"(1 a)",
+	")",
+	"(1))",
+}
+
+// The maximum lookahead of the Context of each input of generatorTestInputs.
+const generatorTestLookahead = 8
+
+// The program that tokenizes each input of generatorTestInputs with the
+// generated TestTokenizer, and prints a JSON generatorTestResult for each. The
+// %v is replaced with generatorTestLookahead.
+const generatorTestMain = `package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+func main() {
+	var inputs []string
+	if err := json.NewDecoder(os.Stdin).Decode(&inputs); err != nil {
+		panic(err)
+	}
+	type result struct {
+		Tokens []*eztok.Token
+		Err    string
+	}
+	results := []result{}
+	for _, input := range inputs {
+		ctx := eztok.NewReaderContextWithOptions(bufio.NewReader(strings.NewReader(input)), "test",
+			eztok.ReaderContextOptions{MaxLookahead: %v})
+		toks, err := NewTestTokenizer().Tokenize(ctx)
+		if err != nil {
+			results = append(results, result{nil, err.Error()})
+		} else {
+			results = append(results, result{toks, ""})
+		}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+		panic(err)
+	}
+}
+`
+
+// Represents the result of tokenizing an input of generatorTestInputs.
+type generatorTestResult struct {
+	Tokens []*eztok.Token
+	Err    string
+}
+
+// Generates a Tokenizer from generatorTestSpec, builds it, and checks that it
+// returns the same Token objects (including their Origin) and errors as an
+// eztok.SpecTokenizer of the spec for each of generatorTestInputs.
+func TestGeneratedTokenizer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building the generated Tokenizer is slow")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skipf("cannot find the go tool: %v", err)
+	}
+	spec, err := eztok.ParseLexerSpec(generatorTestSpec, "test.lex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Generate(spec, GenerateOptions{"main", "Test", "test.lex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	moduleRoot, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	goMod := "module eztokgentest\n\ngo 1.19\n\nrequire github.com/tpillow/eztok v0.0.0\n\n" +
+		"replace github.com/tpillow/eztok => " + moduleRoot + "\n"
+	files := map[string]string{"go.mod": goMod, "test_lexer.go": string(code), "main.go": fmt.Sprintf(generatorTestMain, generatorTestLookahead)}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inputs, err := json.Marshal(generatorTestInputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(inputs)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("running the generated Tokenizer failed: %v\n%v", err, stderr.String())
+	}
+	var got []generatorTestResult
+	if err := json.Unmarshal(output, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(generatorTestInputs) {
+		t.Fatalf("the generated Tokenizer returned %v results, want %v", len(got), len(generatorTestInputs))
+	}
+
+	tizer := eztok.NewSpecTokenizer(spec)
+	for i, input := range generatorTestInputs {
+		ctx := eztok.NewReaderContextWithOptions(bufio.NewReader(strings.NewReader(input)), "test",
+			eztok.ReaderContextOptions{MaxLookahead: generatorTestLookahead})
+		toks, err := tizer.Tokenize(ctx)
+		want := generatorTestResult{toks, ""}
+		if err != nil {
+			want = generatorTestResult{nil, err.Error()}
+		}
+		if !reflect.DeepEqual(got[i], want) {
+			t.Errorf("tokenizing %q returned %v, want %v", input, generatorTestString(got[i]), generatorTestString(want))
+		}
+	}
+}
+
+// Returns a string of the Token objects or error of result.
+func generatorTestString(result generatorTestResult) string {
+	if result.Err != "" {
+		return "error: " + result.Err
+	}
+	strs := make([]string, len(result.Tokens))
+	for i, tok := range result.Tokens {
+		strs[i] = tok.ToString() + " at " + tok.Origin.ToString()
+	}
+	return "[" + strings.Join(strs, ", ") + "]"
+}
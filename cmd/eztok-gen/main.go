@@ -0,0 +1,86 @@
+// Command eztok-gen generates a Go file holding a DFA-based Tokenizer from a
+// lexer spec (see eztok.ParseLexerSpec). The generated Tokenizer produces the
+// same Token objects, Origin information and errors as an eztok.SpecTokenizer
+// of the same spec, without trying each rule in turn. The one exception is the
+// maximum lookahead of the Context (see eztok.ReaderContextOptions): the
+// regular expressions of an eztok.SpecTokenizer read up to two runes beyond
+// their match, so it fails a match ending just before the maximum lookahead,
+// which the generated Tokenizer returns.
+//
+// Usage:
+//
+//	eztok-gen [-o output.go] [-package name] [-name Name] spec.lex
+//
+// The generated file declares a NameTokenizer type and a NewNameTokenizer
+// function, where Name defaults to the spec file name in title case.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+func main() {
+	output := flag.String("o", "", "the file to write the generated Go code to (defaults to stdout)")
+	packageName := flag.String("package", "main", "the package of the generated Go code")
+	name := flag.String("name", "", "the prefix of the generated Tokenizer type (defaults to the spec file name)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: eztok-gen [flags] spec.lex\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	specPath := flag.Arg(0)
+	if *name == "" {
+		*name = nameFromPath(specPath)
+	}
+
+	spec, err := eztok.LoadLexerSpec(specPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "eztok-gen: %v\n", err)
+		os.Exit(1)
+	}
+	code, err := Generate(spec, GenerateOptions{*packageName, *name, filepath.Base(specPath)})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "eztok-gen: %v\n", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.Write(code)
+	} else if err := os.WriteFile(*output, code, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "eztok-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// Returns the spec file name at path in title case, without its extension
+// and without any rune that cannot be part of a Go identifier.
+func nameFromPath(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name := strings.Builder{}
+	upper := true
+	for _, r := range base {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	if name.Len() <= 0 || !unicode.IsLetter([]rune(name.String())[0]) {
+		return "Spec" + name.String()
+	}
+	return name.String()
+}
@@ -39,6 +39,11 @@ func main() {
 		// following the rules just described.
 		eztok.IdentifierNode,
 	)
+	// Check that no node is placed after a node that always matches first
+	// (such as a StringMatchNode placed after IdentifierNode).
+	if err := tokenizer.Validate(); err != nil {
+		log.Fatalf("Error while validating the tokenizer: %v", err)
+	}
 
 	// Tokenize an example string using our tokenizer above.
 	tokens, err := eztok.TokenizeString(tokenizer, `
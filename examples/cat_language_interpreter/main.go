@@ -68,9 +68,10 @@ var tokenizer = eztok.NewInOrderNodeTokenizer(
 	eztok.NewStringMatchNode(TokenTypeCat, "cat"),
 )
 
-// A helper to tokenize a string of text into our cat-language.
-func Tokenize(content string) []*eztok.Token {
-	toks, err := eztok.TokenizeString(tokenizer, content)
+// A helper to tokenize a string of text into our cat-language. The name is used
+// as the Origin.Name of each token.
+func Tokenize(name string, content string) []*eztok.Token {
+	toks, err := eztok.TokenizeNamedString(tokenizer, content, name)
 	if err != nil {
 		log.Fatalf("Error tokenizing: %v.", err)
 	}
@@ -79,7 +80,7 @@ func Tokenize(content string) []*eztok.Token {
 
 // Interpret an expression (just print it).
 // An expression is any of: <integer>, <float>, <string>, cat
-func RunExpression(trav *eztok.TokenTraverser) {
+func RunExpression(trav *eztok.SpliceTraverser) {
 	// An expression in the cat-language is only ever one token long.
 	tok := trav.NextToken()
 	if tok == nil {
@@ -92,13 +93,13 @@ func RunExpression(trav *eztok.TokenTraverser) {
 	case TokenTypeCat:
 		fmt.Printf("Expression: (^-^)\n")
 	default:
-		log.Fatalf("Expected an expression but got token %v.", tok.ToString())
+		log.Fatalf("Expected an expression but got token %v at %v.", tok.ToString(), tok.Origin.ToString())
 	}
 }
 
 // Interpret a statement.
 // A statement is in the form: <Expression> ;
-func RunStatement(trav *eztok.TokenTraverser) {
+func RunStatement(trav *eztok.SpliceTraverser) {
 	// Simulate the expression
 	RunExpression(trav)
 	// Each expression must end with a semicolon.
@@ -110,7 +111,7 @@ func RunStatement(trav *eztok.TokenTraverser) {
 
 // Interpret an include statement.
 // An include statement is in the form: @include "pathOfCodeToInclude"
-func RunIncludeStatement(trav *eztok.TokenTraverser) {
+func RunIncludeStatement(trav *eztok.SpliceTraverser) {
 	if !eztok.PeekTokenTypeIs(trav, TokenTypeInclude) {
 		log.Fatalf("Expected a '@include' token")
 	}
@@ -126,19 +127,24 @@ func RunIncludeStatement(trav *eztok.TokenTraverser) {
 	if !ok {
 		log.Fatalf("Unknown include path '%v'.", includePath)
 	}
-	// Tokenize the text to include
-	includeToks := Tokenize(includeText)
-	// Prepend all new tokens from the include to the existing tokens to traverse.
-	// The traverser's next token is always at index 0, so prepending will ensure the
-	// included tokens are next to process.
-	trav.Tokens = append(includeToks, trav.Tokens...)
+	// Tokenize the text to include, recording that its tokens were included from
+	// the include path token, so errors can report the full include chain.
+	includeToks := Tokenize(includePath, includeText)
+	eztok.SetIncludedFrom(includeToks, includeStrTok.Origin)
+	// Splice all new tokens from the include in ahead of the existing tokens to
+	// traverse, so the included tokens are next to process. Recursive includes
+	// (e.g. Include1 including itself) are reported as an error.
+	if err := trav.Splice(includePath, includeToks); err != nil {
+		log.Fatalf("Error including '%v': %v.", includePath, err)
+	}
 }
 
 // Interpret a program.
 // A program is 0 or more <IncludeStatement> or <Statement>.
 func RunProgram(toks []*eztok.Token) {
-	// Create a token traverser to go over all tokens.
-	trav := eztok.NewTokenTraverser(toks)
+	// Create a splice traverser to go over all tokens, allowing includes to
+	// splice in more tokens.
+	trav := eztok.NewSpliceTraverser(eztok.TokenizeStringOriginName, toks)
 	// For as long as we have tokens, we must have a statement.
 	for trav.PeekToken(0) != nil {
 		// Check if we might have an include statement.
@@ -155,7 +161,7 @@ func RunProgram(toks []*eztok.Token) {
 // Entry point.
 func main() {
 	// Tokenize the initial program text
-	toks := Tokenize(ProgramText)
+	toks := Tokenize(eztok.TokenizeStringOriginName, ProgramText)
 	// Interpret the program
 	RunProgram(toks)
 }
@@ -0,0 +1,237 @@
+// Code generated by eztok-gen from calc.lex; DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// A Tokenizer generated from the lexer spec calc.lex. Produces the same Token
+// objects as an eztok.SpecTokenizer of the spec.
+type CalcTokenizer struct{}
+
+// Returns a new CalcTokenizer.
+func NewCalcTokenizer() *CalcTokenizer {
+	return &CalcTokenizer{}
+}
+
+// For as long as eztok.IsEOF does not return true, applies the rule of the
+// current mode with the longest match. A rule that does not skip its match
+// generates a Token whose Origin is the start of the match. Returns an error if
+// no rule matches, or a rule pops the initial mode.
+func (tizer *CalcTokenizer) Tokenize(ctx eztok.Context) ([]*eztok.Token, error) {
+	toks := []*eztok.Token{}
+	modeStack := []int{0}
+	for !eztok.IsEOF(ctx) {
+		mode := modeStack[len(modeStack)-1]
+		ruleIndex, value, err := calcMatch(calcModeStarts[mode], calcModeRuleStarts[mode], ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ruleIndex < 0 {
+			if err := calcContextErr(ctx); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("unexpected rune '%c' at %v", ctx.PeekRune(0), ctx.GetNextOrigin().ToString())
+		}
+		rule := &calcRules[ruleIndex]
+		origin := ctx.GetNextOrigin()
+		eztok.SkipRunes(ctx, len(value))
+		if !rule.skip {
+			tok := eztok.NewToken(rule.tokenType, string(value))
+			tok.Origin = origin
+			toks = append(toks, tok)
+		}
+
+		switch rule.action {
+		case eztok.LexerActionPush:
+			modeStack = append(modeStack, rule.pushMode)
+		case eztok.LexerActionPop:
+			if len(modeStack) <= 1 {
+				return nil, fmt.Errorf("cannot pop the initial mode '%v' at %v", "main", origin.ToString())
+			}
+			modeStack = modeStack[:len(modeStack)-1]
+		}
+	}
+	if err := calcContextErr(ctx); err != nil {
+		return nil, err
+	}
+	return toks, nil
+}
+
+// Returns the index of the rule with the longest match against the next runes
+// in the Context, starting from the DFA state start, and the matched runes.
+// Ties go to the earliest rule. Returns a rule index of -1 if no rule matches.
+// Returns an error if the DFA could still match a longer run of runes after the
+// maximum lookahead of the Context.
+func calcMatch(start int, ruleStart int, ctx eztok.Context) (int, []rune, error) {
+	reader := eztok.NewPeekRuneReader(ctx)
+	runes := []rune{}
+	bestRule, bestCount := -1, 0
+	for state := start; ; {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return -1, nil, err
+		}
+		state = calcNext(state, r)
+		if state < 0 {
+			break
+		}
+		runes = append(runes, r)
+		if accept := calcAccepts[state]; accept >= 0 {
+			bestRule, bestCount = ruleStart+accept, len(runes)
+		}
+	}
+	return bestRule, runes[:bestCount], nil
+}
+
+// Returns the DFA state that follows state on the rune r, or -1 if there is
+// none.
+func calcNext(state int, r rune) int {
+	if r >= 0 && r < 128 {
+		return int(calcASCII[state][r]) - 1
+	}
+	transitions := calcTransitions[state]
+	lo, hi := 0, len(transitions)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if transitions[mid].hi < r {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(transitions) && transitions[lo].lo <= r {
+		return transitions[lo].next
+	}
+	return -1
+}
+
+// Returns the error held by ctx if it is an eztok.FallibleContext. Returns nil
+// otherwise.
+func calcContextErr(ctx eztok.Context) error {
+	if fallible, ok := ctx.(eztok.FallibleContext); ok {
+		return fallible.Err()
+	}
+	return nil
+}
+
+// Represents a rule of the lexer spec.
+type calcRule struct {
+	tokenType eztok.TokenType
+	skip      bool
+	action    eztok.LexerAction
+	pushMode  int
+}
+
+// Represents a transition between DFA states on the runes lo to hi.
+type calcTransition struct {
+	lo, hi rune
+	next   int
+}
+
+// The rules of every mode, one mode after another.
+var calcRules = []calcRule{
+	{"", true, eztok.LexerActionNone, 0},               // calc.lex:2:1: [ \t\r\n]+
+	{"", true, eztok.LexerActionNone, 0},               // calc.lex:3:1: //[^\n]*
+	{"keyword", false, eztok.LexerActionNone, 0},       // calc.lex:7:1: let
+	{"keyword", false, eztok.LexerActionNone, 0},       // calc.lex:7:1: print
+	{"identifier", false, eztok.LexerActionNone, 0},    // calc.lex:8:1: [A-Za-z_][A-Za-z0-9_]*
+	{"number", false, eztok.LexerActionNone, 0},        // calc.lex:9:1: [0-9]+(\.[0-9]+)?
+	{"=", false, eztok.LexerActionNone, 0},             // calc.lex:10:1: =
+	{"+", false, eztok.LexerActionNone, 0},             // calc.lex:11:1: \+
+	{"-", false, eztok.LexerActionNone, 0},             // calc.lex:12:1: -
+	{"*", false, eztok.LexerActionNone, 0},             // calc.lex:13:1: \*
+	{"**", false, eztok.LexerActionNone, 0},            // calc.lex:14:1: \*\*
+	{"slash", false, eztok.LexerActionNone, 0},         // calc.lex:15:1: /
+	{"(", false, eztok.LexerActionNone, 0},             // calc.lex:16:1: \(
+	{")", false, eztok.LexerActionNone, 0},             // calc.lex:17:1: \)
+	{";", false, eztok.LexerActionNone, 0},             // calc.lex:18:1: ;
+	{"string_start", false, eztok.LexerActionPush, 1},  // calc.lex:19:1: "
+	{"string_text", false, eztok.LexerActionNone, 0},   // calc.lex:22:1: [^"\\]+
+	{"string_escape", false, eztok.LexerActionNone, 0}, // calc.lex:23:1: \\.
+	{"string_end", false, eztok.LexerActionPop, 0},     // calc.lex:24:1: "
+}
+
+// The first DFA state of each mode.
+var calcModeStarts = []int{0, 25}
+
+// The index in calcRules of the first rule of each mode.
+var calcModeRuleStarts = []int{0, 16}
+
+// The index of the rule (within its mode) accepted by each DFA state, or -1.
+var calcAccepts = []int{-1, 0, 15, 12, 13, 9, 7, 8, 11, 5, 14, 6, 4, 4, 4, 10, 1, -1, 4, 4, 5, 2, 4, 4, 3, -1, 0, 2, -1, 1}
+
+// The next DFA state (plus 1, so 0 means none) of each DFA state on each
+// ASCII rune.
+var calcASCII = [][128]int16{
+	{9: 2, 10: 2, 13: 2, 32: 2, 34: 3, 40: 4, 41: 5, 42: 6, 43: 7, 45: 8, 47: 9, 48: 10, 49: 10, 50: 10, 51: 10, 52: 10, 53: 10, 54: 10, 55: 10, 56: 10, 57: 10, 59: 11, 61: 12, 65: 13, 66: 13, 67: 13, 68: 13, 69: 13, 70: 13, 71: 13, 72: 13, 73: 13, 74: 13, 75: 13, 76: 13, 77: 13, 78: 13, 79: 13, 80: 13, 81: 13, 82: 13, 83: 13, 84: 13, 85: 13, 86: 13, 87: 13, 88: 13, 89: 13, 90: 13, 95: 13, 97: 13, 98: 13, 99: 13, 100: 13, 101: 13, 102: 13, 103: 13, 104: 13, 105: 13, 106: 13, 107: 13, 108: 14, 109: 13, 110: 13, 111: 13, 112: 15, 113: 13, 114: 13, 115: 13, 116: 13, 117: 13, 118: 13, 119: 13, 120: 13, 121: 13, 122: 13},
+	{9: 2, 10: 2, 13: 2, 32: 2},
+	{},
+	{},
+	{},
+	{42: 16},
+	{},
+	{},
+	{47: 17},
+	{46: 18, 48: 10, 49: 10, 50: 10, 51: 10, 52: 10, 53: 10, 54: 10, 55: 10, 56: 10, 57: 10},
+	{},
+	{},
+	{48: 13, 49: 13, 50: 13, 51: 13, 52: 13, 53: 13, 54: 13, 55: 13, 56: 13, 57: 13, 65: 13, 66: 13, 67: 13, 68: 13, 69: 13, 70: 13, 71: 13, 72: 13, 73: 13, 74: 13, 75: 13, 76: 13, 77: 13, 78: 13, 79: 13, 80: 13, 81: 13, 82: 13, 83: 13, 84: 13, 85: 13, 86: 13, 87: 13, 88: 13, 89: 13, 90: 13, 95: 13, 97: 13, 98: 13, 99: 13, 100: 13, 101: 13, 102: 13, 103: 13, 104: 13, 105: 13, 106: 13, 107: 13, 108: 13, 109: 13, 110: 13, 111: 13, 112: 13, 113: 13, 114: 13, 115: 13, 116: 13, 117: 13, 118: 13, 119: 13, 120: 13, 121: 13, 122: 13},
+	{48: 13, 49: 13, 50: 13, 51: 13, 52: 13, 53: 13, 54: 13, 55: 13, 56: 13, 57: 13, 65: 13, 66: 13, 67: 13, 68: 13, 69: 13, 70: 13, 71: 13, 72: 13, 73: 13, 74: 13, 75: 13, 76: 13, 77: 13, 78: 13, 79: 13, 80: 13, 81: 13, 82: 13, 83: 13, 84: 13, 85: 13, 86: 13, 87: 13, 88: 13, 89: 13, 90: 13, 95: 13, 97: 13, 98: 13, 99: 13, 100: 13, 101: 19, 102: 13, 103: 13, 104: 13, 105: 13, 106: 13, 107: 13, 108: 13, 109: 13, 110: 13, 111: 13, 112: 13, 113: 13, 114: 13, 115: 13, 116: 13, 117: 13, 118: 13, 119: 13, 120: 13, 121: 13, 122: 13},
+	{48: 13, 49: 13, 50: 13, 51: 13, 52: 13, 53: 13, 54: 13, 55: 13, 56: 13, 57: 13, 65: 13, 66: 13, 67: 13, 68: 13, 69: 13, 70: 13, 71: 13, 72: 13, 73: 13, 74: 13, 75: 13, 76: 13, 77: 13, 78: 13, 79: 13, 80: 13, 81: 13, 82: 13, 83: 13, 84: 13, 85: 13, 86: 13, 87: 13, 88: 13, 89: 13, 90: 13, 95: 13, 97: 13, 98: 13, 99: 13, 100: 13, 101: 13, 102: 13, 103: 13, 104: 13, 105: 13, 106: 13, 107: 13, 108: 13, 109: 13, 110: 13, 111: 13, 112: 13, 113: 13, 114: 20, 115: 13, 116: 13, 117: 13, 118: 13, 119: 13, 120: 13, 121: 13, 122: 13},
+	{},
+	{0: 17, 1: 17, 2: 17, 3: 17, 4: 17, 5: 17, 6: 17, 7: 17, 8: 17, 9: 17, 11: 17, 12: 17, 13: 17, 14: 17, 15: 17, 16: 17, 17: 17, 18: 17, 19: 17, 20: 17, 21: 17, 22: 17, 23: 17, 24: 17, 25: 17, 26: 17, 27: 17, 28: 17, 29: 17, 30: 17, 31: 17, 32: 17, 33: 17, 34: 17, 35: 17, 36: 17, 37: 17, 38: 17, 39: 17, 40: 17, 41: 17, 42: 17, 43: 17, 44: 17, 45: 17, 46: 17, 47: 17, 48: 17, 49: 17, 50: 17, 51: 17, 52: 17, 53: 17, 54: 17, 55: 17, 56: 17, 57: 17, 58: 17, 59: 17, 60: 17, 61: 17, 62: 17, 63: 17, 64: 17, 65: 17, 66: 17, 67: 17, 68: 17, 69: 17, 70: 17, 71: 17, 72: 17, 73: 17, 74: 17, 75: 17, 76: 17, 77: 17, 78: 17, 79: 17, 80: 17, 81: 17, 82: 17, 83: 17, 84: 17, 85: 17, 86: 17, 87: 17, 88: 17, 89: 17, 90: 17, 91: 17, 92: 17, 93: 17, 94: 17, 95: 17, 96: 17, 97: 17, 98: 17, 99: 17, 100: 17, 101: 17, 102: 17, 103: 17, 104: 17, 105: 17, 106: 17, 107: 17, 108: 17, 109: 17, 110: 17, 111: 17, 112: 17, 113: 17, 114: 17, 115: 17, 116: 17, 117: 17, 118: 17, 119: 17, 120: 17, 121: 17, 122: 17, 123: 17, 124: 17, 125: 17, 126: 17, 127: 17},
+	{48: 21, 49: 21, 50: 21, 51: 21, 52: 21, 53: 21, 54: 21, 55: 21, 56: 21, 57: 21},
+	{48: 13, 49: 13, 50: 13, 51: 13, 52: 13, 53: 13, 54: 13, 55: 13, 56: 13, 57: 13, 65: 13, 66: 13, 67: 13, 68: 13, 69: 13, 70: 13, 71: 13, 72: 13, 73: 13, 74: 13, 75: 13, 76: 13, 77: 13, 78: 13, 79: 13, 80: 13, 81: 13, 82: 13, 83: 13, 84: 13, 85: 13, 86: 13, 87: 13, 88: 13, 89: 13, 90: 13, 95: 13, 97: 13, 98: 13, 99: 13, 100: 13, 101: 13, 102: 13, 103: 13, 104: 13, 105: 13, 106: 13, 107: 13, 108: 13, 109: 13, 110: 13, 111: 13, 112: 13, 113: 13, 114: 13, 115: 13, 116: 22, 117: 13, 118: 13, 119: 13, 120: 13, 121: 13, 122: 13},
+	{48: 13, 49: 13, 50: 13, 51: 13, 52: 13, 53: 13, 54: 13, 55: 13, 56: 13, 57: 13, 65: 13, 66: 13, 67: 13, 68: 13, 69: 13, 70: 13, 71: 13, 72: 13, 73: 13, 74: 13, 75: 13, 76: 13, 77: 13, 78: 13, 79: 13, 80: 13, 81: 13, 82: 13, 83: 13, 84: 13, 85: 13, 86: 13, 87: 13, 88: 13, 89: 13, 90: 13, 95: 13, 97: 13, 98: 13, 99: 13, 100: 13, 101: 13, 102: 13, 103: 13, 104: 13, 105: 23, 106: 13, 107: 13, 108: 13, 109: 13, 110: 13, 111: 13, 112: 13, 113: 13, 114: 13, 115: 13, 116: 13, 117: 13, 118: 13, 119: 13, 120: 13, 121: 13, 122: 13},
+	{48: 21, 49: 21, 50: 21, 51: 21, 52: 21, 53: 21, 54: 21, 55: 21, 56: 21, 57: 21},
+	{48: 13, 49: 13, 50: 13, 51: 13, 52: 13, 53: 13, 54: 13, 55: 13, 56: 13, 57: 13, 65: 13, 66: 13, 67: 13, 68: 13, 69: 13, 70: 13, 71: 13, 72: 13, 73: 13, 74: 13, 75: 13, 76: 13, 77: 13, 78: 13, 79: 13, 80: 13, 81: 13, 82: 13, 83: 13, 84: 13, 85: 13, 86: 13, 87: 13, 88: 13, 89: 13, 90: 13, 95: 13, 97: 13, 98: 13, 99: 13, 100: 13, 101: 13, 102: 13, 103: 13, 104: 13, 105: 13, 106: 13, 107: 13, 108: 13, 109: 13, 110: 13, 111: 13, 112: 13, 113: 13, 114: 13, 115: 13, 116: 13, 117: 13, 118: 13, 119: 13, 120: 13, 121: 13, 122: 13},
+	{48: 13, 49: 13, 50: 13, 51: 13, 52: 13, 53: 13, 54: 13, 55: 13, 56: 13, 57: 13, 65: 13, 66: 13, 67: 13, 68: 13, 69: 13, 70: 13, 71: 13, 72: 13, 73: 13, 74: 13, 75: 13, 76: 13, 77: 13, 78: 13, 79: 13, 80: 13, 81: 13, 82: 13, 83: 13, 84: 13, 85: 13, 86: 13, 87: 13, 88: 13, 89: 13, 90: 13, 95: 13, 97: 13, 98: 13, 99: 13, 100: 13, 101: 13, 102: 13, 103: 13, 104: 13, 105: 13, 106: 13, 107: 13, 108: 13, 109: 13, 110: 24, 111: 13, 112: 13, 113: 13, 114: 13, 115: 13, 116: 13, 117: 13, 118: 13, 119: 13, 120: 13, 121: 13, 122: 13},
+	{48: 13, 49: 13, 50: 13, 51: 13, 52: 13, 53: 13, 54: 13, 55: 13, 56: 13, 57: 13, 65: 13, 66: 13, 67: 13, 68: 13, 69: 13, 70: 13, 71: 13, 72: 13, 73: 13, 74: 13, 75: 13, 76: 13, 77: 13, 78: 13, 79: 13, 80: 13, 81: 13, 82: 13, 83: 13, 84: 13, 85: 13, 86: 13, 87: 13, 88: 13, 89: 13, 90: 13, 95: 13, 97: 13, 98: 13, 99: 13, 100: 13, 101: 13, 102: 13, 103: 13, 104: 13, 105: 13, 106: 13, 107: 13, 108: 13, 109: 13, 110: 13, 111: 13, 112: 13, 113: 13, 114: 13, 115: 13, 116: 25, 117: 13, 118: 13, 119: 13, 120: 13, 121: 13, 122: 13},
+	{48: 13, 49: 13, 50: 13, 51: 13, 52: 13, 53: 13, 54: 13, 55: 13, 56: 13, 57: 13, 65: 13, 66: 13, 67: 13, 68: 13, 69: 13, 70: 13, 71: 13, 72: 13, 73: 13, 74: 13, 75: 13, 76: 13, 77: 13, 78: 13, 79: 13, 80: 13, 81: 13, 82: 13, 83: 13, 84: 13, 85: 13, 86: 13, 87: 13, 88: 13, 89: 13, 90: 13, 95: 13, 97: 13, 98: 13, 99: 13, 100: 13, 101: 13, 102: 13, 103: 13, 104: 13, 105: 13, 106: 13, 107: 13, 108: 13, 109: 13, 110: 13, 111: 13, 112: 13, 113: 13, 114: 13, 115: 13, 116: 13, 117: 13, 118: 13, 119: 13, 120: 13, 121: 13, 122: 13},
+	{0: 27, 1: 27, 2: 27, 3: 27, 4: 27, 5: 27, 6: 27, 7: 27, 8: 27, 9: 27, 10: 27, 11: 27, 12: 27, 13: 27, 14: 27, 15: 27, 16: 27, 17: 27, 18: 27, 19: 27, 20: 27, 21: 27, 22: 27, 23: 27, 24: 27, 25: 27, 26: 27, 27: 27, 28: 27, 29: 27, 30: 27, 31: 27, 32: 27, 33: 27, 34: 28, 35: 27, 36: 27, 37: 27, 38: 27, 39: 27, 40: 27, 41: 27, 42: 27, 43: 27, 44: 27, 45: 27, 46: 27, 47: 27, 48: 27, 49: 27, 50: 27, 51: 27, 52: 27, 53: 27, 54: 27, 55: 27, 56: 27, 57: 27, 58: 27, 59: 27, 60: 27, 61: 27, 62: 27, 63: 27, 64: 27, 65: 27, 66: 27, 67: 27, 68: 27, 69: 27, 70: 27, 71: 27, 72: 27, 73: 27, 74: 27, 75: 27, 76: 27, 77: 27, 78: 27, 79: 27, 80: 27, 81: 27, 82: 27, 83: 27, 84: 27, 85: 27, 86: 27, 87: 27, 88: 27, 89: 27, 90: 27, 91: 27, 92: 29, 93: 27, 94: 27, 95: 27, 96: 27, 97: 27, 98: 27, 99: 27, 100: 27, 101: 27, 102: 27, 103: 27, 104: 27, 105: 27, 106: 27, 107: 27, 108: 27, 109: 27, 110: 27, 111: 27, 112: 27, 113: 27, 114: 27, 115: 27, 116: 27, 117: 27, 118: 27, 119: 27, 120: 27, 121: 27, 122: 27, 123: 27, 124: 27, 125: 27, 126: 27, 127: 27},
+	{0: 27, 1: 27, 2: 27, 3: 27, 4: 27, 5: 27, 6: 27, 7: 27, 8: 27, 9: 27, 10: 27, 11: 27, 12: 27, 13: 27, 14: 27, 15: 27, 16: 27, 17: 27, 18: 27, 19: 27, 20: 27, 21: 27, 22: 27, 23: 27, 24: 27, 25: 27, 26: 27, 27: 27, 28: 27, 29: 27, 30: 27, 31: 27, 32: 27, 33: 27, 35: 27, 36: 27, 37: 27, 38: 27, 39: 27, 40: 27, 41: 27, 42: 27, 43: 27, 44: 27, 45: 27, 46: 27, 47: 27, 48: 27, 49: 27, 50: 27, 51: 27, 52: 27, 53: 27, 54: 27, 55: 27, 56: 27, 57: 27, 58: 27, 59: 27, 60: 27, 61: 27, 62: 27, 63: 27, 64: 27, 65: 27, 66: 27, 67: 27, 68: 27, 69: 27, 70: 27, 71: 27, 72: 27, 73: 27, 74: 27, 75: 27, 76: 27, 77: 27, 78: 27, 79: 27, 80: 27, 81: 27, 82: 27, 83: 27, 84: 27, 85: 27, 86: 27, 87: 27, 88: 27, 89: 27, 90: 27, 91: 27, 93: 27, 94: 27, 95: 27, 96: 27, 97: 27, 98: 27, 99: 27, 100: 27, 101: 27, 102: 27, 103: 27, 104: 27, 105: 27, 106: 27, 107: 27, 108: 27, 109: 27, 110: 27, 111: 27, 112: 27, 113: 27, 114: 27, 115: 27, 116: 27, 117: 27, 118: 27, 119: 27, 120: 27, 121: 27, 122: 27, 123: 27, 124: 27, 125: 27, 126: 27, 127: 27},
+	{},
+	{0: 30, 1: 30, 2: 30, 3: 30, 4: 30, 5: 30, 6: 30, 7: 30, 8: 30, 9: 30, 11: 30, 12: 30, 13: 30, 14: 30, 15: 30, 16: 30, 17: 30, 18: 30, 19: 30, 20: 30, 21: 30, 22: 30, 23: 30, 24: 30, 25: 30, 26: 30, 27: 30, 28: 30, 29: 30, 30: 30, 31: 30, 32: 30, 33: 30, 34: 30, 35: 30, 36: 30, 37: 30, 38: 30, 39: 30, 40: 30, 41: 30, 42: 30, 43: 30, 44: 30, 45: 30, 46: 30, 47: 30, 48: 30, 49: 30, 50: 30, 51: 30, 52: 30, 53: 30, 54: 30, 55: 30, 56: 30, 57: 30, 58: 30, 59: 30, 60: 30, 61: 30, 62: 30, 63: 30, 64: 30, 65: 30, 66: 30, 67: 30, 68: 30, 69: 30, 70: 30, 71: 30, 72: 30, 73: 30, 74: 30, 75: 30, 76: 30, 77: 30, 78: 30, 79: 30, 80: 30, 81: 30, 82: 30, 83: 30, 84: 30, 85: 30, 86: 30, 87: 30, 88: 30, 89: 30, 90: 30, 91: 30, 92: 30, 93: 30, 94: 30, 95: 30, 96: 30, 97: 30, 98: 30, 99: 30, 100: 30, 101: 30, 102: 30, 103: 30, 104: 30, 105: 30, 106: 30, 107: 30, 108: 30, 109: 30, 110: 30, 111: 30, 112: 30, 113: 30, 114: 30, 115: 30, 116: 30, 117: 30, 118: 30, 119: 30, 120: 30, 121: 30, 122: 30, 123: 30, 124: 30, 125: 30, 126: 30, 127: 30},
+	{},
+}
+
+// The transitions of each DFA state on non-ASCII runes, sorted by rune.
+var calcTransitions = [][]calcTransition{
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{{128, 1114111, 16}},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{},
+	{{128, 1114111, 26}},
+	{{128, 1114111, 26}},
+	{},
+	{{128, 1114111, 29}},
+	{},
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"log"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+//go:generate go run ../../cmd/eztok-gen -o calc_lexer.go calc.lex
+
+// The lexer spec describing the tokens of the calculator language.
+//
+//go:embed calc.lex
+var calcSpec string
+
+// The program to tokenize.
+const ProgramText = `
+	let x = 2 ** 10; // exponent
+	print "x is \"" x;
+`
+
+func main() {
+	// Parse the lexer spec. Errors point to the offending line of the spec.
+	spec, err := eztok.ParseLexerSpec(calcSpec, "calc.lex")
+	if err != nil {
+		log.Fatalf("Error while parsing the lexer spec: %v", err)
+	}
+	// Create a tokenizer that operates on the rules of the spec.
+	tokenizer := eztok.NewSpecTokenizer(spec)
+
+	// Tokenize an example string using our tokenizer above.
+	tokens, err := eztok.TokenizeString(tokenizer, ProgramText)
+	// Check for tokenization errors.
+	if err != nil {
+		log.Fatalf("Error while tokenizing: %v", err)
+	}
+
+	// The same spec compiled ahead of time by eztok-gen (see calc_lexer.go)
+	// produces the same tokens, without trying each rule in turn.
+	generatedTokens, err := eztok.TokenizeString(NewCalcTokenizer(), ProgramText)
+	if err != nil {
+		log.Fatalf("Error while tokenizing with the generated tokenizer: %v", err)
+	}
+	if len(generatedTokens) != len(tokens) {
+		log.Fatalf("The generated tokenizer produced %v tokens instead of %v", len(generatedTokens), len(tokens))
+	}
+	for i, tok := range tokens {
+		generated := generatedTokens[i]
+		if generated.TokenType != tok.TokenType || generated.Value != tok.Value || *generated.Origin != *tok.Origin {
+			log.Fatalf("The generated tokenizer produced %v at %v instead of %v at %v",
+				generated.ToString(), generated.Origin.ToString(), tok.ToString(), tok.Origin.ToString())
+		}
+	}
+
+	// Print out each token in-order.
+	fmt.Printf("Tokenized Tokens:\n")
+	for _, tok := range tokens {
+		fmt.Printf("%v at %v\n", tok.ToString(), tok.Origin.ToString())
+	}
+}
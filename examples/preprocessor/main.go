@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// Custom TokenType definitions.
+const (
+	TokenTypeOpenParen  eztok.TokenType = "("
+	TokenTypeCloseParen eztok.TokenType = ")"
+	TokenTypeComma      eztok.TokenType = ","
+	TokenTypeStar       eztok.TokenType = "*"
+	TokenTypeSemicolon  eztok.TokenType = ";"
+)
+
+// Available "files" to include in the example program.
+// Key is "file name" and value is "file contents".
+var availableIncludePathToContent = eztok.MapIncludeResolver{
+	"math.h": `
+		#define PI 3.14159
+		#define SQUARE(x) (x * x)
+	`,
+}
+
+// The main program contents to preprocess.
+const ProgramText = `
+	#include "math.h"
+	#ifdef DEBUG
+		debug(SQUARE(PI));
+	#else
+		print(SQUARE(PI), SQUARE(2));
+	#endif
+`
+
+func main() {
+	// Create the tokenizer that the preprocessor will run on.
+	// The PreprocessorDirectiveNode is required for the preprocessor to see directives.
+	tokenizer := eztok.NewInOrderNodeTokenizer(
+		eztok.SkipWhitespaceNode,
+		eztok.PreprocessorDirectiveNode,
+		eztok.NewRuneMatchNode(TokenTypeOpenParen, '('),
+		eztok.NewRuneMatchNode(TokenTypeCloseParen, ')'),
+		eztok.NewRuneMatchNode(TokenTypeComma, ','),
+		eztok.NewRuneMatchNode(TokenTypeStar, '*'),
+		eztok.NewRuneMatchNode(TokenTypeSemicolon, ';'),
+		eztok.NumberNode,
+		eztok.IdentifierNode,
+	)
+
+	// Create the preprocessor. Since SQUARE is a function-like macro, the
+	// preprocessor needs to know which tokens are parens and commas.
+	preprocessor := eztok.NewPreprocessor(tokenizer, availableIncludePathToContent)
+	preprocessor.OpenParenType = TokenTypeOpenParen
+	preprocessor.CloseParenType = TokenTypeCloseParen
+	preprocessor.CommaType = TokenTypeComma
+
+	// A Preprocessor is itself a Tokenizer, so it can be used anywhere a Tokenizer can.
+	tokens, err := eztok.TokenizeString(preprocessor, ProgramText)
+	if err != nil {
+		log.Fatalf("Error while preprocessing: %v", err)
+	}
+
+	// Print out each expanded token in-order, along with where it came from.
+	traverser := eztok.NewTokenTraverser(tokens)
+	fmt.Printf("Preprocessed Tokens:\n")
+	for traverser.PeekToken(0) != nil {
+		tok := traverser.NextToken()
+		fmt.Printf("%-20v from %v\n", tok.ToString(), tok.Origin.ToString())
+	}
+}
@@ -1,5 +1,10 @@
 package eztok
 
+import (
+	"sync"
+	"unicode"
+)
+
 // The type of the callback function required by Node.CanParseToken.
 type CanParseTokenCallback func(ctx Context) bool
 
@@ -13,11 +18,31 @@ type CallbackNode struct {
 	canParseCallback CanParseTokenCallback
 	// The function to call when CallbackNode.ParseToken is called.
 	parseCallback ParseTokenCallback
+	// A regular expression describing canParseCallback, or empty if none is
+	// known. See PatternNode.
+	canParsePattern string
+	// The runes that canParseCallback requires the next rune to be one of,
+	// or nil if unknown. See FirstRuneNode.
+	firstRunes     []*unicode.RangeTable
+	firstRunesOnce sync.Once
 }
 
 // Returns a new CallbackNode with the provided parameters.
 func NewCallbackNode(canParseCallback CanParseTokenCallback, parseCallback ParseTokenCallback) *CallbackNode {
-	return &CallbackNode{canParseCallback, parseCallback}
+	return &CallbackNode{canParseCallback: canParseCallback, parseCallback: parseCallback}
+}
+
+// Returns a new CallbackNode with the provided parameters. The canParsePattern
+// is a regular expression, using the syntax of the regexp package, that must
+// match one or more of the next runes in a Context exactly when
+// canParseCallback returns true. It is returned by CallbackNode.CanParsePattern,
+// and lets the Node be compiled with other nodes (see PatternNode) and
+// dispatched by its first rune (see FirstRuneNode).
+func NewCallbackNodeWithPattern(canParsePattern string, canParseCallback CanParseTokenCallback,
+	parseCallback ParseTokenCallback) *CallbackNode {
+	node := NewCallbackNode(canParseCallback, parseCallback)
+	node.canParsePattern = canParsePattern
+	return node
 }
 
 // Calls the CanParseTokenCallback function held by the CallbackNode.
@@ -29,3 +54,24 @@ func (node *CallbackNode) CanParseToken(ctx Context) bool {
 func (node *CallbackNode) ParseToken(ctx Context) (*Token, error) {
 	return node.parseCallback(ctx)
 }
+
+// Returns the regular expression describing the CanParseTokenCallback function
+// held by the CallbackNode, and true if there is one. See PatternNode.
+func (node *CallbackNode) CanParsePattern() (string, bool) {
+	return node.canParsePattern, node.canParsePattern != ""
+}
+
+// Returns the tables of runes that the CanParseTokenCallback function held by
+// the CallbackNode requires Context.PeekRune(0) to be within, and true if they
+// are known. They are derived from the pattern of CallbackNode.CanParsePattern.
+// See FirstRuneNode.
+func (node *CallbackNode) FirstRunes() ([]*unicode.RangeTable, bool) {
+	node.firstRunesOnce.Do(func() {
+		if node.firstRunes == nil && node.canParsePattern != "" {
+			if dfa, err := BuildDFA(node.canParsePattern); err == nil {
+				node.firstRunes = []*unicode.RangeTable{dfaFirstRunes(dfa)}
+			}
+		}
+	})
+	return node.firstRunes, node.firstRunes != nil
+}
@@ -0,0 +1,252 @@
+package eztok
+
+import (
+	"fmt"
+	"io"
+)
+
+// A Tokenizer that produces the same Token objects as an InOrderNodeTokenizer
+// of the same Node objects, but compiles the CanParseToken functions of each
+// PatternNode into a single DFA. Rather than calling CanParseToken on each
+// Node in turn, the DFA finds the first PatternNode that can parse a Token in
+// a single pass over the next runes. A Node whose CanParseToken function is
+// not described by a pattern is still called in-order.
+type CompiledTokenizer struct {
+	nodes []Node
+	// The DFA whose rules are the patterns of the compiled nodes, in-order.
+	dfa *DFA
+	// The index in nodes of the Node of each rule of the DFA.
+	ruleNodes []int
+	// The indices in nodes of the Node objects that were not compiled.
+	opaqueNodes []int
+	// The lowest rule accepted by each state of the DFA, or by a state
+	// reachable from it, or len(ruleNodes) if there is none.
+	minReachableRule []int
+	shadowedNodes    []*ShadowedNode
+}
+
+// Represents a Node that can never parse a Token, since wherever it could, a
+// Node before it can parse a Token instead.
+type ShadowedNode struct {
+	// The index of the shadowed Node.
+	Index int
+	// The indices of the Node objects before it that parse Token objects
+	// instead, in ascending order.
+	ShadowedBy []int
+}
+
+// Returns a new CompiledTokenizer with the provided parameters. Each Node that
+// is a PatternNode with a pattern that can be compiled into a DFA (see
+// BuildDFA) is compiled; any other Node is called in-order. Returns an error if
+// the patterns need too many DFA states.
+func NewCompiledTokenizer(nodes ...Node) (*CompiledTokenizer, error) {
+	dfa, ruleNodes, opaqueNodes, err := compileNodePatterns(nodes)
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledTokenizer{nodes, dfa, ruleNodes, opaqueNodes, dfaMinReachableRules(dfa, len(ruleNodes)),
+		findShadowedNodes(dfa, ruleNodes)}, nil
+}
+
+// Returns the compiled Node objects that can never parse a Token, since
+// wherever they could, a compiled Node before them can parse a Token instead.
+// Node objects that were not compiled are neither reported nor taken into
+// account.
+func (tizer *CompiledTokenizer) ShadowedNodes() []*ShadowedNode {
+	return tizer.shadowedNodes
+}
+
+// For as long as IsEOF does not return true, calls
+// CompiledTokenizer.TokenizeNext and collects the Token objects it returns.
+func (tizer *CompiledTokenizer) Tokenize(ctx Context) ([]*Token, error) {
+	toks := []*Token{}
+	for !IsEOF(ctx) {
+		tok, err := tizer.TokenizeNext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if tok != nil {
+			toks = append(toks, tok)
+		}
+	}
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+	return toks, nil
+}
+
+// Finds the first Node that can parse a Token given the current Context state,
+// exactly as InOrderNodeTokenizer.TokenizeNext would, and calls its ParseToken
+// function. Returns a nil Token if the Node consumed input without generating
+// a Token.
+func (tizer *CompiledTokenizer) TokenizeNext(ctx Context) (*Token, error) {
+	rule, err := tizer.firstMatchingRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	firstNode := len(tizer.nodes)
+	if rule < len(tizer.ruleNodes) {
+		firstNode = tizer.ruleNodes[rule]
+	}
+	for _, index := range tizer.opaqueNodes {
+		if index > firstNode {
+			break
+		}
+		if tizer.nodes[index].CanParseToken(ctx) {
+			return parseNodeToken(ctx, tizer.nodes[index])
+		}
+	}
+	if firstNode < len(tizer.nodes) {
+		return parseNodeToken(ctx, tizer.nodes[firstNode])
+	}
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("unexpected rune '%c' at %v",
+		ctx.PeekRune(0), ctx.GetNextOrigin().ToString())
+}
+
+// Returns the lowest rule of the DFA that matches one or more of the next runes
+// in the Context, or len(CompiledTokenizer.ruleNodes) if there is none.
+// Returns an error if a lower rule than any found could still match after the
+// maximum lookahead of the Context.
+func (tizer *CompiledTokenizer) firstMatchingRule(ctx Context) (int, error) {
+	reader := NewPeekRuneReader(ctx)
+	first := len(tizer.ruleNodes)
+	// Stop once no lower rule than the lowest found so far can be reached.
+	for state := 0; tizer.minReachableRule[state] < first; {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		if state = tizer.dfa.Next(state, r); state < 0 {
+			break
+		}
+		if accepts := tizer.dfa.States[state].Accepts; len(accepts) > 0 && accepts[0] < first {
+			first = accepts[0]
+		}
+	}
+	return first, nil
+}
+
+// Builds a DFA whose rules are the patterns of each PatternNode within nodes
+// whose pattern can be compiled into a DFA. Returns the DFA, the index within
+// nodes of the Node of each rule, and the indices of the other Node objects.
+func compileNodePatterns(nodes []Node) (*DFA, []int, []int, error) {
+	patterns := []string{}
+	ruleNodes := []int{}
+	opaqueNodes := []int{}
+	for i, node := range nodes {
+		if patternNode, ok := node.(PatternNode); ok {
+			if pattern, ok := patternNode.CanParsePattern(); ok {
+				if _, err := BuildDFA(pattern); err == nil {
+					patterns = append(patterns, pattern)
+					ruleNodes = append(ruleNodes, i)
+					continue
+				}
+			}
+		}
+		opaqueNodes = append(opaqueNodes, i)
+	}
+	dfa, err := BuildDFA(patterns...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return dfa, ruleNodes, opaqueNodes, nil
+}
+
+// Returns the lowest rule accepted by each state of dfa or by a state
+// reachable from it, or ruleCount if there is none.
+func dfaMinReachableRules(dfa *DFA, ruleCount int) []int {
+	minRules := make([]int, len(dfa.States))
+	for i, state := range dfa.States {
+		minRules[i] = ruleCount
+		if len(state.Accepts) > 0 {
+			minRules[i] = state.Accepts[0]
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for i, state := range dfa.States {
+			for _, transition := range state.Transitions {
+				if minRules[transition.Next] < minRules[i] {
+					minRules[i] = minRules[transition.Next]
+					changed = true
+				}
+			}
+		}
+	}
+	return minRules
+}
+
+// Returns the rules of dfa that can never be the lowest rule to match one or
+// more runes, with ruleNodes mapping rules to the indices of their Node.
+func findShadowedNodes(dfa *DFA, ruleNodes []int) []*ShadowedNode {
+	// Find the rules accepted by each state or a state reachable from it.
+	reachable := make([][]bool, len(dfa.States))
+	for i, state := range dfa.States {
+		reachable[i] = make([]bool, len(ruleNodes))
+		for _, rule := range state.Accepts {
+			reachable[i][rule] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for i, state := range dfa.States {
+			for _, transition := range state.Transitions {
+				for rule, ok := range reachable[transition.Next] {
+					if ok && !reachable[i][rule] {
+						reachable[i][rule] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	shadowed := []*ShadowedNode{}
+	for rule := range ruleNodes {
+		// Search the runes leading to rule that no lower rule matches a
+		// prefix of, noting the lower rules that block the search.
+		shadowedBy := map[int]bool{}
+		visited := make([]bool, len(dfa.States))
+		queue := []int{0}
+		visited[0] = true
+		accepted := false
+		for len(queue) > 0 && !accepted {
+			state := queue[0]
+			queue = queue[1:]
+			for _, transition := range dfa.States[state].Transitions {
+				next := transition.Next
+				if visited[next] || !reachable[next][rule] {
+					continue
+				}
+				visited[next] = true
+				if accepts := dfa.States[next].Accepts; len(accepts) > 0 && accepts[0] < rule {
+					for _, lower := range accepts {
+						if lower < rule {
+							shadowedBy[lower] = true
+						}
+					}
+				} else if len(accepts) > 0 && accepts[0] == rule {
+					accepted = true
+					break
+				} else {
+					queue = append(queue, next)
+				}
+			}
+		}
+		if !accepted {
+			node := &ShadowedNode{ruleNodes[rule], []int{}}
+			for lower, ruleNode := range ruleNodes[:rule] {
+				if shadowedBy[lower] {
+					node.ShadowedBy = append(node.ShadowedBy, ruleNode)
+				}
+			}
+			shadowed = append(shadowed, node)
+		}
+	}
+	return shadowed
+}
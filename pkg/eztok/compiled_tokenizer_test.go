@@ -0,0 +1,74 @@
+package eztok
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCompiledTokenizerLookaheadLimit(t *testing.T) {
+	tizer, err := NewCompiledTokenizer(
+		SkipWhitespaceNode,
+		NewStringMatchNode("long", "xxxxxxxxxxxx"),
+		NewRegexMatchNode("id", "[a-z]+"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"short tokens", "ab cd", false},
+		{"long literal does not match", "xxxy", false},
+		{"long literal may match", "xxxxxxxxxxxx", true},
+		{"long identifier", "abcdefghijk", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := tizer.Tokenize(newLookaheadTestContext(test.input))
+			if test.wantErr != errors.Is(err, ErrLookaheadLimit) || (!test.wantErr && err != nil) {
+				t.Fatalf("Tokenize returned error %v, want an ErrLookaheadLimit: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompiledTokenizerShadowedNodes(t *testing.T) {
+	// A Node without a pattern, which is not compiled.
+	opaqueNode := NewCallbackNode(
+		func(ctx Context) bool { return ctx.PeekRune(0) == 'i' },
+		func(ctx Context) (*Token, error) { return NewToken("i", ctx.NextRune()), nil },
+	)
+	tests := []struct {
+		name  string
+		nodes []Node
+		// Each ShadowedNode returned, as its Index and ShadowedBy.
+		want string
+	}{
+		{"literal covered by an earlier regex", []Node{NewRegexMatchNode("id", "[a-z]+"), NewStringMatchNode("if", "if")}, "[1 [0]]"},
+		{"literal covered by a prefix regex", []Node{NewRegexMatchNode("i", "i"), NewStringMatchNode("if", "if")}, "[1 [0]]"},
+		{"literal after a Node that is not compiled", []Node{NewRegexMatchNode("id", "i[a-z]*"), opaqueNode,
+			NewStringMatchNode("if", "if")}, "[2 [0]]"},
+		{"literal before the regex", []Node{NewStringMatchNode("if", "if"), NewRegexMatchNode("id", "[a-z]+")}, "[]"},
+		{"literal not covered by a regex", []Node{NewRegexMatchNode("id", "[a-z]+"), NewStringMatchNode("0x", "0x")}, "[]"},
+		{"regex covered by several regexes", []Node{NewRegexMatchNode("upper", "[A-Z]+"), NewRegexMatchNode("lower", "[a-z]+"),
+			NewRegexMatchNode("letters", "[A-Za-z]+")}, "[2 [0 1]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tizer, err := NewCompiledTokenizer(test.nodes...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, shadowed := range tizer.ShadowedNodes() {
+				got = append(got, fmt.Sprintf("%v %v", shadowed.Index, shadowed.ShadowedBy))
+			}
+			if fmt.Sprint(got) != test.want {
+				t.Fatalf("ShadowedNodes() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
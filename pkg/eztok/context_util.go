@@ -1,20 +1,205 @@
 package eztok
 
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
 // The type of the callback function required by some Context utils.
 type UntilRuneCallback func(r rune) bool
 
+// Returns true if no runes remain in the input of ctx. If ctx is not an
+// EOFContext, the input is taken to have ended once Context.PeekRune(0)
+// returns NilRune.
+func IsEOF(ctx Context) bool {
+	if eofCtx, ok := ctx.(EOFContext); ok {
+		return eofCtx.IsEOF()
+	}
+	return ctx.PeekRune(0) == NilRune
+}
+
 // Consumes and concatenates runes into a string given the current Context state
-// until Context.PeekRune(0) returns NilRune or callback(Context.PeekRune(0))
-// returns true.
+// until IsEOF returns true or callback(Context.PeekRune(0)) returns true.
 func ReadRunesUntil(ctx Context, callback UntilRuneCallback) string {
 	str := ""
-	for r := ctx.PeekRune(0); r != NilRune && !callback(r); r = ctx.PeekRune(0) {
-		r = ctx.NextRune()
-		str += string(r)
+	for !IsEOF(ctx) && !callback(ctx.PeekRune(0)) {
+		str += string(ctx.NextRune())
 	}
 	return str
 }
 
+// Returns true if the next runes in the Context equal the runes of prefix.
+// Does not consume any runes. For a Context with a maximum lookahead (such as
+// a ReaderContext), a prefix with more runes than it allows fails the Context
+// with an error wrapping ErrLookaheadLimit if the runes it allows match.
+func HasPrefix(ctx Context, prefix string) bool {
+	if fast, ok := ctx.(prefixContext); ok && !strings.ContainsRune(prefix, utf8.RuneError) {
+		return fast.HasPrefix(prefix)
+	}
+	runes := []rune(prefix)
+	if limiter, ok := ctx.(lookaheadLimiter); ok && len(runes) > limiter.MaxLookahead()+1 {
+		// Only peek beyond the maximum lookahead, which fails the Context,
+		// if the runes within it match.
+		for i := 0; i <= limiter.MaxLookahead(); i++ {
+			if ctx.PeekRune(i) != runes[i] {
+				return false
+			}
+		}
+	}
+	// Peek the last rune first, so that contexts which buffer their input
+	// only need to fill their buffer once.
+	for i := len(runes) - 1; i >= 0; i-- {
+		if ctx.PeekRune(i) != runes[i] {
+			return false
+		}
+	}
+	return len(runes) <= 0 || !IsEOF(ctx)
+}
+
+// Returns the next count runes in the Context, or fewer if the input ends
+// first. Does not consume any runes. For a Context with a maximum lookahead
+// (such as a ReaderContext), a count greater than it allows stops the input
+// with an error wrapping ErrLookaheadLimit.
+func PeekRunes(ctx Context, count int) []rune {
+	if fast, ok := ctx.(prefixContext); ok {
+		return []rune(fast.PeekString(count))
+	}
+	if count > 0 {
+		// Peek the last rune first, so that peeking too far fails before
+		// anything is buffered.
+		ctx.PeekRune(count - 1)
+	}
+	runes := make([]rune, 0, count)
+	for i := 0; i < count; i++ {
+		r, ok := peekRuneAt(ctx, i)
+		if !ok {
+			break
+		}
+		runes = append(runes, r)
+	}
+	return runes
+}
+
+// Returns the next count runes in the Context as a string, or fewer if the
+// input ends first. Does not consume any runes. See PeekRunes.
+func PeekString(ctx Context, count int) string {
+	if fast, ok := ctx.(prefixContext); ok {
+		return fast.PeekString(count)
+	}
+	return string(PeekRunes(ctx, count))
+}
+
+// Consumes the next count runes in the Context, or fewer if the input ends
+// first. Returns the number of runes consumed.
+func SkipRunes(ctx Context, count int) int {
+	skipped := 0
+	for ; skipped < count && !IsEOF(ctx); skipped++ {
+		ctx.NextRune()
+	}
+	return skipped
+}
+
+// Implemented by contexts that can compare and read ahead without peeking
+// rune by rune, such as StringContext and ByteContext.
+type prefixContext interface {
+	HasPrefix(prefix string) bool
+	PeekString(count int) string
+}
+
+// Implemented by contexts that can tell a rune 0 within their input apart
+// from the end of their input when peeking, such as ReaderContext.
+type runePeeker interface {
+	// Returns the rune that is relative runes ahead of the current rune in
+	// the input, and true if there is such a rune.
+	peekRune(relative int) (rune, bool)
+}
+
+// Implemented by contexts that limit how far ahead they can be peeked, such
+// as ReaderContext.
+type lookaheadLimiter interface {
+	MaxLookahead() int
+}
+
+// Returns the rune that is relative runes ahead of the current rune in ctx,
+// and true if there is such a rune. For a Context that is not a runePeeker, a
+// NilRune beyond the first rune is taken to be the end of the input.
+func peekRuneAt(ctx Context, relative int) (rune, bool) {
+	if peeker, ok := ctx.(runePeeker); ok {
+		return peeker.peekRune(relative)
+	}
+	r := ctx.PeekRune(relative)
+	if r == NilRune && (relative > 0 || IsEOF(ctx)) {
+		return NilRune, false
+	}
+	return r, true
+}
+
+// Returns an io.RuneReader of the runes ahead in ctx, which does not consume
+// them. The reader ends with io.EOF at the end of the input. Reading beyond
+// the maximum lookahead of ctx (such as ReaderContextOptions.MaxLookahead)
+// fails ctx, and ends the reader with an error wrapping ErrLookaheadLimit. The
+// size of each rune read is the length of its UTF-8 encoding.
+func NewPeekRuneReader(ctx Context) io.RuneReader {
+	if fast, ok := ctx.(peekReaderContext); ok {
+		return fast.newPeekRuneReader()
+	}
+	limit := -1
+	if limiter, ok := ctx.(lookaheadLimiter); ok {
+		limit = limiter.MaxLookahead()
+	}
+	return &peekRuneReader{ctx, 0, limit}
+}
+
+// The io.RuneReader returned by NewPeekRuneReader.
+type peekRuneReader struct {
+	ctx      Context
+	relative int
+	// The maximum relative that may be peeked, or -1 if there is none.
+	limit int
+}
+
+// Returns the next rune ahead in the Context.
+func (reader *peekRuneReader) ReadRune() (rune, int, error) {
+	if reader.limit >= 0 && reader.relative > reader.limit {
+		// Peek anyway, so that ctx records the failure.
+		reader.ctx.PeekRune(reader.relative)
+		if err := contextErr(reader.ctx); err != nil {
+			return 0, 0, err
+		}
+		return 0, 0, ErrLookaheadLimit
+	}
+	r, ok := peekRuneAt(reader.ctx, reader.relative)
+	if !ok {
+		return 0, 0, io.EOF
+	}
+	reader.relative++
+	return r, runeSize(r), nil
+}
+
+// Implemented by contexts that can provide the reader returned by
+// NewPeekRuneReader without peeking rune by rune, such as StringContext.
+type peekReaderContext interface {
+	newPeekRuneReader() io.RuneReader
+}
+
+// Returns the length of the UTF-8 encoding of r, treating a rune that cannot
+// be encoded as utf8.RuneError.
+func runeSize(r rune) int {
+	if size := utf8.RuneLen(r); size > 0 {
+		return size
+	}
+	return utf8.RuneLen(utf8.RuneError)
+}
+
+// Returns the error held by ctx if it is a FallibleContext. Returns nil otherwise.
+func contextErr(ctx Context) error {
+	if fallible, ok := ctx.(FallibleContext); ok {
+		return fallible.Err()
+	}
+	return nil
+}
+
 // The inverse of ReadRunesUntil; the reading of runes will stop if
 // callback(Context.PeekRune(0)) returns false instead of true.
 func ReadRunesUntilNot(ctx Context, callback UntilRuneCallback) string {
@@ -0,0 +1,61 @@
+package eztok
+
+import "testing"
+
+// A Context that only implements the methods required by Context, as an
+// implementation outside of this package might.
+type minimalContext struct {
+	runes []rune
+}
+
+func (ctx *minimalContext) PeekRune(relative int) rune {
+	if relative < len(ctx.runes) {
+		return ctx.runes[relative]
+	}
+	return NilRune
+}
+
+func (ctx *minimalContext) NextRune() rune {
+	r := ctx.PeekRune(0)
+	if len(ctx.runes) > 0 {
+		ctx.runes = ctx.runes[1:]
+	}
+	return r
+}
+
+func (ctx *minimalContext) GetNextOrigin() *Origin {
+	return NewOrigin("minimal", 1, 1)
+}
+
+func TestIsEOF(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  Context
+		want bool
+	}{
+		{"EOFContext with rune 0", NewStringContext("\x00", "test"), false},
+		{"EOFContext at end", NewStringContext("", "test"), true},
+		{"Context with runes", &minimalContext{[]rune("a")}, false},
+		{"Context at end", &minimalContext{}, true},
+		// Without IsEOF, a rune 0 cannot be told apart from the end.
+		{"Context with rune 0", &minimalContext{[]rune{0}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsEOF(test.ctx); got != test.want {
+				t.Fatalf("IsEOF() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeMinimalContext(t *testing.T) {
+	tizer := NewInOrderNodeTokenizer(SkipWhitespaceNode, IdentifierNode)
+	toks, err := tizer.Tokenize(&minimalContext{[]rune("ab cd")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toks) != 2 || toks[0].Value != "ab" || toks[1].Value != "cd" {
+		t.Fatalf("Tokenize returned %v Token objects, want ab and cd", len(toks))
+	}
+}
@@ -0,0 +1,272 @@
+package eztok
+
+import (
+	"fmt"
+	"io"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The maximum number of states BuildDFA will build before returning an error.
+const MaxDFAStates = 10000
+
+// Represents a deterministic finite automaton that matches several regular
+// expressions (its rules) at once, one rune at a time.
+type DFA struct {
+	// The states of the DFA. The state at index 0 is the start state.
+	States []*DFAState
+}
+
+// Represents a state of a DFA.
+type DFAState struct {
+	// The transitions out of the state, sorted by DFATransition.Lo and not
+	// overlapping. There is no next state for a rune not covered by any
+	// transition.
+	Transitions []DFATransition
+	// The indices of the rules that match the runes leading to this state,
+	// sorted ascending.
+	Accepts []int
+}
+
+// Represents a transition between states of a DFA.
+type DFATransition struct {
+	// The first and last runes of the transition.
+	Lo, Hi rune
+	// The index of the next state.
+	Next int
+}
+
+// Returns the index of the state that follows state on the rune r, or -1 if
+// there is none.
+func (dfa *DFA) Next(state int, r rune) int {
+	transitions := dfa.States[state].Transitions
+	i := sort.Search(len(transitions), func(i int) bool { return transitions[i].Hi >= r })
+	if i < len(transitions) && transitions[i].Lo <= r {
+		return transitions[i].Next
+	}
+	return -1
+}
+
+// Returns the index of the rule with the longest match against the next runes
+// in the Context, and the number of runes in the match. Ties go to the rule
+// with the lowest index. Returns a rule of -1 if no rule matches one or more
+// runes. Does not consume any runes, nor peek beyond the maximum lookahead of
+// the Context. Returns an error if the DFA could still match a longer run of
+// runes after the maximum lookahead, as the longest match is then unknown.
+func (dfa *DFA) Match(ctx Context) (int, int, error) {
+	reader := NewPeekRuneReader(ctx)
+	bestRule, bestCount := -1, 0
+	for state, count := 0, 1; ; count++ {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return -1, 0, err
+		}
+		if state = dfa.Next(state, r); state < 0 {
+			break
+		}
+		if accepts := dfa.States[state].Accepts; len(accepts) > 0 {
+			bestRule, bestCount = accepts[0], count
+		}
+	}
+	return bestRule, bestCount, nil
+}
+
+// Returns a table of the runes that lead out of the start state of dfa.
+func dfaFirstRunes(dfa *DFA) *unicode.RangeTable {
+	ranges := [][2]rune{}
+	for _, transition := range dfa.States[0].Transitions {
+		ranges = append(ranges, [2]rune{transition.Lo, transition.Hi})
+	}
+	return newRangeTable(ranges)
+}
+
+// Returns a new DFA whose rules are the regular expressions patterns, in
+// order. The patterns use the syntax of the regexp package, but must not use
+// assertions such as '^', '$' or '\b'. Returns an error if a pattern is
+// invalid, or the DFA would have more than MaxDFAStates states.
+func BuildDFA(patterns ...string) (*DFA, error) {
+	progs := make([]*syntax.Prog, len(patterns))
+	for i, pattern := range patterns {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%v': %v", pattern, err)
+		}
+		if progs[i], err = syntax.Compile(re.Simplify()); err != nil {
+			return nil, fmt.Errorf("invalid pattern '%v': %v", pattern, err)
+		}
+		for _, inst := range progs[i].Inst {
+			if inst.Op == syntax.InstEmptyWidth {
+				return nil, fmt.Errorf("pattern '%v' uses an assertion, which is not supported by a DFA", pattern)
+			}
+		}
+	}
+
+	builder := &dfaBuilder{progs, &DFA{[]*DFAState{}}, map[string]int{}, [][]dfaThread{}}
+	start := []dfaThread{}
+	for i, prog := range progs {
+		start = builder.addThread(start, dfaThread{i, uint32(prog.Start)})
+	}
+	builder.stateOf(start)
+	for state := 0; state < len(builder.dfa.States); state++ {
+		if len(builder.dfa.States) > MaxDFAStates {
+			return nil, fmt.Errorf("the patterns need more than %v DFA states", MaxDFAStates)
+		}
+		builder.buildTransitions(state)
+	}
+	return builder.dfa, nil
+}
+
+// A thread of the NFA that a DFA is built from: an instruction of the
+// compiled pattern of a rule.
+type dfaThread struct {
+	rule int
+	pc   uint32
+}
+
+// Holds the state of a call to BuildDFA.
+type dfaBuilder struct {
+	progs []*syntax.Prog
+	dfa   *DFA
+	// The index of the state of each set of threads, by dfaThreadsKey.
+	stateIndices map[string]int
+	// The rune-consuming threads of each state.
+	stateThreads [][]dfaThread
+}
+
+// Appends thread, and the threads reachable from it without consuming a
+// rune, to threads. Only rune-consuming and matching threads are kept.
+func (builder *dfaBuilder) addThread(threads []dfaThread, thread dfaThread) []dfaThread {
+	for _, existing := range threads {
+		if existing == thread {
+			return threads
+		}
+	}
+	inst := &builder.progs[thread.rule].Inst[thread.pc]
+	switch inst.Op {
+	case syntax.InstAlt, syntax.InstAltMatch:
+		threads = builder.addThread(threads, dfaThread{thread.rule, inst.Out})
+		return builder.addThread(threads, dfaThread{thread.rule, inst.Arg})
+	case syntax.InstNop, syntax.InstCapture:
+		return builder.addThread(threads, dfaThread{thread.rule, inst.Out})
+	case syntax.InstFail:
+		return threads
+	}
+	return append(threads, thread)
+}
+
+// Returns the index of the state of threads, adding the state to the DFA if
+// it is new.
+func (builder *dfaBuilder) stateOf(threads []dfaThread) int {
+	sort.Slice(threads, func(i, j int) bool {
+		return threads[i].rule < threads[j].rule || (threads[i].rule == threads[j].rule && threads[i].pc < threads[j].pc)
+	})
+	key := dfaThreadsKey(threads)
+	if index, ok := builder.stateIndices[key]; ok {
+		return index
+	}
+	state := &DFAState{[]DFATransition{}, []int{}}
+	consuming := []dfaThread{}
+	for _, thread := range threads {
+		if builder.progs[thread.rule].Inst[thread.pc].Op == syntax.InstMatch {
+			if len(state.Accepts) <= 0 || state.Accepts[len(state.Accepts)-1] != thread.rule {
+				state.Accepts = append(state.Accepts, thread.rule)
+			}
+		} else {
+			consuming = append(consuming, thread)
+		}
+	}
+	index := len(builder.dfa.States)
+	builder.dfa.States = append(builder.dfa.States, state)
+	builder.stateThreads = append(builder.stateThreads, consuming)
+	builder.stateIndices[key] = index
+	return index
+}
+
+// Builds the transitions out of the state at index state, adding any new
+// states they lead to.
+func (builder *dfaBuilder) buildTransitions(state int) {
+	threads := builder.stateThreads[state]
+	ranges := make([][]rune, len(threads))
+	boundaries := []rune{}
+	for i, thread := range threads {
+		ranges[i] = dfaInstRanges(&builder.progs[thread.rule].Inst[thread.pc])
+		for j := 0; j < len(ranges[i]); j += 2 {
+			boundaries = append(boundaries, ranges[i][j], ranges[i][j+1]+1)
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i] < boundaries[j] })
+
+	transitions := []DFATransition{}
+	for i := 0; i < len(boundaries)-1; i++ {
+		lo, hi := boundaries[i], boundaries[i+1]-1
+		if lo > hi {
+			continue
+		}
+		next := []dfaThread{}
+		for j, thread := range threads {
+			if dfaRangesContain(ranges[j], lo) {
+				inst := &builder.progs[thread.rule].Inst[thread.pc]
+				next = builder.addThread(next, dfaThread{thread.rule, inst.Out})
+			}
+		}
+		if len(next) <= 0 {
+			continue
+		}
+		nextState := builder.stateOf(next)
+		if last := len(transitions) - 1; last >= 0 && transitions[last].Next == nextState && transitions[last].Hi+1 == lo {
+			transitions[last].Hi = hi
+		} else {
+			transitions = append(transitions, DFATransition{lo, hi, nextState})
+		}
+	}
+	builder.dfa.States[state].Transitions = transitions
+}
+
+// Returns a string that uniquely identifies a sorted set of threads.
+func dfaThreadsKey(threads []dfaThread) string {
+	key := strings.Builder{}
+	for _, thread := range threads {
+		fmt.Fprintf(&key, "%v:%v,", thread.rule, thread.pc)
+	}
+	return key.String()
+}
+
+// Returns the runes matched by a rune-consuming instruction, as sorted pairs
+// of first and last runes.
+func dfaInstRanges(inst *syntax.Inst) []rune {
+	switch inst.Op {
+	case syntax.InstRune1:
+		return []rune{inst.Rune[0], inst.Rune[0]}
+	case syntax.InstRuneAny:
+		return []rune{0, unicode.MaxRune}
+	case syntax.InstRuneAnyNotNL:
+		return []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}
+	case syntax.InstRune:
+		if len(inst.Rune) != 1 {
+			return inst.Rune
+		}
+		runes := []rune{inst.Rune[0]}
+		if syntax.Flags(inst.Arg)&syntax.FoldCase != 0 {
+			for r := unicode.SimpleFold(inst.Rune[0]); r != inst.Rune[0]; r = unicode.SimpleFold(r) {
+				runes = append(runes, r)
+			}
+		}
+		sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+		ranges := make([]rune, 0, len(runes)*2)
+		for _, r := range runes {
+			ranges = append(ranges, r, r)
+		}
+		return ranges
+	}
+	return []rune{}
+}
+
+// Returns true if r is within the sorted pairs of first and last runes ranges.
+func dfaRangesContain(ranges []rune, r rune) bool {
+	i := sort.Search(len(ranges)/2, func(i int) bool { return ranges[i*2+1] >= r })
+	return i < len(ranges)/2 && ranges[i*2] <= r
+}
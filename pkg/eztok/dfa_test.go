@@ -0,0 +1,176 @@
+package eztok
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestBuildDFAErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		errText  string
+	}{
+		{"invalid pattern", []string{"a", "("}, "invalid pattern '('"},
+		{"line assertion", []string{"^a"}, "uses an assertion"},
+		{"word boundary", []string{`a\b`}, "uses an assertion"},
+		// Remembering the last 15 runes needs 2^15 states.
+		{"too many states", []string{"[ab]*a[ab]{14}"}, "more than 10000 DFA states"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := BuildDFA(test.patterns...)
+			if err == nil || !strings.Contains(err.Error(), test.errText) {
+				t.Fatalf("BuildDFA returned error %v, want %q", err, test.errText)
+			}
+		})
+	}
+}
+
+func TestBuildDFAMatches(t *testing.T) {
+	dfa, err := BuildDFA("if", "[a-z]+", "(?i)k", ".", "[0-9]+(\\.[0-9]+)?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input     string
+		wantRule  int
+		wantCount int
+	}{
+		{"if", 0, 2},
+		{"if(", 0, 2},
+		{"iff", 1, 3},
+		{"i", 1, 1},
+		{"k", 1, 1},
+		{"K", 2, 1},
+		{"\u212A", 2, 1},
+		{"Z", 3, 1},
+		{"\n", -1, 0},
+		{"12.5", 4, 4},
+		// The longest match is "12", not "12." followed by no digits.
+		{"12.x", 4, 2},
+		{"", -1, 0},
+	}
+	for _, test := range tests {
+		rule, count, err := dfa.Match(NewStringContext(test.input, "test"))
+		if err != nil || rule != test.wantRule || count != test.wantCount {
+			t.Fatalf("Match(%q) = %v, %v, %v, want %v, %v, nil", test.input, rule, count, err, test.wantRule, test.wantCount)
+		}
+	}
+}
+
+func TestDFAMatchLookaheadLimit(t *testing.T) {
+	dfa, err := BuildDFA("[a-z]+", "ab", "[0-9]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		input     string
+		wantRule  int
+		wantCount int
+		wantErr   bool
+	}{
+		{"within the lookahead", "abc defgh", 0, 3, false},
+		{"tie goes to the lowest rule", "ab", 0, 2, false},
+		{"dead before the lookahead", "1abcdefghijk", 2, 1, false},
+		{"no match", " abcdefghijk", -1, 0, false},
+		{"match ends at the lookahead", "abcdefgh ijk", 0, 8, false},
+		{"live at the lookahead", "abcdefghijk", -1, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, count, err := dfa.Match(newLookaheadTestContext(test.input))
+			if test.wantErr {
+				if !errors.Is(err, ErrLookaheadLimit) {
+					t.Fatalf("Match returned error %v, want an ErrLookaheadLimit", err)
+				}
+				return
+			}
+			if err != nil || rule != test.wantRule || count != test.wantCount {
+				t.Fatalf("Match = %v, %v, %v, want %v, %v, nil", rule, count, err, test.wantRule, test.wantCount)
+			}
+		})
+	}
+}
+
+func TestBuildDFAStates(t *testing.T) {
+	tests := []struct {
+		name       string
+		patterns   []string
+		wantStates int
+	}{
+		// Equal sets of NFA threads share a state.
+		{"alternation of equal branches", []string{"a|a"}, 2},
+		{"repetition", []string{"a+"}, 2},
+		{"shared prefix", []string{"ab", "ac"}, 4},
+		{"overlapping rules", []string{"a", "a"}, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dfa, err := BuildDFA(test.patterns...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(dfa.States) != test.wantStates {
+				t.Fatalf("BuildDFA built %v states, want %v", len(dfa.States), test.wantStates)
+			}
+			checkDFATransitions(t, dfa)
+		})
+	}
+
+	dfa, err := BuildDFA("a", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepts := dfa.States[dfa.Next(0, 'a')].Accepts; len(accepts) != 2 || accepts[0] != 0 || accepts[1] != 1 {
+		t.Fatalf("Accepts = %v, want [0 1]", accepts)
+	}
+}
+
+func TestBuildDFAStartTransitions(t *testing.T) {
+	dfa, err := BuildDFA("[a-c]", "[d-f]x", "[^a-z]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDFATransitions(t, dfa)
+	// Both ranges of "[^a-z]" lead to the same state.
+	transitions := dfa.States[0].Transitions
+	rangeNext := func(lo, hi rune) int {
+		for _, transition := range transitions {
+			if transition.Lo == lo && transition.Hi == hi {
+				return transition.Next
+			}
+		}
+		t.Fatalf("start state has transitions %v, want one from %q to %q", transitions, lo, hi)
+		return -1
+	}
+	if len(transitions) != 4 {
+		t.Fatalf("start state has transitions %v, want 4", transitions)
+	}
+	below, abc, def, above := rangeNext(0, 'a'-1), rangeNext('a', 'c'), rangeNext('d', 'f'), rangeNext('z'+1, unicode.MaxRune)
+	if below != above || below == abc || abc == def || def == below {
+		t.Fatalf("start state has transitions %v, want distinct states per rule", transitions)
+	}
+	if dfa.Next(0, 'g') >= 0 {
+		t.Fatalf("Next(0, 'g') = %v, want -1", dfa.Next(0, 'g'))
+	}
+}
+
+// Fails t unless the transitions of each state of dfa are sorted, do not
+// overlap, and lead to states of dfa.
+func checkDFATransitions(t *testing.T, dfa *DFA) {
+	t.Helper()
+	for i, state := range dfa.States {
+		for j, transition := range state.Transitions {
+			if transition.Lo > transition.Hi || (j > 0 && state.Transitions[j-1].Hi >= transition.Lo) {
+				t.Fatalf("state %v has unsorted or overlapping transitions %v", i, state.Transitions)
+			}
+			if transition.Next < 0 || transition.Next >= len(dfa.States) {
+				t.Fatalf("state %v has a transition to the missing state %v", i, transition.Next)
+			}
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package eztok
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// The type of a function that converts a reader of encoded input into a
+// reader of UTF-8 input. Compatible with the Reader method of the decoders in
+// golang.org/x/text/encoding.
+type Decoder func(reader io.Reader) io.Reader
+
+// The UTF-8 encoding of a byte order mark.
+var utf8ByteOrderMark = []byte{0xEF, 0xBB, 0xBF}
+
+// Byte order marks, in the order they must be checked in.
+var byteOrderMarks = []struct {
+	bom     []byte
+	decoder Decoder
+}{
+	{[]byte{0xFF, 0xFE, 0x00, 0x00}, DecodeUTF32LE},
+	{[]byte{0x00, 0x00, 0xFE, 0xFF}, DecodeUTF32BE},
+	{utf8ByteOrderMark, DecodeUTF8},
+	{[]byte{0xFF, 0xFE}, DecodeUTF16LE},
+	{[]byte{0xFE, 0xFF}, DecodeUTF16BE},
+}
+
+// Detects a UTF-8, UTF-16 or UTF-32 byte order mark at the start of reader.
+// Returns the Decoder for the detected encoding, or DecodeUTF8 if there is no
+// byte order mark. The byte order mark is left in reader, and is stripped by
+// the returned Decoder.
+func DetectEncoding(reader *bufio.Reader) Decoder {
+	if decoder, _ := detectByteOrderMark(reader); decoder != nil {
+		return decoder
+	}
+	return DecodeUTF8
+}
+
+// Returns the Decoder and byte order mark detected at the start of reader.
+// Returns nil values if there is no byte order mark.
+func detectByteOrderMark(reader *bufio.Reader) (Decoder, []byte) {
+	start, _ := reader.Peek(4)
+	for _, mark := range byteOrderMarks {
+		if bytes.HasPrefix(start, mark.bom) {
+			return mark.decoder, mark.bom
+		}
+	}
+	return nil, nil
+}
+
+// A Decoder for UTF-8 input. A leading byte order mark is stripped. Invalid
+// UTF-8 is passed through as-is.
+func DecodeUTF8(reader io.Reader) io.Reader {
+	return &utf8BOMReader{bufio.NewReader(reader), false}
+}
+
+// A Decoder for little-endian UTF-16 input. A leading byte order mark is
+// stripped. Invalid UTF-16 is replaced by utf8.RuneError.
+func DecodeUTF16LE(reader io.Reader) io.Reader {
+	return newRuneDecodingReader(reader, 2, func(unit []byte, next []byte) (rune, int) {
+		return decodeUTF16(binary.LittleEndian.Uint16(unit), next, binary.LittleEndian)
+	}, true)
+}
+
+// A Decoder for big-endian UTF-16 input. A leading byte order mark is
+// stripped. Invalid UTF-16 is replaced by utf8.RuneError.
+func DecodeUTF16BE(reader io.Reader) io.Reader {
+	return newRuneDecodingReader(reader, 2, func(unit []byte, next []byte) (rune, int) {
+		return decodeUTF16(binary.BigEndian.Uint16(unit), next, binary.BigEndian)
+	}, true)
+}
+
+// A Decoder for little-endian UTF-32 input. A leading byte order mark is
+// stripped. Invalid UTF-32 is replaced by utf8.RuneError.
+func DecodeUTF32LE(reader io.Reader) io.Reader {
+	return newRuneDecodingReader(reader, 4, func(unit []byte, next []byte) (rune, int) {
+		return decodeUTF32(binary.LittleEndian.Uint32(unit)), 4
+	}, true)
+}
+
+// A Decoder for big-endian UTF-32 input. A leading byte order mark is
+// stripped. Invalid UTF-32 is replaced by utf8.RuneError.
+func DecodeUTF32BE(reader io.Reader) io.Reader {
+	return newRuneDecodingReader(reader, 4, func(unit []byte, next []byte) (rune, int) {
+		return decodeUTF32(binary.BigEndian.Uint32(unit)), 4
+	}, true)
+}
+
+// A Decoder for Latin-1 (ISO-8859-1) input, where each byte is one rune.
+func DecodeLatin1(reader io.Reader) io.Reader {
+	return newRuneDecodingReader(reader, 1, func(unit []byte, next []byte) (rune, int) {
+		return rune(unit[0]), 1
+	}, false)
+}
+
+// Decodes the UTF-16 code unit, which may be the first of a surrogate pair
+// whose second code unit is at the start of next. Returns the decoded rune
+// and the number of bytes it used.
+func decodeUTF16(unit uint16, next []byte, order binary.ByteOrder) (rune, int) {
+	if !utf16.IsSurrogate(rune(unit)) {
+		return rune(unit), 2
+	}
+	if len(next) >= 2 {
+		if r := utf16.DecodeRune(rune(unit), rune(order.Uint16(next))); r != utf8.RuneError {
+			return r, 4
+		}
+	}
+	return utf8.RuneError, 2
+}
+
+// Returns the rune held by the UTF-32 code unit, or utf8.RuneError if it is
+// not a valid rune.
+func decodeUTF32(unit uint32) rune {
+	if unit > utf8.MaxRune || !utf8.ValidRune(rune(unit)) {
+		return utf8.RuneError
+	}
+	return rune(unit)
+}
+
+// A reader that strips a leading UTF-8 byte order mark from another reader.
+type utf8BOMReader struct {
+	reader  *bufio.Reader
+	checked bool
+}
+
+// Reads from the underlying reader, skipping a leading byte order mark.
+func (reader *utf8BOMReader) Read(p []byte) (int, error) {
+	if !reader.checked {
+		reader.checked = true
+		if start, _ := reader.reader.Peek(3); bytes.Equal(start, utf8ByteOrderMark) {
+			reader.reader.Discard(3)
+		}
+	}
+	return reader.reader.Read(p)
+}
+
+// The type of the callback function required by newRuneDecodingReader. Decodes
+// the rune whose first code unit is unit, given that next holds the following
+// code unit if there is one. Returns the decoded rune and the number of bytes
+// it used, which must be either len(unit) or len(unit)+len(next).
+type decodeUnitCallback func(unit []byte, next []byte) (rune, int)
+
+// A reader that decodes fixed-size code units from another reader into UTF-8.
+type runeDecodingReader struct {
+	reader    *bufio.Reader
+	unitSize  int
+	decode    decodeUnitCallback
+	skipBOM   bool
+	started   bool
+	pending   []byte
+	readerErr error
+}
+
+// Returns a new runeDecodingReader with the provided parameters.
+func newRuneDecodingReader(reader io.Reader, unitSize int, decode decodeUnitCallback, skipBOM bool) *runeDecodingReader {
+	return &runeDecodingReader{bufio.NewReader(reader), unitSize, decode, skipBOM, false, nil, nil}
+}
+
+// Reads the decoded UTF-8 of the underlying reader into p.
+func (reader *runeDecodingReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(reader.pending) > 0 {
+			copied := copy(p[n:], reader.pending)
+			reader.pending = reader.pending[copied:]
+			n += copied
+			continue
+		}
+		if reader.readerErr != nil || (n > 0 && reader.reader.Buffered() < 2*reader.unitSize) {
+			// Avoid blocking on the underlying reader once some data is read.
+			break
+		}
+		r, err := reader.decodeRune()
+		if err != nil {
+			reader.readerErr = err
+			break
+		}
+		if !reader.started {
+			reader.started = true
+			if reader.skipBOM && r == '\uFEFF' {
+				continue
+			}
+		}
+		reader.pending = utf8.AppendRune(reader.pending[:0], r)
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return 0, reader.readerErr
+}
+
+// Decodes the next rune of the underlying reader.
+func (reader *runeDecodingReader) decodeRune() (rune, error) {
+	units, err := reader.reader.Peek(2 * reader.unitSize)
+	if len(units) < reader.unitSize {
+		if len(units) > 0 {
+			// A trailing partial code unit.
+			reader.reader.Discard(len(units))
+			return utf8.RuneError, nil
+		}
+		if err == nil {
+			err = io.EOF
+		}
+		return 0, err
+	}
+	r, size := reader.decode(units[:reader.unitSize], units[reader.unitSize:])
+	reader.reader.Discard(size)
+	return r, nil
+}
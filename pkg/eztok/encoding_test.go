@@ -0,0 +1,85 @@
+package eztok
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoders(t *testing.T) {
+	tests := []struct {
+		name    string
+		decoder Decoder
+		input   []byte
+		want    string
+	}{
+		{"UTF-8", DecodeUTF8, []byte("aé☃"), "aé☃"},
+		{"UTF-8 with BOM", DecodeUTF8, []byte("\xEF\xBB\xBFaé"), "aé"},
+		{"UTF-8 invalid", DecodeUTF8, []byte("a\xffb"), "a\xffb"},
+		{"UTF-16LE", DecodeUTF16LE, []byte{'a', 0, 0xE9, 0}, "aé"},
+		{"UTF-16LE with BOM", DecodeUTF16LE, []byte{0xFF, 0xFE, 'a', 0}, "a"},
+		{"UTF-16LE surrogate pair", DecodeUTF16LE, []byte{0x34, 0xD8, 0x1E, 0xDD}, "𝄞"},
+		{"UTF-16LE lone surrogate", DecodeUTF16LE, []byte{0x34, 0xD8, 'a', 0}, "\uFFFDa"},
+		{"UTF-16LE odd length", DecodeUTF16LE, []byte{'a', 0, 'b'}, "a\uFFFD"},
+		{"UTF-16BE", DecodeUTF16BE, []byte{0, 'a', 0x26, 0x03}, "a☃"},
+		{"UTF-16BE with BOM", DecodeUTF16BE, []byte{0xFE, 0xFF, 0, 'a'}, "a"},
+		{"UTF-16BE surrogate pair", DecodeUTF16BE, []byte{0xD8, 0x34, 0xDD, 0x1E}, "𝄞"},
+		{"UTF-16BE BOM only stripped once", DecodeUTF16BE, []byte{0xFE, 0xFF, 0xFE, 0xFF}, "\uFEFF"},
+		{"UTF-32LE", DecodeUTF32LE, []byte{0xFF, 0xFE, 0, 0, 0x1E, 0xD1, 0x01, 0}, "𝄞"},
+		{"UTF-32BE", DecodeUTF32BE, []byte{0, 0, 0, 'a', 0, 0x11, 0, 0}, "a\uFFFD"},
+		{"Latin-1", DecodeLatin1, []byte{'a', 0xE9, 0xFF}, "aéÿ"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, reader := range []io.Reader{bytes.NewReader(test.input), iotest.OneByteReader(bytes.NewReader(test.input))} {
+				got, err := io.ReadAll(test.decoder(reader))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != test.want {
+					t.Fatalf("decoded %q, want %q", got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReaderContextDetectsEncoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"no BOM", []byte("a\nb")},
+		{"UTF-8 BOM", []byte("\xEF\xBB\xBFa\nb")},
+		{"UTF-16LE BOM", []byte{0xFF, 0xFE, 'a', 0, '\n', 0, 'b', 0}},
+		{"UTF-16BE BOM", []byte{0xFE, 0xFF, 0, 'a', 0, '\n', 0, 'b'}},
+		{"UTF-32LE BOM", []byte{0xFF, 0xFE, 0, 0, 'a', 0, 0, 0, '\n', 0, 0, 0, 'b', 0, 0, 0}},
+		{"UTF-32BE BOM", []byte{0, 0, 0xFE, 0xFF, 0, 0, 0, 'a', 0, 0, 0, '\n', 0, 0, 0, 'b'}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := NewReaderContext(bufio.NewReader(bytes.NewReader(test.input)), "test")
+			got := ReadRunesUntil(ctx, func(r rune) bool { return false })
+			if got != "a\nb" {
+				t.Fatalf("read %q, want %q", got, "a\nb")
+			}
+			if origin := ctx.GetNextOrigin(); origin.LineNum != 2 || origin.ColNum != 2 {
+				t.Fatalf("final Origin = %v, want test:2:2", origin.ToString())
+			}
+		})
+	}
+}
+
+func TestDetectEncodingLeavesBOM(t *testing.T) {
+	reader := bufio.NewReader(bytes.NewReader([]byte{0xFF, 0xFE, 'a', 0}))
+	decoder := DetectEncoding(reader)
+	if start, _ := reader.Peek(2); !bytes.Equal(start, []byte{0xFF, 0xFE}) {
+		t.Fatalf("DetectEncoding consumed the byte order mark")
+	}
+	got, err := io.ReadAll(decoder(reader))
+	if err != nil || string(got) != "a" {
+		t.Fatalf("decoded %q with error %v, want \"a\"", got, err)
+	}
+}
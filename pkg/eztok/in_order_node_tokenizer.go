@@ -1,6 +1,12 @@
 package eztok
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"unicode"
+)
 
 // A Tokenizer that operates on a slice of Node objects, attempting to
 // parse Token objects by checking each Node in-order.
@@ -8,43 +14,198 @@ type InOrderNodeTokenizer struct {
 	// The slice of Node objects to operate on. A Node at a lower index
 	// will attempt to be processed before a Node with a higher index.
 	Nodes []Node
+	// Holds the dispatch index of Nodes, or nil if the InOrderNodeTokenizer
+	// was not returned by NewInOrderNodeTokenizer, in which case every Node
+	// is called in-order. Copies of an InOrderNodeTokenizer share it.
+	indexCache *firstRuneIndexCache
+}
+
+// Holds the firstRuneIndex last built for the Nodes of an InOrderNodeTokenizer.
+type firstRuneIndexCache struct {
+	index atomic.Pointer[firstRuneIndex]
+}
+
+// The firstRuneIndex of an InOrderNodeTokenizer without a firstRuneIndexCache.
+var unindexedNodes = &firstRuneIndex{}
+
+// Holds, for each ASCII rune, the Node objects that may be able to parse a
+// Token when it is the next rune. See FirstRuneNode.
+type firstRuneIndex struct {
+	// The identity of each Node the index was built for.
+	identities []nodeIdentity
+	// The Nodes the index was built for.
+	nodes []Node
+	// False if the Nodes cannot be indexed, as one of them has no identity.
+	// Every Node is then called in-order.
+	indexed bool
+	// The tables of first runes of each Node, or nil if unknown.
+	firstRunes [][]*unicode.RangeTable
+	// The indices of the Node objects that may be able to parse a Token,
+	// in-order, by ASCII rune.
+	ascii [128][]int
+}
+
+// Identifies a Node by its type and the address it points to, so that Node
+// objects can be compared without ==, which panics on some values.
+type nodeIdentity struct {
+	nodeType reflect.Type
+	// The address the Node points to, or 0 if it is not a pointer.
+	address uintptr
 }
 
 // Returns a new InOrderNodeTokenizer with the given parameters.
 func NewInOrderNodeTokenizer(initialNodes ...Node) *InOrderNodeTokenizer {
-	return &InOrderNodeTokenizer{initialNodes}
+	return &InOrderNodeTokenizer{Nodes: initialNodes, indexCache: &firstRuneIndexCache{}}
 }
 
-// For as long as Context.PeekRune(0) does not return NilRune, all
-// InOrderNodeTokenzer.Nodes will have their CanParseToken function called
-// until one returns true for the current Context state. In which case, that
-// Node will have its ParseToken function called with the current Context state
-// and no further nodes will be checked until the next iteration.
+// For as long as IsEOF does not return true, calls
+// InOrderNodeTokenizer.TokenizeNext and collects the Token objects it returns.
 func (tizer *InOrderNodeTokenizer) Tokenize(ctx Context) ([]*Token, error) {
 	toks := []*Token{}
-	for ctx.PeekRune(0) != NilRune {
-		valid := false
+	index := tizer.firstRuneIndex()
+	for !IsEOF(ctx) {
+		tok, err := tizer.tokenizeNext(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		if tok != nil {
+			toks = append(toks, tok)
+		}
+	}
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+	return toks, nil
+}
+
+// All InOrderNodeTokenizer.Nodes will have their CanParseToken function called
+// until one returns true for the current Context state. In which case, that
+// Node will have its ParseToken function called with the current Context state
+// and no further nodes will be checked. Returns a nil Token if the Node consumed
+// input without generating a Token. A Node that is a FirstRuneNode is only
+// called if Context.PeekRune(0) is one of its first runes.
+func (tizer *InOrderNodeTokenizer) TokenizeNext(ctx Context) (*Token, error) {
+	return tizer.tokenizeNext(ctx, tizer.firstRuneIndex())
+}
+
+// Implements InOrderNodeTokenizer.TokenizeNext using the index.
+func (tizer *InOrderNodeTokenizer) tokenizeNext(ctx Context, index *firstRuneIndex) (*Token, error) {
+	if !index.indexed {
 		for _, node := range tizer.Nodes {
 			if node.CanParseToken(ctx) {
-				beforeOriginInfo := ctx.GetNextOrigin()
-				tok, err := node.ParseToken(ctx)
-				if err != nil {
-					return nil, fmt.Errorf("%v at %v", err, ctx.GetNextOrigin().ToString())
-				}
-				if tok != nil {
-					if tok.Origin == nil {
-						tok.Origin = beforeOriginInfo
-					}
-					toks = append(toks, tok)
-				}
-				valid = true
+				return parseNodeToken(ctx, node)
+			}
+		}
+	} else if r := ctx.PeekRune(0); r >= 0 && int(r) < len(index.ascii) {
+		for _, i := range index.ascii[r] {
+			if index.nodes[i].CanParseToken(ctx) {
+				return parseNodeToken(ctx, index.nodes[i])
+			}
+		}
+	} else {
+		for i, node := range index.nodes {
+			if firstRunes := index.firstRunes[i]; (firstRunes == nil || unicode.In(r, firstRunes...)) && node.CanParseToken(ctx) {
+				return parseNodeToken(ctx, node)
+			}
+		}
+	}
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("unexpected rune '%c' at %v",
+		ctx.PeekRune(0), ctx.GetNextOrigin().ToString())
+}
+
+// Returns the firstRuneIndex of InOrderNodeTokenizer.Nodes, building it if
+// Nodes has changed since it was last built. A Node that is not a pointer has
+// no identity, so the index of Nodes that hold one is not indexed; it is still
+// cached, so that it is only rebuilt when Nodes changes. Returns an index that
+// is not indexed if the InOrderNodeTokenizer has no firstRuneIndexCache.
+func (tizer *InOrderNodeTokenizer) firstRuneIndex() *firstRuneIndex {
+	if tizer.indexCache == nil {
+		return unindexedNodes
+	}
+	nodes := tizer.Nodes
+	if index := tizer.indexCache.index.Load(); index != nil && len(index.identities) == len(nodes) {
+		unchanged := true
+		for i, node := range nodes {
+			if index.indexed {
+				// Every Node of the index is a pointer, so == compares
+				// identities and cannot panic.
+				unchanged = index.nodes[i] == node
+			} else {
+				identity, _ := identifyNode(node)
+				unchanged = index.identities[i] == identity
+			}
+			if !unchanged {
 				break
 			}
 		}
-		if !valid {
-			return nil, fmt.Errorf("unexpected rune '%c' at %v",
-				ctx.PeekRune(0), ctx.GetNextOrigin().ToString())
+		if unchanged {
+			return index
 		}
 	}
-	return toks, nil
+
+	identities := make([]nodeIdentity, len(nodes))
+	indexed := true
+	for i, node := range nodes {
+		var ok bool
+		if identities[i], ok = identifyNode(node); !ok {
+			indexed = false
+		}
+	}
+	index := &firstRuneIndex{identities: identities, nodes: append([]Node{}, nodes...), indexed: indexed}
+	if indexed {
+		index.firstRunes = make([][]*unicode.RangeTable, len(nodes))
+		for i, node := range nodes {
+			if firstRuneNode, ok := node.(FirstRuneNode); ok {
+				index.firstRunes[i], _ = firstRuneNode.FirstRunes()
+			}
+			for r := range index.ascii {
+				if index.firstRunes[i] == nil || unicode.In(rune(r), index.firstRunes[i]...) {
+					index.ascii[r] = append(index.ascii[r], i)
+				}
+			}
+		}
+	}
+	tizer.indexCache.index.Store(index)
+	return index
+}
+
+// Returns the identity of node, and false if it has none as it is not a
+// pointer. The index holds on to each Node it was built for, so the address of
+// a Node cannot be reused by another while the index is in use.
+func identifyNode(node Node) (nodeIdentity, bool) {
+	value := reflect.ValueOf(node)
+	if !value.IsValid() {
+		return nodeIdentity{}, false
+	}
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return nodeIdentity{value.Type(), value.Pointer()}, true
+	}
+	return nodeIdentity{value.Type(), 0}, false
+}
+
+// Calls the ParseToken function of node with the current Context state. Sets
+// the Origin of the returned Token to the start of its input if the Node did
+// not set one, and adds the Origin of the failure to any returned error.
+// Returns the error of the Context instead if the Node peeked further than its
+// maximum lookahead, since the Token may then be cut short.
+func parseNodeToken(ctx Context, node Node) (*Token, error) {
+	beforeOriginInfo := ctx.GetNextOrigin()
+	tok, err := node.ParseToken(ctx)
+	if err != nil {
+		if ctxErr := contextErr(ctx); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("%v at %v", err, ctx.GetNextOrigin().ToString())
+	}
+	if err := contextErr(ctx); err != nil && errors.Is(err, ErrLookaheadLimit) {
+		return nil, err
+	}
+	if tok != nil && tok.Origin == nil {
+		tok.Origin = beforeOriginInfo
+	}
+	return tok, nil
 }
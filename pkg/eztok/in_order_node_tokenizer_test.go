@@ -0,0 +1,107 @@
+package eztok
+
+import (
+	"strings"
+	"testing"
+)
+
+// A Node that is not a pointer and panics when compared with ==, as it holds
+// a func in an interface.
+type valueTestNode struct {
+	tokenType any
+}
+
+func (node valueTestNode) CanParseToken(ctx Context) bool {
+	return ctx.PeekRune(0) == '$'
+}
+
+func (node valueTestNode) ParseToken(ctx Context) (*Token, error) {
+	ctx.NextRune()
+	return NewToken(node.tokenType.(func() TokenType)(), "$"), nil
+}
+
+func TestInOrderNodeTokenizerValueNodes(t *testing.T) {
+	node := valueTestNode{func() TokenType { return "$" }}
+	tizer := NewInOrderNodeTokenizer(SkipWhitespaceNode, node, IdentifierNode)
+	for i := 0; i < 2; i++ {
+		toks, err := TokenizeString(tizer, "a $ b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(toks) != 3 || toks[1].TokenType != "$" {
+			t.Fatalf("Tokenize returned %v Token objects, want a, $ and b", len(toks))
+		}
+	}
+	index := tizer.firstRuneIndex()
+	if index.indexed {
+		t.Fatalf("Nodes holding a value Node were indexed")
+	}
+	if tizer.firstRuneIndex() != index {
+		t.Fatalf("the unindexed result was not cached")
+	}
+	// Replacing the value Node is seen even though the index was not built.
+	tizer.Nodes[1] = NewRuneMatchNode("dollar", '$')
+	toks, err := TokenizeString(tizer, "$")
+	if err != nil || len(toks) != 1 || toks[0].TokenType != "dollar" {
+		t.Fatalf("Tokenize after replacing a Node returned %v Token objects and error %v", len(toks), err)
+	}
+	if !tizer.firstRuneIndex().indexed {
+		t.Fatalf("Nodes of pointers were not indexed")
+	}
+}
+
+func TestInOrderNodeTokenizerNodesChange(t *testing.T) {
+	tizer := NewInOrderNodeTokenizer(SkipWhitespaceNode, IdentifierNode)
+	if _, err := TokenizeString(tizer, "a + b"); err == nil || !strings.Contains(err.Error(), "unexpected rune '+'") {
+		t.Fatalf("Tokenize returned error %v, want an unexpected '+'", err)
+	}
+	index := tizer.firstRuneIndex()
+	if tizer.firstRuneIndex() != index {
+		t.Fatalf("the index was rebuilt although Nodes did not change")
+	}
+	tizer.Nodes = append(tizer.Nodes, NewRuneMatchNode("+", '+'))
+	if toks, err := TokenizeString(tizer, "a + b"); err != nil || len(toks) != 3 {
+		t.Fatalf("Tokenize after appending a Node returned %v Token objects and error %v", len(toks), err)
+	}
+	// A different Node with equal fields is a change.
+	tizer.Nodes[2] = NewRuneMatchNode("plus", '+')
+	if toks, err := TokenizeString(tizer, "+"); err != nil || len(toks) != 1 || toks[0].TokenType != "plus" {
+		t.Fatalf("Tokenize after replacing a Node returned %v Token objects and error %v", len(toks), err)
+	}
+}
+
+// A Node that is a map, which panics when compared with ==.
+type mapTestNode map[rune]TokenType
+
+func (node mapTestNode) CanParseToken(ctx Context) bool {
+	_, ok := node[ctx.PeekRune(0)]
+	return ok
+}
+
+func (node mapTestNode) ParseToken(ctx Context) (*Token, error) {
+	r := ctx.NextRune()
+	return NewToken(node[r], r), nil
+}
+
+func TestInOrderNodeTokenizerMapNodes(t *testing.T) {
+	tizer := NewInOrderNodeTokenizer(mapTestNode{'+': "+", '-': "-"}, IdentifierNode)
+	for i := 0; i < 2; i++ {
+		if toks, err := TokenizeString(tizer, "a+b-c"); err != nil || len(toks) != 5 {
+			t.Fatalf("Tokenize returned %v Token objects and error %v, want 5", len(toks), err)
+		}
+	}
+}
+
+func TestInOrderNodeTokenizerLiteralsAndCopies(t *testing.T) {
+	nodes := []Node{SkipWhitespaceNode, NewRuneMatchNode("+", '+'), IdentifierNode}
+	literal := &InOrderNodeTokenizer{Nodes: nodes}
+	if literal.firstRuneIndex().indexed {
+		t.Fatalf("an InOrderNodeTokenizer literal was indexed")
+	}
+	copied := *NewInOrderNodeTokenizer(nodes...)
+	for _, tizer := range []*InOrderNodeTokenizer{literal, &copied} {
+		if toks, err := TokenizeString(tizer, "a + b"); err != nil || len(toks) != 3 {
+			t.Fatalf("Tokenize returned %v Token objects and error %v, want 3", len(toks), err)
+		}
+	}
+}
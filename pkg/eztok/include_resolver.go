@@ -0,0 +1,48 @@
+package eztok
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// Represents something that can look up the contents of an included file
+// given its include path.
+type IncludeResolver interface {
+	// Returns the contents of the include at path. Returns an error if the
+	// include could not be found or read.
+	ResolveInclude(path string) (string, error)
+}
+
+// An IncludeResolver whose includes are held in a map of include path to
+// include contents.
+type MapIncludeResolver map[string]string
+
+// Returns the contents held in the MapIncludeResolver for path. Returns an
+// error if path is not in the map.
+func (resolver MapIncludeResolver) ResolveInclude(path string) (string, error) {
+	content, ok := resolver[path]
+	if !ok {
+		return "", fmt.Errorf("unknown include path '%v'", path)
+	}
+	return content, nil
+}
+
+// An IncludeResolver whose includes are files within an fs.FS.
+type FSIncludeResolver struct {
+	// The file system to read includes from.
+	FS fs.FS
+}
+
+// Returns a new FSIncludeResolver with the given parameters.
+func NewFSIncludeResolver(fsys fs.FS) *FSIncludeResolver {
+	return &FSIncludeResolver{fsys}
+}
+
+// Returns the contents of the file at path within FSIncludeResolver.FS.
+func (resolver *FSIncludeResolver) ResolveInclude(path string) (string, error) {
+	content, err := fs.ReadFile(resolver.FS, path)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -1,6 +1,10 @@
 package eztok
 
-// Alias to rune(0). Used as nil in the context of runes.
+import "unicode"
+
+// Alias to rune(0). Used as nil in the context of runes. Since a rune 0 may
+// also appear within input, IsEOF should be used to check for the end of
+// input.
 const NilRune rune = 0
 
 // Represents something that can look ahead by n input runes, and
@@ -18,6 +22,25 @@ type Context interface {
 	GetNextOrigin() *Origin
 }
 
+// Represents a Context that can tell the end of its input apart from a rune 0
+// within its input. See IsEOF.
+type EOFContext interface {
+	Context
+	// Returns true if no runes remain in the input. Unlike checking for a
+	// NilRune, this is not fooled by a rune 0 within the input.
+	IsEOF() bool
+}
+
+// Represents a Context whose input can fail to be read, such as due to an
+// I/O error or invalid encoding. A FallibleContext stops providing runes once
+// its input fails, as if the input had ended.
+type FallibleContext interface {
+	Context
+	// Returns the error that stopped the input from being read, once all
+	// runes before the failure have been consumed. Returns nil otherwise.
+	Err() error
+}
+
 // Represents a Node in a node-based tokenizer.
 type Node interface {
 	// Returns true if, given the current Context state, this node
@@ -33,6 +56,28 @@ type Node interface {
 	ParseToken(ctx Context) (*Token, error)
 }
 
+// Represents a Node whose CanParseToken function can be described by a regular
+// expression, so that it can be compiled together with other nodes (such as by
+// NewCompiledTokenizer).
+type PatternNode interface {
+	Node
+	// Returns a regular expression, using the syntax of the regexp package,
+	// that matches one or more of the next runes in a Context exactly when
+	// CanParseToken returns true, and true. Returns false if there is none.
+	CanParsePattern() (string, bool)
+}
+
+// Represents a Node whose CanParseToken function can only return true when the
+// next rune in a Context is one of a known set of runes, so that tokenizers
+// (such as InOrderNodeTokenizer) can skip calling it for any other rune.
+type FirstRuneNode interface {
+	Node
+	// Returns tables of runes, and true, such that CanParseToken only returns
+	// true if Context.PeekRune(0) is within one of the tables. Returns false
+	// if the runes are not known.
+	FirstRunes() ([]*unicode.RangeTable, bool)
+}
+
 // Represents something that can convert a Context into a stream of Token
 // objects.
 type Tokenizer interface {
@@ -42,6 +87,17 @@ type Tokenizer interface {
 	Tokenize(ctx Context) ([]*Token, error)
 }
 
+// Represents a Tokenizer that can convert a Context into Token objects one at
+// a time, so that tokenization can be paused between Token objects.
+type StepTokenizer interface {
+	Tokenizer
+	// Consume the input of the next Token given the current Context state,
+	// which must not be at the end of its input. Returns an error if
+	// tokenization fails. Returns a nil Token if input was consumed without
+	// generating a Token.
+	TokenizeNext(ctx Context) (*Token, error)
+}
+
 // Represents something that can look 1 token ahead in a Token stream, and
 // consume & return the next Token in a Token stream.
 type Traverser interface {
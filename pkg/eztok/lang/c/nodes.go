@@ -0,0 +1,566 @@
+package c
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// Represents a character constant or string literal.
+type Literal struct {
+	// The encoding prefix of the literal: "", "L", "u8", "u" or "U",
+	// followed by "R" for a C++ raw string literal.
+	Prefix string
+	// The contents of the literal, with escape sequences replaced by what
+	// they represent. Within a literal with a prefix of "" or "u8", an octal
+	// or hexadecimal escape sequence is a single byte, so Value may not be
+	// valid UTF-8. A character constant may hold more than one character.
+	// The contents of a raw string literal are as written, except that line
+	// splices are removed.
+	Value string
+}
+
+// Represents an integer or floating constant.
+type Number struct {
+	// The text of the constant as written, including any suffix.
+	Text string
+	// The suffix of the constant as written (such as "ULL", "f", or a C++
+	// user-defined literal suffix such as "ms" or "_km"), or "" if it has
+	// none.
+	Suffix string
+	// The value of an integer constant.
+	Int uint64
+	// The value of a floating constant (an infinity if it is out of range).
+	Float float64
+}
+
+// The suffixes of an integer constant, in lower case.
+var integerSuffixes = map[string]bool{
+	"u": true, "l": true, "ul": true, "lu": true, "ll": true, "ull": true,
+	"llu": true, "z": true, "uz": true, "zu": true, "wb": true, "uwb": true,
+	"wbu": true,
+}
+
+// The suffixes of a floating constant, in lower case.
+var floatingSuffixes = map[string]bool{
+	"f": true, "l": true, "f16": true, "f32": true, "f64": true, "f128": true,
+	"bf16": true, "df": true, "dd": true, "dl": true,
+}
+
+// The suffixes of the C++ standard library's user-defined literals. Any
+// other user-defined literal suffix must begin with '_'.
+var userDefinedSuffixes = map[string]bool{
+	"h": true, "min": true, "s": true, "ms": true, "us": true, "ns": true,
+	"y": true, "d": true, "i": true, "il": true, "if": true,
+}
+
+// Holds a map of escape rune (a rune following a '\' in a character constant
+// or string literal) to the rune it represents.
+var escapedRuneToRune = map[rune]rune{
+	'\'': '\'',
+	'"':  '"',
+	'?':  '?',
+	'\\': '\\',
+	'a':  '\a',
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  '\v',
+}
+
+// A node that matches a whitespace rune other than a newline and skips it
+// (i.e. generates no Token). Newlines are handled by Tokenizer, since they
+// end preprocessor directives.
+var WhitespaceNode = eztok.NewCallbackNodeWithPattern(
+	`[\t\v\f\r ]`,
+	func(ctx eztok.Context) bool {
+		return isWhitespaceRune(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.NextRune(); !isWhitespaceRune(r) {
+			return nil, fmt.Errorf("expected a whitespace rune but got a '%c' rune", r)
+		}
+		return nil, nil
+	},
+)
+
+// A node that matches a '//' comment (up to, but not including, the end of its
+// line) or a '/* */' comment. Returns a Token with a TokenType of
+// TokenTypeComment and the text of the comment (including its delimiters) as
+// its Value.
+var CommentNode = eztok.NewCallbackNodeWithPattern(
+	`/[/*]`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '/' && (ctx.PeekRune(1) == '/' || ctx.PeekRune(1) == '*')
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		var builder strings.Builder
+		builder.WriteRune(ctx.NextRune())
+		switch kind := ctx.NextRune(); kind {
+		case '/':
+			builder.WriteRune(kind)
+			for !eztok.IsEOF(ctx) && ctx.PeekRune(0) != '\n' {
+				builder.WriteRune(ctx.NextRune())
+			}
+		case '*':
+			builder.WriteRune(kind)
+			for !eztok.HasPrefix(ctx, "*/") {
+				if eztok.IsEOF(ctx) {
+					return nil, fmt.Errorf("unterminated comment")
+				}
+				builder.WriteRune(ctx.NextRune())
+			}
+			eztok.SkipRunes(ctx, 2)
+			builder.WriteString("*/")
+		default:
+			return nil, fmt.Errorf("expected a comment")
+		}
+		return eztok.NewToken(TokenTypeComment, builder.String()), nil
+	},
+)
+
+// A node that matches a preprocessor directive, which begins with a '#' (or
+// the digraph '%:'), followed by a directive name, and continues until the end
+// of the line. Returns a Token with a TokenType of eztok.TokenTypeDirective
+// and a *eztok.Directive Value, like eztok.PreprocessorDirectiveNode. Unlike
+// it, a comment within the directive may span multiple lines, and is replaced
+// by spaces within the Directive.Body (a single space if it spans multiple
+// lines). A null directive (a '#' alone on its line) has an empty
+// Directive.Name.
+var DirectiveNode = eztok.NewCallbackNodeWithPattern(
+	`#|%:`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '#' || (ctx.PeekRune(0) == '%' && ctx.PeekRune(1) == ':')
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if ctx.NextRune() == '%' {
+			ctx.NextRune()
+		}
+		if _, err := readDirectiveText(ctx, func(r rune) bool { return !isWhitespaceRune(r) }); err != nil {
+			return nil, err
+		}
+		name := eztok.ReadRunesUntilNot(ctx, func(r rune) bool {
+			return isIdentifierStartRune(r) || unicode.IsDigit(r)
+		})
+		bodyOrigin := ctx.GetNextOrigin()
+		body, err := readDirectiveText(ctx, func(r rune) bool { return false })
+		if err != nil {
+			return nil, err
+		}
+		return eztok.NewToken(eztok.TokenTypeDirective, &eztok.Directive{
+			Name:       name,
+			Body:       strings.TrimSuffix(body, "\r"),
+			BodyOrigin: bodyOrigin,
+		}), nil
+	},
+)
+
+// Consumes and returns the text of a directive until the end of its line, or
+// until stop returns true for a rune outside of a comment or literal. Each
+// comment is replaced by spaces.
+func readDirectiveText(ctx eztok.Context, stop eztok.UntilRuneCallback) (string, error) {
+	var builder strings.Builder
+	for !eztok.IsEOF(ctx) && ctx.PeekRune(0) != '\n' {
+		r := ctx.PeekRune(0)
+		switch {
+		case eztok.HasPrefix(ctx, "//"):
+			eztok.ReadRunesUntil(ctx, func(r rune) bool { return r == '\n' })
+		case eztok.HasPrefix(ctx, "/*"):
+			comment, err := CommentNode.ParseToken(ctx)
+			if err != nil {
+				return "", err
+			}
+			if text := comment.Value.(string); strings.ContainsRune(text, '\n') {
+				builder.WriteRune(' ')
+			} else {
+				builder.WriteString(strings.Repeat(" ", utf8.RuneCountInString(text)))
+			}
+		case stop(r):
+			return builder.String(), nil
+		case r == '"' || r == '\'':
+			// Copy the literal, so that it cannot begin a comment.
+			builder.WriteRune(ctx.NextRune())
+			for !eztok.IsEOF(ctx) && ctx.PeekRune(0) != '\n' {
+				literalRune := ctx.NextRune()
+				builder.WriteRune(literalRune)
+				if literalRune == r {
+					break
+				} else if literalRune == '\\' && !eztok.IsEOF(ctx) && ctx.PeekRune(0) != '\n' {
+					builder.WriteRune(ctx.NextRune())
+				}
+			}
+		default:
+			builder.WriteRune(ctx.NextRune())
+		}
+	}
+	return builder.String(), nil
+}
+
+// A node that matches an identifier (including a keyword). An identifier
+// begins with a letter, '_' or '$', and is followed by 0 or more letters,
+// digits, '_'s or '$'s. Returns a Token with a TokenType of
+// eztok.TokenTypeIdentifier and the name as its Value. See IsKeyword and
+// IsCPlusPlusKeyword to recognise keywords.
+var IdentifierNode = eztok.NewCallbackNodeWithPattern(
+	`[\p{L}_$]`,
+	func(ctx eztok.Context) bool {
+		return isIdentifierStartRune(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		var builder strings.Builder
+		for !eztok.IsEOF(ctx) && (isIdentifierStartRune(ctx.PeekRune(0)) || unicode.IsDigit(ctx.PeekRune(0))) {
+			builder.WriteRune(ctx.NextRune())
+		}
+		if builder.Len() <= 0 {
+			return nil, fmt.Errorf("expected an identifier but got an empty identifier")
+		}
+		return eztok.NewToken(eztok.TokenTypeIdentifier, builder.String()), nil
+	},
+)
+
+// A node that matches a character constant encased in single quotes ('),
+// optionally preceded by an encoding prefix ("L", "u8", "u" or "U"). Returns a
+// Token with a TokenType of TokenTypeCharacter and a *Literal Value.
+var CharacterNode = eztok.NewCallbackNodeWithPattern(
+	`(?:L|u8?|U)?'`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(len(peekEncodingPrefix(ctx))) == '\''
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		literal, err := readLiteral(ctx, '\'')
+		if err != nil {
+			return nil, err
+		}
+		if len(literal.Value) <= 0 {
+			return nil, fmt.Errorf("empty character constant")
+		}
+		return eztok.NewToken(TokenTypeCharacter, literal), nil
+	},
+)
+
+// A node that matches a string literal encased in double quotes ("),
+// optionally preceded by an encoding prefix ("L", "u8", "u" or "U"). A C++ raw
+// string literal, such as R"delim(contents)delim", is also matched. Returns a
+// Token with a TokenType of TokenTypeString and a *Literal Value.
+var StringNode = eztok.NewCallbackNodeWithPattern(
+	`(?:L|u8?|U)?R?"`,
+	func(ctx eztok.Context) bool {
+		prefixLength := len(peekEncodingPrefix(ctx))
+		if ctx.PeekRune(prefixLength) == 'R' {
+			prefixLength++
+		}
+		return ctx.PeekRune(prefixLength) == '"'
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		prefix := peekEncodingPrefix(ctx)
+		if ctx.PeekRune(len(prefix)) == 'R' && ctx.PeekRune(len(prefix)+1) == '"' {
+			eztok.SkipRunes(ctx, len(prefix)+2)
+			literal, err := readRawString(ctx)
+			if err != nil {
+				return nil, err
+			}
+			literal.Prefix = prefix + "R"
+			return eztok.NewToken(TokenTypeString, literal), nil
+		}
+		literal, err := readLiteral(ctx, '"')
+		if err != nil {
+			return nil, err
+		}
+		return eztok.NewToken(TokenTypeString, literal), nil
+	},
+)
+
+// A node that matches an integer or floating constant. Integer constants may
+// be decimal, octal (a leading '0'), hexadecimal ('0x') or binary ('0b'), and
+// floating constants may be decimal or hexadecimal (such as 0x1.8p3). Digits
+// may be separated by a single quote ('), and a constant may be followed by a
+// suffix (such as "ULL" or "f"). Returns a Token with a TokenType of
+// TokenTypeInteger or TokenTypeFloating, and a *Number Value.
+var NumberNode = eztok.NewCallbackNodeWithPattern(
+	`\.?[0-9]`,
+	func(ctx eztok.Context) bool {
+		r := ctx.PeekRune(0)
+		return isDigitRune(r, 10) || (r == '.' && isDigitRune(ctx.PeekRune(1), 10))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		var text strings.Builder
+		// The digits of the constant, without its prefix, separators or suffix.
+		var digits strings.Builder
+		base := 10
+		if ctx.PeekRune(0) == '0' {
+			switch ctx.PeekRune(1) {
+			case 'x', 'X':
+				base = 16
+			case 'b', 'B':
+				base = 2
+			}
+			if base != 10 {
+				text.WriteString(eztok.PeekString(ctx, 2))
+				eztok.SkipRunes(ctx, 2)
+			}
+		}
+		readDigits := func() int {
+			count := 0
+			for !eztok.IsEOF(ctx) {
+				r := ctx.PeekRune(0)
+				if r == '\'' && count > 0 && isDigitRune(ctx.PeekRune(1), base) {
+					text.WriteRune(ctx.NextRune())
+					continue
+				}
+				if !isDigitRune(r, base) && !(base < 10 && isDigitRune(r, 10)) {
+					return count
+				}
+				text.WriteRune(r)
+				digits.WriteRune(ctx.NextRune())
+				count++
+			}
+			return count
+		}
+
+		digitCount := readDigits()
+		isFloating := false
+		if ctx.PeekRune(0) == '.' && base != 2 {
+			isFloating = true
+			text.WriteRune(ctx.NextRune())
+			digits.WriteRune('.')
+			digitCount += readDigits()
+		}
+		if digitCount <= 0 {
+			return nil, fmt.Errorf("expected a digit in constant '%v'", text.String())
+		}
+		exponent := 'e'
+		if base == 16 {
+			exponent = 'p'
+		}
+		if unicode.ToLower(ctx.PeekRune(0)) == exponent {
+			sign := ctx.PeekRune(1)
+			if isDigitRune(sign, 10) || ((sign == '+' || sign == '-') && isDigitRune(ctx.PeekRune(2), 10)) {
+				isFloating = true
+				digits.WriteRune(exponent)
+				text.WriteRune(ctx.NextRune())
+				if !isDigitRune(sign, 10) {
+					digits.WriteRune(sign)
+					text.WriteRune(ctx.NextRune())
+				}
+				savedBase := base
+				base = 10
+				readDigits()
+				base = savedBase
+			}
+		}
+		if isFloating && base == 16 && !strings.ContainsRune(digits.String(), 'p') {
+			return nil, fmt.Errorf("hexadecimal floating constant '%v' requires an exponent", text.String())
+		}
+
+		suffix := eztok.ReadRunesUntilNot(ctx, func(r rune) bool {
+			return isIdentifierStartRune(r) || unicode.IsDigit(r)
+		})
+		text.WriteString(suffix)
+		number := &Number{Text: text.String(), Suffix: suffix}
+		isUserDefined := strings.HasPrefix(suffix, "_") || userDefinedSuffixes[suffix]
+		if isFloating {
+			if len(suffix) > 0 && !floatingSuffixes[strings.ToLower(suffix)] && !isUserDefined {
+				return nil, fmt.Errorf("invalid suffix '%v' on floating constant '%v'", suffix, number.Text)
+			}
+			str := digits.String()
+			if base == 16 {
+				str = "0x" + str
+			}
+			// The syntax has already been checked, so the only possible error
+			// is that the constant is out of range.
+			number.Float, _ = strconv.ParseFloat(str, 64)
+			return eztok.NewToken(TokenTypeFloating, number), nil
+		}
+
+		if len(suffix) > 0 && !integerSuffixes[strings.ToLower(suffix)] && !isUserDefined {
+			return nil, fmt.Errorf("invalid suffix '%v' on integer constant '%v'", suffix, number.Text)
+		}
+		str := digits.String()
+		if base == 10 && len(str) > 1 && str[0] == '0' {
+			base = 8
+		}
+		if index := strings.IndexFunc(str, func(r rune) bool { return !isDigitRune(r, base) }); index >= 0 {
+			return nil, fmt.Errorf("invalid digit '%c' in integer constant '%v'", str[index], number.Text)
+		}
+		value, err := strconv.ParseUint(str, base, 64)
+		if err != nil {
+			return nil, fmt.Errorf("integer constant '%v' is too large", number.Text)
+		}
+		number.Int = value
+		return eztok.NewToken(TokenTypeInteger, number), nil
+	},
+)
+
+// Returns the encoding prefix ("L", "u8", "u" or "U") that the next runes in
+// the Context begin with, or "" if there is none. Does not consume any runes.
+func peekEncodingPrefix(ctx eztok.Context) string {
+	switch ctx.PeekRune(0) {
+	case 'L':
+		return "L"
+	case 'U':
+		return "U"
+	case 'u':
+		if ctx.PeekRune(1) == '8' {
+			return "u8"
+		}
+		return "u"
+	}
+	return ""
+}
+
+// Consumes a character constant or string literal encased in quoteRune, with
+// an optional encoding prefix, and returns it.
+func readLiteral(ctx eztok.Context, quoteRune rune) (*Literal, error) {
+	prefix := peekEncodingPrefix(ctx)
+	if ctx.PeekRune(len(prefix)) != quoteRune {
+		prefix = ""
+	}
+	eztok.SkipRunes(ctx, len(prefix))
+	if r := ctx.NextRune(); r != quoteRune {
+		return nil, fmt.Errorf("expected a '%c' rune but got a '%c' rune", quoteRune, r)
+	}
+	value := []byte{}
+	for {
+		if eztok.IsEOF(ctx) || ctx.PeekRune(0) == '\n' {
+			return nil, fmt.Errorf("missing terminating %c character", quoteRune)
+		}
+		r := ctx.NextRune()
+		if r == quoteRune {
+			break
+		}
+		if r == '\\' {
+			escaped, isByte, err := readEscape(ctx, prefix)
+			if err != nil {
+				return nil, err
+			}
+			if isByte {
+				value = append(value, byte(escaped))
+				continue
+			}
+			r = escaped
+		}
+		value = utf8.AppendRune(value, r)
+	}
+	return &Literal{prefix, string(value)}, nil
+}
+
+// Consumes the rest of a raw string literal, whose prefix and opening '"'
+// have already been consumed, and returns it.
+func readRawString(ctx eztok.Context) (*Literal, error) {
+	delimiter := eztok.ReadRunesUntil(ctx, func(r rune) bool {
+		return r == '(' || r == ')' || r == '\\' || r == '"' || unicode.IsSpace(r)
+	})
+	if ctx.PeekRune(0) != '(' || utf8.RuneCountInString(delimiter) > 16 {
+		return nil, fmt.Errorf("invalid delimiter '%v' of raw string literal", delimiter)
+	}
+	ctx.NextRune()
+	terminator := ")" + delimiter + "\""
+	var builder strings.Builder
+	for !eztok.HasPrefix(ctx, terminator) {
+		if eztok.IsEOF(ctx) {
+			return nil, fmt.Errorf("unterminated raw string literal")
+		}
+		builder.WriteRune(ctx.NextRune())
+	}
+	eztok.SkipRunes(ctx, utf8.RuneCountInString(terminator))
+	return &Literal{Value: builder.String()}, nil
+}
+
+// Consumes the escape sequence following a '\' (which has already been
+// consumed) in a literal with the encoding prefix. Returns the escaped rune,
+// and true if it is a single byte of a literal with a prefix of "" or "u8".
+func readEscape(ctx eztok.Context, prefix string) (rune, bool, error) {
+	if eztok.IsEOF(ctx) || ctx.PeekRune(0) == '\n' {
+		return 0, false, fmt.Errorf("unterminated escape sequence")
+	}
+	escape := ctx.NextRune()
+	if escaped, ok := escapedRuneToRune[escape]; ok {
+		return escaped, false, nil
+	}
+	isNarrow := prefix == "" || prefix == "u8"
+	maxValue := rune(unicode.MaxRune)
+	switch prefix {
+	case "", "u8":
+		maxValue = 0xFF
+	case "u":
+		maxValue = 0xFFFF
+	}
+
+	value := rune(0)
+	switch {
+	case isDigitRune(escape, 8):
+		value = escape - '0'
+		for i := 0; i < 2 && isDigitRune(ctx.PeekRune(0), 8); i++ {
+			value = value*8 + ctx.NextRune() - '0'
+		}
+	case escape == 'x':
+		if !isDigitRune(ctx.PeekRune(0), 16) {
+			return 0, false, fmt.Errorf("\\x used with no following hex digits")
+		}
+		for isDigitRune(ctx.PeekRune(0), 16) {
+			value = value*16 + hexDigitValue(ctx.NextRune())
+			if value > maxValue {
+				return 0, false, fmt.Errorf("hex escape sequence out of range")
+			}
+		}
+	case escape == 'u' || escape == 'U':
+		digitCount := 4
+		if escape == 'U' {
+			digitCount = 8
+		}
+		for i := 0; i < digitCount; i++ {
+			if !isDigitRune(ctx.PeekRune(0), 16) {
+				return 0, false, fmt.Errorf("incomplete universal character name \\%c", escape)
+			}
+			value = value*16 + hexDigitValue(ctx.NextRune())
+		}
+		if value > unicode.MaxRune || (value >= 0xD800 && value < 0xE000) {
+			return 0, false, fmt.Errorf("invalid universal character name %#U", value)
+		}
+		return value, false, nil
+	default:
+		return 0, false, fmt.Errorf("unknown escape sequence '\\%c'", escape)
+	}
+	if value > maxValue {
+		return 0, false, fmt.Errorf("octal escape sequence out of range")
+	}
+	return value, isNarrow, nil
+}
+
+// Returns the value of the hexadecimal digit r.
+func hexDigitValue(r rune) rune {
+	switch {
+	case r >= 'a' && r <= 'f':
+		return r - 'a' + 10
+	case r >= 'A' && r <= 'F':
+		return r - 'A' + 10
+	}
+	return r - '0'
+}
+
+// Returns true if r is an ASCII digit of base (2, 8, 10 or 16).
+func isDigitRune(r rune, base int) bool {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r-'0') < base
+	case (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F'):
+		return base == 16
+	}
+	return false
+}
+
+// Returns true if r can begin an identifier.
+func isIdentifierStartRune(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == '$'
+}
+
+// Returns true if r is a whitespace rune other than a newline.
+func isWhitespaceRune(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\v' || r == '\f' || r == '\r'
+}
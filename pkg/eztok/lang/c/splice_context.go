@@ -0,0 +1,82 @@
+package c
+
+import "github.com/tpillow/eztok/pkg/eztok"
+
+// A Context that removes each backslash-newline line splice from the input of
+// another Context, joining the lines on either side of it. The newline may be
+// "\n" or "\r\n". The Origin information of each rune is that of the wrapped
+// Context.
+type spliceContext struct {
+	ctx eztok.Context
+}
+
+// Returns a new spliceContext wrapping ctx.
+func newSpliceContext(ctx eztok.Context) *spliceContext {
+	if splice, ok := ctx.(*spliceContext); ok {
+		return splice
+	}
+	return &spliceContext{ctx}
+}
+
+// Returns the rune that is relative runes ahead of the current rune in the
+// input, skipping any line splices. Returns eztok.NilRune if there is none.
+func (ctx *spliceContext) PeekRune(relative int) rune {
+	offset := ctx.spliceLength(0)
+	for ; relative > 0; relative-- {
+		offset++
+		offset += ctx.spliceLength(offset)
+	}
+	return ctx.ctx.PeekRune(offset)
+}
+
+// Consumes any line splices, then consumes and returns the next rune.
+func (ctx *spliceContext) NextRune() rune {
+	ctx.skipSplices()
+	return ctx.ctx.NextRune()
+}
+
+// Consumes any line splices, then returns the Origin information of the rune
+// that would be returned by a call to NextRune().
+func (ctx *spliceContext) GetNextOrigin() *eztok.Origin {
+	ctx.skipSplices()
+	return ctx.ctx.GetNextOrigin()
+}
+
+// Consumes any line splices, then returns true if no runes remain.
+func (ctx *spliceContext) IsEOF() bool {
+	ctx.skipSplices()
+	return eztok.IsEOF(ctx.ctx)
+}
+
+// Returns the error of the wrapped Context if it is an eztok.FallibleContext.
+// Returns nil otherwise.
+func (ctx *spliceContext) Err() error {
+	if fallible, ok := ctx.ctx.(eztok.FallibleContext); ok {
+		return fallible.Err()
+	}
+	return nil
+}
+
+// Consumes any line splices at the current rune of the wrapped Context.
+func (ctx *spliceContext) skipSplices() {
+	for length := ctx.spliceLength(0); length > 0; length = ctx.spliceLength(0) {
+		eztok.SkipRunes(ctx.ctx, length)
+	}
+}
+
+// Returns the number of runes in the line splices that begin relative runes
+// ahead of the current rune of the wrapped Context, or 0 if none do.
+func (ctx *spliceContext) spliceLength(relative int) int {
+	length := 0
+	for ctx.ctx.PeekRune(relative+length) == '\\' {
+		switch next := ctx.ctx.PeekRune(relative + length + 1); {
+		case next == '\n':
+			length += 2
+		case next == '\r' && ctx.ctx.PeekRune(relative+length+2) == '\n':
+			length += 3
+		default:
+			return length
+		}
+	}
+	return length
+}
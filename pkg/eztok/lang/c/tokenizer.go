@@ -0,0 +1,212 @@
+package c
+
+import (
+	"sort"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// TokenType definitions returned by Tokenizer. Identifiers (including
+// keywords) are returned as eztok.TokenTypeIdentifier, and preprocessor
+// directives as eztok.TokenTypeDirective. The TokenType of a punctuator Token
+// is the punctuator itself (such as "->" or "<<="), with a Value of the
+// punctuator as written; a digraph has the TokenType of the punctuator it is
+// an alternative spelling of (such as "[" for "<:").
+const (
+	TokenTypeInteger   eztok.TokenType = "integer_constant"
+	TokenTypeFloating  eztok.TokenType = "floating_constant"
+	TokenTypeCharacter eztok.TokenType = "character_constant"
+	TokenTypeString    eztok.TokenType = "string_literal"
+	TokenTypeComment   eztok.TokenType = "comment"
+)
+
+// The punctuators of C and C++.
+var punctuators = []string{
+	"[", "]", "(", ")", "{", "}", ".", "->",
+	"++", "--", "&", "*", "+", "-", "~", "!",
+	"/", "%", "<<", ">>", "<", ">", "<=", ">=", "==", "!=", "^", "|", "&&", "||",
+	"?", ":", ";", "...",
+	"=", "*=", "/=", "%=", "+=", "-=", "<<=", ">>=", "&=", "^=", "|=",
+	",", "#", "##",
+	// C++ only.
+	"::", ".*", "->*", "<=>",
+}
+
+// Holds a map of each digraph to the punctuator it is an alternative spelling
+// of.
+var digraphs = map[string]string{
+	"<:":   "[",
+	":>":   "]",
+	"<%":   "{",
+	"%>":   "}",
+	"%:":   "#",
+	"%:%:": "##",
+}
+
+// The keywords of C23.
+var keywords = map[string]bool{
+	"alignas": true, "alignof": true, "auto": true, "bool": true, "break": true,
+	"case": true, "char": true, "const": true, "constexpr": true,
+	"continue": true, "default": true, "do": true, "double": true,
+	"else": true, "enum": true, "extern": true, "false": true, "float": true,
+	"for": true, "goto": true, "if": true, "inline": true, "int": true,
+	"long": true, "nullptr": true, "register": true, "restrict": true,
+	"return": true, "short": true, "signed": true, "sizeof": true,
+	"static": true, "static_assert": true, "struct": true, "switch": true,
+	"thread_local": true, "true": true, "typedef": true, "typeof": true,
+	"typeof_unqual": true, "union": true, "unsigned": true, "void": true,
+	"volatile": true, "while": true, "_Alignas": true, "_Alignof": true,
+	"_Atomic": true, "_BitInt": true, "_Bool": true, "_Complex": true,
+	"_Decimal128": true, "_Decimal32": true, "_Decimal64": true,
+	"_Generic": true, "_Imaginary": true, "_Noreturn": true,
+	"_Static_assert": true, "_Thread_local": true,
+}
+
+// The keywords of C++20.
+var cPlusPlusKeywords = map[string]bool{
+	"alignas": true, "alignof": true, "and": true, "and_eq": true, "asm": true,
+	"auto": true, "bitand": true, "bitor": true, "bool": true, "break": true,
+	"case": true, "catch": true, "char": true, "char8_t": true,
+	"char16_t": true, "char32_t": true, "class": true, "compl": true,
+	"concept": true, "const": true, "consteval": true, "constexpr": true,
+	"constinit": true, "const_cast": true, "continue": true,
+	"co_await": true, "co_return": true, "co_yield": true, "decltype": true,
+	"default": true, "delete": true, "do": true, "double": true,
+	"dynamic_cast": true, "else": true, "enum": true, "explicit": true,
+	"export": true, "extern": true, "false": true, "float": true, "for": true,
+	"friend": true, "goto": true, "if": true, "inline": true, "int": true,
+	"long": true, "mutable": true, "namespace": true, "new": true,
+	"noexcept": true, "not": true, "not_eq": true, "nullptr": true,
+	"operator": true, "or": true, "or_eq": true, "private": true,
+	"protected": true, "public": true, "register": true,
+	"reinterpret_cast": true, "requires": true, "return": true, "short": true,
+	"signed": true, "sizeof": true, "static": true, "static_assert": true,
+	"static_cast": true, "struct": true, "switch": true, "template": true,
+	"this": true, "thread_local": true, "throw": true, "true": true,
+	"try": true, "typedef": true, "typeid": true, "typename": true,
+	"union": true, "unsigned": true, "using": true, "virtual": true,
+	"void": true, "volatile": true, "wchar_t": true, "while": true,
+	"xor": true, "xor_eq": true,
+}
+
+// Returns true if name is a keyword of C (as of C23).
+func IsKeyword(name string) bool {
+	return keywords[name]
+}
+
+// Returns true if name is a keyword of C++ (as of C++20).
+func IsCPlusPlusKeyword(name string) bool {
+	return cPlusPlusKeywords[name]
+}
+
+// A Tokenizer that tokenizes C and C++ source code. Each backslash-newline
+// line splice is removed before tokenizing. A '#' (or '%:') that is the first
+// Token of a line (and not part of a '##' or '%:%:') begins a preprocessor
+// directive, which is returned as a single Token by DirectiveNode, so that a
+// Tokenizer can be used with an eztok.Preprocessor (see NewPreprocessor). A
+// '#' or '##' anywhere else is a punctuator.
+type Tokenizer struct {
+	// If true, comments are returned as TokenTypeComment Token objects.
+	// Otherwise, they are skipped.
+	KeepComments       bool
+	nodeTokenizer      *eztok.InOrderNodeTokenizer
+	directiveTokenizer *eztok.InOrderNodeTokenizer
+}
+
+// Returns a new Tokenizer that skips comments.
+func NewTokenizer() *Tokenizer {
+	spellings := append([]string{}, punctuators...)
+	for digraph := range digraphs {
+		spellings = append(spellings, digraph)
+	}
+	// Try longer punctuators first, so that shorter ones do not shadow them.
+	sort.Slice(spellings, func(i, j int) bool {
+		if len(spellings[i]) != len(spellings[j]) {
+			return len(spellings[i]) > len(spellings[j])
+		}
+		return spellings[i] < spellings[j]
+	})
+	nodes := []eztok.Node{
+		WhitespaceNode,
+		CommentNode,
+		CharacterNode,
+		StringNode,
+		IdentifierNode,
+		NumberNode,
+	}
+	for _, spelling := range spellings {
+		tokenType := spelling
+		if punctuator, ok := digraphs[spelling]; ok {
+			tokenType = punctuator
+		}
+		nodes = append(nodes, eztok.NewStringMatchNode(eztok.TokenType(tokenType), spelling))
+	}
+	return &Tokenizer{
+		KeepComments:       false,
+		nodeTokenizer:      eztok.NewInOrderNodeTokenizer(nodes...),
+		directiveTokenizer: eztok.NewInOrderNodeTokenizer(DirectiveNode),
+	}
+}
+
+// Returns a new eztok.Preprocessor that tokenizes input with a new Tokenizer,
+// and that supports function-like macros.
+func NewPreprocessor(resolver eztok.IncludeResolver) *eztok.Preprocessor {
+	pp := eztok.NewPreprocessor(NewTokenizer(), resolver)
+	pp.OpenParenType = "("
+	pp.CloseParenType = ")"
+	pp.CommaType = ","
+	return pp
+}
+
+// For as long as eztok.IsEOF does not return true, tokenizes the next Token
+// as described by Tokenizer.
+func (tizer *Tokenizer) Tokenize(ctx eztok.Context) ([]*eztok.Token, error) {
+	ctx = newSpliceContext(ctx)
+	toks := []*eztok.Token{}
+	// True if no Token has been found since the start of the current line.
+	atLineStart := true
+	for !eztok.IsEOF(ctx) {
+		if ctx.PeekRune(0) == '\n' {
+			ctx.NextRune()
+			atLineStart = true
+			continue
+		}
+		var tok *eztok.Token
+		var err error
+		if atLineStart && isDirectiveStart(ctx) {
+			tok, err = tizer.directiveTokenizer.TokenizeNext(ctx)
+		} else {
+			tok, err = tizer.nodeTokenizer.TokenizeNext(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if tok == nil {
+			continue
+		}
+		if tok.TokenType == TokenTypeComment {
+			if tizer.KeepComments {
+				toks = append(toks, tok)
+			}
+			continue
+		}
+		toks = append(toks, tok)
+		atLineStart = false
+	}
+	if fallible, ok := ctx.(eztok.FallibleContext); ok && fallible.Err() != nil {
+		return nil, fallible.Err()
+	}
+	return toks, nil
+}
+
+// Returns true if the next Token in the Context is a '#' or '%:' that begins a
+// directive at the start of a line, rather than a '##' or '%:%:' punctuator.
+func isDirectiveStart(ctx eztok.Context) bool {
+	switch {
+	case ctx.PeekRune(0) == '#':
+		return ctx.PeekRune(1) != '#'
+	case ctx.PeekRune(0) == '%' && ctx.PeekRune(1) == ':':
+		return ctx.PeekRune(2) != '%' || ctx.PeekRune(3) != ':'
+	}
+	return false
+}
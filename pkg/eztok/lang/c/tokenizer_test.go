@@ -0,0 +1,102 @@
+package c
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+func TestTokenizerDigraphs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		// The Token objects returned, or "" if tokenizing fails.
+		want string
+	}{
+		{"brackets", "a<:1:>", "identifier a at 1:1, [ <: at 1:2, integer_constant 1 at 1:4, ] :> at 1:5"},
+		{"braces", "<%x%>", "{ <% at 1:1, identifier x at 1:3, } %> at 1:4"},
+		{"punctuators mid-line", "a %: b %:%: c", "identifier a at 1:1, # %: at 1:3, identifier b at 1:6, ## %:%: at 1:8, identifier c at 1:13"},
+		{"directive", "%:define X\nX", "directive define \" X\" at 1:1, identifier X at 2:1"},
+		{"'##' at line start", "## x", "## ## at 1:1, identifier x at 1:4"},
+		{"'%:%:' at line start", "%:%: x", "## %:%: at 1:1, identifier x at 1:6"},
+		{"mixed spellings at line start", "#%: x", "directive  \"%: x\" at 1:1"},
+		{"longest punctuator", "a<<=b<=c", "identifier a at 1:1, <<= <<= at 1:2, identifier b at 1:5, <= <= at 1:6, identifier c at 1:8"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := eztok.TokenizeString(NewTokenizer(), test.input)
+			if test.want == "" {
+				if err == nil {
+					t.Fatalf("tokenizing %q returned %v, want an error", test.input, cTestString(toks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizing %q returned error: %v", test.input, err)
+			}
+			if got := cTestString(toks); got != test.want {
+				t.Fatalf("tokenizing %q returned:\n%v\nwant:\n%v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizerLineSplices(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		// The Token objects returned, or "" if tokenizing fails.
+		want string
+	}{
+		{"identifier", "ab\\\ncd", "identifier abcd at 1:1"},
+		{"CRLF", "ab\\\r\ncd", "identifier abcd at 1:1"},
+		{"consecutive", "a\\\n\\\nb", "identifier ab at 1:1"},
+		{"punctuator", "+\\\n=", "+= += at 1:1"},
+		{"digraph", "<\\\n:", "[ <: at 1:1"},
+		{"string literal", "\"a\\\nb\"", "string_literal \"ab\" at 1:1"},
+		{"between Token objects", "a \\\n b", "identifier a at 1:1, identifier b at 2:2"},
+		{"line comment", "// c \\\nx\ny", "identifier y at 3:1"},
+		{"directive", "#define X \\\n 1\nX", "directive define \" X  1\" at 1:1, identifier X at 3:1"},
+		// A line splice does not end a line, so '#' is not at its start.
+		{"before '#'", "a \\\n#b", "identifier a at 1:1, # # at 2:1, identifier b at 2:2"},
+		{"backslash at end", "x\\", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := eztok.TokenizeString(NewTokenizer(), test.input)
+			if test.want == "" {
+				if err == nil {
+					t.Fatalf("tokenizing %q returned %v, want an error", test.input, cTestString(toks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizing %q returned error: %v", test.input, err)
+			}
+			if got := cTestString(toks); got != test.want {
+				t.Fatalf("tokenizing %q returned:\n%v\nwant:\n%v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+// Returns a string of the TokenType, value and Origin line and column of each
+// Token in toks, separated by commas.
+func cTestString(toks []*eztok.Token) string {
+	strs := make([]string, len(toks))
+	for i, tok := range toks {
+		value := fmt.Sprint(tok.Value)
+		switch tokValue := tok.Value.(type) {
+		case *eztok.Directive:
+			value = fmt.Sprintf("%v %q", tokValue.Name, tokValue.Body)
+		case *Literal:
+			value = fmt.Sprintf("%q", tokValue.Value)
+		case *Number:
+			value = tokValue.Text
+		}
+		strs[i] = fmt.Sprintf("%v %v at %v:%v", tok.TokenType, value, tok.Origin.LineNum, tok.Origin.ColNum)
+	}
+	return strings.Join(strs, ", ")
+}
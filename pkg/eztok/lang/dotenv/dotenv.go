@@ -0,0 +1,297 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// TokenType definitions returned by Tokenizer.
+const (
+	TokenTypeNewline eztok.TokenType = "newline"
+	TokenTypeComment eztok.TokenType = "comment"
+	TokenTypeExport  eztok.TokenType = "export"
+	TokenTypeKey     eztok.TokenType = "key"
+	TokenTypeValue   eztok.TokenType = "value"
+)
+
+// Holds a map of escape rune (a rune following a '\' in a double-quoted value)
+// to the actual string contents of the escape. A '\' followed by any other
+// rune is kept as written.
+var escapedRuneToString = map[rune]string{
+	'\\': "\\",
+	'\'': "'",
+	'"':  "\"",
+	'$':  "$",
+	'a':  "\a",
+	'b':  "\b",
+	'f':  "\f",
+	'n':  "\n",
+	'r':  "\r",
+	't':  "\t",
+	'v':  "\v",
+}
+
+// The position of a Tokenizer within a line.
+type lineState int
+
+const (
+	// Before the key of the line.
+	lineStateStart lineState = iota
+	// After the key (and '=') of the line.
+	lineStateAfterKey
+	// After the value of the line.
+	lineStateEnd
+)
+
+// A Tokenizer that tokenizes .env files. Each line is blank, a '#' comment, or
+// an assignment of a value to a key (such as KEY=value), which may be
+// preceded by 'export'. An assignment returns an optional TokenTypeExport
+// Token, a TokenTypeKey Token with the key as its Value, and a TokenTypeValue
+// Token with the value as its Value (see ValueNode). A TokenTypeNewline Token
+// is returned for each line break that is not within a quoted value.
+type Tokenizer struct {
+	// If true, comments are returned as TokenTypeComment Token objects.
+	// Otherwise, they are skipped.
+	KeepComments bool
+	// The InOrderNodeTokenizer used in each lineState.
+	nodeTokenizers map[lineState]*eztok.InOrderNodeTokenizer
+}
+
+// Returns a new Tokenizer that skips comments.
+func NewTokenizer() *Tokenizer {
+	return &Tokenizer{
+		KeepComments: false,
+		nodeTokenizers: map[lineState]*eztok.InOrderNodeTokenizer{
+			lineStateStart: eztok.NewInOrderNodeTokenizer(
+				NewlineNode,
+				WhitespaceNode,
+				CommentNode,
+				ExportNode,
+				KeyNode,
+			),
+			lineStateAfterKey: eztok.NewInOrderNodeTokenizer(ValueNode),
+			lineStateEnd: eztok.NewInOrderNodeTokenizer(
+				NewlineNode,
+				WhitespaceNode,
+				CommentNode,
+			),
+		},
+	}
+}
+
+// For as long as eztok.IsEOF does not return true, tokenizes the next Token
+// as described by Tokenizer. A key at the end of the input is given an empty
+// value.
+func (tizer *Tokenizer) Tokenize(ctx eztok.Context) ([]*eztok.Token, error) {
+	toks := []*eztok.Token{}
+	state := lineStateStart
+	for !eztok.IsEOF(ctx) {
+		tok, err := tizer.nodeTokenizers[state].TokenizeNext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if tok == nil || (tok.TokenType == TokenTypeComment && !tizer.KeepComments) {
+			continue
+		}
+		switch tok.TokenType {
+		case TokenTypeNewline:
+			state = lineStateStart
+		case TokenTypeKey:
+			state = lineStateAfterKey
+		case TokenTypeValue:
+			state = lineStateEnd
+		}
+		toks = append(toks, tok)
+	}
+	if fallible, ok := ctx.(eztok.FallibleContext); ok && fallible.Err() != nil {
+		return nil, fallible.Err()
+	}
+	if state == lineStateAfterKey {
+		tok := eztok.NewToken(TokenTypeValue, "")
+		tok.Origin = ctx.GetNextOrigin()
+		toks = append(toks, tok)
+	}
+	return toks, nil
+}
+
+// A node that matches a space, tab or carriage return (that is not part of a
+// line break) and skips it (i.e. generates no Token).
+var WhitespaceNode = eztok.NewCallbackNodeWithPattern(
+	`[\t\r ]`,
+	func(ctx eztok.Context) bool {
+		return isWhitespaceRune(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.NextRune(); !isWhitespaceRune(r) {
+			return nil, fmt.Errorf("expected a whitespace rune but got a '%c' rune", r)
+		}
+		return nil, nil
+	},
+)
+
+// A node that matches a line break ("\n" or "\r\n"). Returns a Token with a
+// TokenType of TokenTypeNewline and a Value of "\n".
+var NewlineNode = eztok.NewCallbackNodeWithPattern(
+	`\r?\n`,
+	func(ctx eztok.Context) bool {
+		return isAtLineEnd(ctx)
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if ctx.PeekRune(0) == '\r' {
+			ctx.NextRune()
+		}
+		if r := ctx.NextRune(); r != '\n' {
+			return nil, fmt.Errorf("expected a newline but got a '%c' rune", r)
+		}
+		return eztok.NewToken(TokenTypeNewline, "\n"), nil
+	},
+)
+
+// A node that matches a '#' comment, up to (but not including) the end of its
+// line. Returns a Token with a TokenType of TokenTypeComment and the text of
+// the comment (including its '#') as its Value.
+var CommentNode = eztok.NewCallbackNodeWithPattern(
+	`#`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '#'
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		text := eztok.ReadRunesUntil(ctx, func(r rune) bool {
+			return isAtLineEnd(ctx)
+		})
+		return eztok.NewToken(TokenTypeComment, text), nil
+	},
+)
+
+// A node that matches the word 'export' when followed by whitespace. Returns a
+// Token with a TokenType of TokenTypeExport and a Value of "export".
+var ExportNode = eztok.NewCallbackNodeWithPattern(
+	`export[\t ]`,
+	func(ctx eztok.Context) bool {
+		return eztok.HasPrefix(ctx, "export") && (ctx.PeekRune(6) == ' ' || ctx.PeekRune(6) == '\t')
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if !eztok.HasPrefix(ctx, "export") {
+			return nil, fmt.Errorf("expected 'export'")
+		}
+		eztok.SkipRunes(ctx, 6)
+		return eztok.NewToken(TokenTypeExport, "export"), nil
+	},
+)
+
+// A node that matches a key (a letter or '_', followed by any letters, digits,
+// '_', '.' or '-'), followed by an '=' (which may be surrounded by spaces or
+// tabs). Returns a Token with a TokenType of TokenTypeKey and the key as its
+// Value.
+var KeyNode = eztok.NewCallbackNodeWithPattern(
+	`[A-Za-z_]`,
+	func(ctx eztok.Context) bool {
+		return isKeyStartRune(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.PeekRune(0); !isKeyStartRune(r) {
+			return nil, fmt.Errorf("expected a key but got a '%c' rune", r)
+		}
+		key := eztok.ReadRunesUntilNot(ctx, isKeyRune)
+		skipWhitespace(ctx)
+		if ctx.PeekRune(0) != '=' {
+			return nil, fmt.Errorf("expected '=' after key '%v'", key)
+		}
+		ctx.NextRune()
+		return eztok.NewToken(TokenTypeKey, key), nil
+	},
+)
+
+// A node that matches the value following a key, after any spaces or tabs. A
+// value may be single-quoted (no escaping is done), double-quoted (a '\'
+// escapes the following rune, see escapedRuneToString) or backquoted (no
+// escaping is done); a quoted value may span multiple lines. An unquoted value
+// continues until the end of its line, or a '#' that follows whitespace
+// (including the whitespace after the '='), and has trailing whitespace
+// removed; it may be empty. Variable references such
+// as ${NAME} are not expanded. Returns a Token with a TokenType of
+// TokenTypeValue and the value as its Value, whose Origin is that of the value
+// rather than any preceding whitespace.
+var ValueNode = eztok.NewCallbackNode(
+	func(ctx eztok.Context) bool {
+		return true
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		followsWhitespace := skipWhitespace(ctx)
+		origin := ctx.GetNextOrigin()
+		var builder strings.Builder
+		value := ""
+		switch quote := ctx.PeekRune(0); quote {
+		case '\'', '"', '`':
+			ctx.NextRune()
+			for {
+				if eztok.IsEOF(ctx) {
+					return nil, fmt.Errorf("unterminated value '%c%v'", quote, builder.String())
+				}
+				r := ctx.NextRune()
+				if r == quote {
+					break
+				}
+				if r == '\\' && quote == '"' && !eztok.IsEOF(ctx) {
+					escape := ctx.NextRune()
+					if replStr, ok := escapedRuneToString[escape]; ok {
+						builder.WriteString(replStr)
+					} else {
+						builder.WriteRune(r)
+						builder.WriteRune(escape)
+					}
+					continue
+				}
+				builder.WriteRune(r)
+			}
+			value = builder.String()
+		default:
+			if followsWhitespace && quote == '#' {
+				// The value is empty, and followed by a comment.
+				break
+			}
+			for !eztok.IsEOF(ctx) && !isAtLineEnd(ctx) {
+				r := ctx.NextRune()
+				builder.WriteRune(r)
+				if isWhitespaceRune(r) && ctx.PeekRune(0) == '#' {
+					break
+				}
+			}
+			value = strings.TrimRight(builder.String(), " \t\r")
+		}
+		tok := eztok.NewToken(TokenTypeValue, value)
+		tok.Origin = origin
+		return tok, nil
+	},
+)
+
+// Consumes any spaces or tabs (and carriage returns that are not part of a
+// line break). Returns true if any were consumed.
+func skipWhitespace(ctx eztok.Context) bool {
+	return len(eztok.ReadRunesUntilNot(ctx, func(r rune) bool {
+		return isWhitespaceRune(r) && !isAtLineEnd(ctx)
+	})) > 0
+}
+
+// Returns true if a line break ("\n" or "\r\n") begins at the current rune in
+// the Context.
+func isAtLineEnd(ctx eztok.Context) bool {
+	return ctx.PeekRune(0) == '\n' || (ctx.PeekRune(0) == '\r' && ctx.PeekRune(1) == '\n')
+}
+
+// Returns true if r is a space, tab or carriage return.
+func isWhitespaceRune(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r'
+}
+
+// Returns true if r can begin a key.
+func isKeyStartRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_'
+}
+
+// Returns true if r can continue a key.
+func isKeyRune(r rune) bool {
+	return isKeyStartRune(r) || (r >= '0' && r <= '9') || r == '.' || r == '-'
+}
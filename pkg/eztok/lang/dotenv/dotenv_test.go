@@ -0,0 +1,74 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+func TestTokenizer(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		// The Token objects returned, or "" if tokenizing fails.
+		want string
+	}{
+		{"unquoted", "A=x y  \n", `key "A" at 1:1, value "x y" at 1:3, newline "\n" at 1:8`},
+		{"export", "export A=1", `export "export" at 1:1, key "A" at 1:8, value "1" at 1:10`},
+		{"spaces around '='", "E = `v` ", `key "E" at 1:1, value "v" at 1:5`},
+		{"empty value at end", "A=", `key "A" at 1:1, value "" at 1:3`},
+		{"comment after whitespace", "F=a #x", `key "F" at 1:1, value "a" at 1:3, comment "#x" at 1:5`},
+		{"comment after '=' and whitespace", "F= #x", `key "F" at 1:1, value "" at 1:4, comment "#x" at 1:4`},
+		{"'#' directly after '='", "F=#x", `key "F" at 1:1, value "#x" at 1:3`},
+		{"'#' within value", "F=a#x", `key "F" at 1:1, value "a#x" at 1:3`},
+		{"single-quoted multi-line", "B='x\ny' # c\r\nC=1",
+			`key "B" at 1:1, value "x\ny" at 1:3, comment "# c" at 2:4, newline "\n" at 2:7, key "C" at 3:1, value "1" at 3:3`},
+		{"double-quoted escapes", `C="a\nb\q\""`, `key "C" at 1:1, value "a\nb\\q\"" at 1:3`},
+		{"single-quoted is not escaped", `C='a\n'`, `key "C" at 1:1, value "a\\n" at 1:3`},
+		{"comment line", "  # c\nA=1", `comment "# c" at 1:3, newline "\n" at 1:6, key "A" at 2:1, value "1" at 2:3`},
+		{"unterminated quote", "A='x", ""},
+		{"missing '='", "A b", ""},
+		{"invalid key", "1=2", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tizer := NewTokenizer()
+			tizer.KeepComments = true
+			toks, err := eztok.TokenizeString(tizer, test.input)
+			if test.want == "" {
+				if err == nil {
+					t.Fatalf("tokenizing %q returned %v, want an error", test.input, dotenvTestString(toks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizing %q returned error: %v", test.input, err)
+			}
+			if got := dotenvTestString(toks); got != test.want {
+				t.Fatalf("tokenizing %q returned:\n%v\nwant:\n%v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizerSkipsComments(t *testing.T) {
+	toks, err := eztok.TokenizeString(NewTokenizer(), "# c\nF= #x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dotenvTestString(toks), `newline "\n" at 1:4, key "F" at 2:1, value "" at 2:4`; got != want {
+		t.Fatalf("Tokenize returned %v, want %v", got, want)
+	}
+}
+
+// Returns a string of the TokenType, Value and Origin line and column of each
+// Token in toks, separated by commas.
+func dotenvTestString(toks []*eztok.Token) string {
+	strs := make([]string, len(toks))
+	for i, tok := range toks {
+		strs[i] = fmt.Sprintf("%v %q at %v:%v", tok.TokenType, tok.Value, tok.Origin.LineNum, tok.Origin.ColNum)
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,464 @@
+package golang
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// Holds a map of escape rune (a rune following a '\' in a rune or string
+// literal) to the rune it represents. Quote escapes are handled separately,
+// since they depend on the literal.
+var escapedRuneToRune = map[rune]rune{
+	'a':  '\a',
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  '\v',
+	'\\': '\\',
+}
+
+// The set of keywords of Go.
+var keywordSet = func() map[string]bool {
+	set := map[string]bool{}
+	for _, keyword := range keywords {
+		set[keyword] = true
+	}
+	return set
+}()
+
+// A node that matches a space, tab or carriage return rune and skips it (i.e.
+// generates no Token). Newlines are handled by Tokenizer, since they may
+// insert a semicolon.
+var WhitespaceNode = eztok.NewCallbackNodeWithPattern(
+	`[\t\r ]`,
+	func(ctx eztok.Context) bool {
+		r := ctx.PeekRune(0)
+		return r == ' ' || r == '\t' || r == '\r'
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.NextRune(); r != ' ' && r != '\t' && r != '\r' {
+			return nil, fmt.Errorf("expected a whitespace rune but got a '%c' rune", r)
+		}
+		return nil, nil
+	},
+)
+
+// A node that matches a '//' comment (up to, but not including, the end of its
+// line) or a '/* */' comment. Returns a Token with a TokenType of
+// TokenTypeComment and the text of the comment (including its delimiters) as
+// its Value.
+var CommentNode = eztok.NewCallbackNodeWithPattern(
+	`/[/*]`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '/' && (ctx.PeekRune(1) == '/' || ctx.PeekRune(1) == '*')
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		var builder strings.Builder
+		builder.WriteRune(ctx.NextRune())
+		switch kind := ctx.NextRune(); kind {
+		case '/':
+			builder.WriteRune(kind)
+			for !eztok.IsEOF(ctx) && ctx.PeekRune(0) != '\n' {
+				builder.WriteRune(ctx.NextRune())
+			}
+		case '*':
+			builder.WriteRune(kind)
+			for !eztok.HasPrefix(ctx, "*/") {
+				if eztok.IsEOF(ctx) {
+					return nil, fmt.Errorf("comment not terminated")
+				}
+				builder.WriteRune(ctx.NextRune())
+			}
+			eztok.SkipRunes(ctx, 2)
+			builder.WriteString("*/")
+		default:
+			return nil, fmt.Errorf("expected a comment")
+		}
+		return eztok.NewToken(TokenTypeComment, builder.String()), nil
+	},
+)
+
+// A node that matches an identifier or keyword. An identifier begins with a
+// letter or '_', and is followed by 0 or more letters, digits, or '_'s.
+// Returns a Token with a TokenType of eztok.TokenTypeIdentifier, or the
+// keyword itself for a keyword, and the name as its Value.
+var IdentifierNode = eztok.NewCallbackNodeWithPattern(
+	`[\p{L}_]`,
+	func(ctx eztok.Context) bool {
+		r := ctx.PeekRune(0)
+		return unicode.IsLetter(r) || r == '_'
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		var builder strings.Builder
+		for !eztok.IsEOF(ctx) {
+			r := ctx.PeekRune(0)
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+				break
+			}
+			builder.WriteRune(ctx.NextRune())
+		}
+		name := builder.String()
+		if len(name) <= 0 {
+			return nil, fmt.Errorf("expected an identifier but got an empty identifier")
+		}
+		if keywordSet[name] {
+			return eztok.NewToken(eztok.TokenType(name), name), nil
+		}
+		return eztok.NewToken(eztok.TokenTypeIdentifier, name), nil
+	},
+)
+
+// A node that matches an integer, floating-point or imaginary literal,
+// including base prefixes, '_' digit separators, hexadecimal floating-point
+// literals and legacy octal literals (such as 0755). Returns a Token with a
+// TokenType of TokenTypeInt, TokenTypeFloat or TokenTypeImaginary, and the
+// text of the literal as its Value (since Go constants are not limited in
+// size; see strconv or go/constant to convert it).
+var NumberNode = eztok.NewCallbackNodeWithPattern(
+	`\.?[0-9]`,
+	func(ctx eztok.Context) bool {
+		r := ctx.PeekRune(0)
+		return isDecimalRune(r) || (r == '.' && isDecimalRune(ctx.PeekRune(1)))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		scanner := &numberScanner{ctx: ctx, invalidDigit: -1}
+		return scanner.scan()
+	},
+)
+
+// A node that matches a rune literal encased in single quotes ('). Returns a
+// Token with a TokenType of TokenTypeRune and the rune as its Value. The
+// value of an octal or hexadecimal byte escape (such as '\xff') is returned as
+// a rune of the same value.
+var RuneNode = eztok.NewCallbackNodeWithPattern(
+	`'`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '\''
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		ctx.NextRune()
+		runeCount := 0
+		value := rune(0)
+		for {
+			if eztok.IsEOF(ctx) || ctx.PeekRune(0) == '\n' {
+				return nil, fmt.Errorf("rune literal not terminated")
+			}
+			r := ctx.NextRune()
+			if r == '\'' {
+				break
+			}
+			runeCount++
+			if r == '\\' {
+				escaped, _, err := readEscape(ctx, '\'')
+				if err != nil {
+					return nil, err
+				}
+				r = escaped
+			}
+			value = r
+		}
+		if runeCount != 1 {
+			return nil, fmt.Errorf("illegal rune literal")
+		}
+		return eztok.NewToken(TokenTypeRune, value), nil
+	},
+)
+
+// A node that matches an interpreted string literal encased in double quotes
+// ("). Returns a Token with a TokenType of TokenTypeString and the unescaped
+// string as its Value. Octal and hexadecimal byte escapes (such as "\xff")
+// produce single bytes, so the Value may not be valid UTF-8.
+var StringNode = eztok.NewCallbackNodeWithPattern(
+	`"`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '"'
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		ctx.NextRune()
+		value := []byte{}
+		for {
+			if eztok.IsEOF(ctx) || ctx.PeekRune(0) == '\n' {
+				return nil, fmt.Errorf("string literal not terminated")
+			}
+			r := ctx.NextRune()
+			if r == '"' {
+				break
+			}
+			if r == '\\' {
+				escaped, isByte, err := readEscape(ctx, '"')
+				if err != nil {
+					return nil, err
+				}
+				if isByte {
+					value = append(value, byte(escaped))
+					continue
+				}
+				r = escaped
+			}
+			value = utf8.AppendRune(value, r)
+		}
+		return eztok.NewToken(TokenTypeString, string(value)), nil
+	},
+)
+
+// A node that matches a raw string literal encased in back quotes (`), which
+// may span multiple lines. Returns a Token with a TokenType of TokenTypeString
+// and the contents of the literal, with any carriage returns removed, as its
+// Value.
+var RawStringNode = eztok.NewCallbackNodeWithPattern(
+	"`",
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '`'
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		ctx.NextRune()
+		var builder strings.Builder
+		for {
+			if eztok.IsEOF(ctx) {
+				return nil, fmt.Errorf("raw string literal not terminated")
+			}
+			r := ctx.NextRune()
+			if r == '`' {
+				break
+			}
+			if r != '\r' {
+				builder.WriteRune(r)
+			}
+		}
+		return eztok.NewToken(TokenTypeString, builder.String()), nil
+	},
+)
+
+// Consumes the escape following a '\' (which has already been consumed) in a
+// rune or string literal encased in quoteRune. Returns the escaped rune, and
+// true if it is the value of an octal or hexadecimal byte escape.
+func readEscape(ctx eztok.Context, quoteRune rune) (rune, bool, error) {
+	if eztok.IsEOF(ctx) || ctx.PeekRune(0) == '\n' {
+		return 0, false, fmt.Errorf("escape sequence not terminated")
+	}
+	escape := ctx.NextRune()
+	if escaped, ok := escapedRuneToRune[escape]; ok {
+		return escaped, false, nil
+	}
+	digitCount, base, maxValue := 0, rune(0), rune(0)
+	switch {
+	case escape == quoteRune:
+		return escape, false, nil
+	case escape >= '0' && escape <= '7':
+		digitCount, base, maxValue = 2, 8, 255
+	case escape == 'x':
+		digitCount, base, maxValue = 2, 16, 255
+	case escape == 'u':
+		digitCount, base, maxValue = 4, 16, unicode.MaxRune
+	case escape == 'U':
+		digitCount, base, maxValue = 8, 16, unicode.MaxRune
+	default:
+		return 0, false, fmt.Errorf("unknown escape sequence '\\%c'", escape)
+	}
+
+	value := rune(0)
+	if base == 8 {
+		value = escape - '0'
+	}
+	for i := 0; i < digitCount; i++ {
+		digit := digitValue(ctx.PeekRune(0))
+		if digit >= base || eztok.IsEOF(ctx) {
+			return 0, false, fmt.Errorf("illegal character '%c' in escape sequence", ctx.PeekRune(0))
+		}
+		ctx.NextRune()
+		value = value*base + digit
+	}
+	if base == 8 && value > maxValue {
+		return 0, false, fmt.Errorf("octal escape value %v > 255", value)
+	}
+	if value > maxValue || (digitCount > 2 && value >= 0xD800 && value < 0xE000) {
+		return 0, false, fmt.Errorf("escape sequence is invalid Unicode code point %#U", value)
+	}
+	return value, digitCount == 2, nil
+}
+
+// Holds the state of the scanning of a number literal by NumberNode.
+type numberScanner struct {
+	ctx eztok.Context
+	// The text of the literal so far.
+	text strings.Builder
+	// The index in text of the first digit that is invalid for the base of
+	// the literal, or -1 if there is none.
+	invalidDigit int
+}
+
+// Consumes a number literal and returns its Token. The literal is scanned
+// leniently, and then checked for errors.
+func (scanner *numberScanner) scan() (*eztok.Token, error) {
+	ctx := scanner.ctx
+	tokenType := TokenTypeInt
+	base, prefix := 10, rune(0)
+	// Bit 0 is set if a digit was found, and bit 1 if a '_' was found.
+	digitSeparator := 0
+
+	if ctx.PeekRune(0) != '.' {
+		if ctx.PeekRune(0) == '0' {
+			scanner.next()
+			switch unicode.ToLower(ctx.PeekRune(0)) {
+			case 'x':
+				scanner.next()
+				base, prefix = 16, 'x'
+			case 'o':
+				scanner.next()
+				base, prefix = 8, 'o'
+			case 'b':
+				scanner.next()
+				base, prefix = 2, 'b'
+			default:
+				// A legacy octal literal, whose leading '0' is a digit.
+				base, prefix = 8, '0'
+				digitSeparator = 1
+			}
+		}
+		digitSeparator |= scanner.digits(base)
+	}
+	if ctx.PeekRune(0) == '.' {
+		tokenType = TokenTypeFloat
+		if prefix == 'o' || prefix == 'b' {
+			return nil, fmt.Errorf("invalid radix point in %v", literalName(prefix))
+		}
+		scanner.next()
+		digitSeparator |= scanner.digits(base)
+	}
+	if digitSeparator&1 == 0 {
+		return nil, fmt.Errorf("%v has no digits", literalName(prefix))
+	}
+
+	if exponent := unicode.ToLower(ctx.PeekRune(0)); exponent == 'e' || exponent == 'p' {
+		if exponent == 'e' && prefix != 0 && prefix != '0' {
+			return nil, fmt.Errorf("'%c' exponent requires decimal mantissa", ctx.PeekRune(0))
+		} else if exponent == 'p' && prefix != 'x' {
+			return nil, fmt.Errorf("'%c' exponent requires hexadecimal mantissa", ctx.PeekRune(0))
+		}
+		scanner.next()
+		tokenType = TokenTypeFloat
+		if r := ctx.PeekRune(0); r == '+' || r == '-' {
+			scanner.next()
+		}
+		exponentDigits := scanner.digits(10)
+		digitSeparator |= exponentDigits
+		if exponentDigits&1 == 0 {
+			return nil, fmt.Errorf("exponent has no digits")
+		}
+	} else if prefix == 'x' && tokenType == TokenTypeFloat {
+		return nil, fmt.Errorf("hexadecimal mantissa requires a 'p' exponent")
+	}
+
+	if ctx.PeekRune(0) == 'i' {
+		scanner.next()
+		tokenType = TokenTypeImaginary
+	}
+
+	text := scanner.text.String()
+	if tokenType == TokenTypeInt && scanner.invalidDigit >= 0 {
+		return nil, fmt.Errorf("invalid digit '%c' in %v", text[scanner.invalidDigit], literalName(prefix))
+	}
+	if digitSeparator&2 != 0 && invalidSeparator(text) >= 0 {
+		return nil, fmt.Errorf("'_' must separate successive digits")
+	}
+	return eztok.NewToken(tokenType, text), nil
+}
+
+// Consumes the next rune into the text of the literal.
+func (scanner *numberScanner) next() {
+	scanner.text.WriteRune(scanner.ctx.NextRune())
+}
+
+// Consumes the digits and '_' separators of base (decimal digits for a base
+// of 10 or less), noting the first digit invalid for base. Returns whether
+// digits (bit 0) and separators (bit 1) were found.
+func (scanner *numberScanner) digits(base int) int {
+	digitSeparator := 0
+	for {
+		r := scanner.ctx.PeekRune(0)
+		if r == '_' {
+			digitSeparator |= 2
+		} else if (base <= 10 && isDecimalRune(r)) || (base > 10 && digitValue(r) < 16) {
+			digitSeparator |= 1
+			if base <= 10 && digitValue(r) >= rune(base) && scanner.invalidDigit < 0 {
+				scanner.invalidDigit = scanner.text.Len()
+			}
+		} else {
+			return digitSeparator
+		}
+		scanner.next()
+	}
+}
+
+// Returns the index of the first '_' in the number literal text that does not
+// separate successive digits, or -1 if there is none.
+func invalidSeparator(text string) int {
+	// The rune before the current one: '_', '0' (any digit, including a base
+	// prefix), or '.' (anything else).
+	previous := '.'
+	isHex := false
+	i := 0
+	if len(text) >= 2 && text[0] == '0' {
+		if base := unicode.ToLower(rune(text[1])); base == 'x' || base == 'o' || base == 'b' {
+			previous, isHex, i = '0', base == 'x', 2
+		}
+	}
+	for ; i < len(text); i++ {
+		r := rune(text[i])
+		switch {
+		case r == '_':
+			if previous != '0' {
+				return i
+			}
+			previous = '_'
+		case isDecimalRune(r) || (isHex && digitValue(r) < 16):
+			previous = '0'
+		default:
+			if previous == '_' {
+				return i - 1
+			}
+			previous = '.'
+		}
+	}
+	if previous == '_' {
+		return len(text) - 1
+	}
+	return -1
+}
+
+// Returns the name of the kind of number literal with prefix.
+func literalName(prefix rune) string {
+	switch prefix {
+	case 'x':
+		return "hexadecimal literal"
+	case 'o', '0':
+		return "octal literal"
+	case 'b':
+		return "binary literal"
+	}
+	return "decimal literal"
+}
+
+// Returns the value of the hexadecimal digit r, or 16 if r is not one.
+func digitValue(r rune) rune {
+	switch {
+	case r >= '0' && r <= '9':
+		return r - '0'
+	case r >= 'a' && r <= 'f':
+		return r - 'a' + 10
+	case r >= 'A' && r <= 'F':
+		return r - 'A' + 10
+	}
+	return 16
+}
+
+// Returns true if r is an ASCII decimal digit.
+func isDecimalRune(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,157 @@
+package golang
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// TokenType definitions returned by Tokenizer. The TokenType of a keyword or
+// operator Token is the keyword or operator itself (such as "func" or "<<="),
+// with a Value of the same string.
+const (
+	TokenTypeInt       eztok.TokenType = "int_lit"
+	TokenTypeFloat     eztok.TokenType = "float_lit"
+	TokenTypeImaginary eztok.TokenType = "imaginary_lit"
+	TokenTypeRune      eztok.TokenType = "rune_lit"
+	TokenTypeString    eztok.TokenType = "string_lit"
+	TokenTypeComment   eztok.TokenType = "comment"
+	TokenTypeSemicolon eztok.TokenType = ";"
+)
+
+// The keywords of Go.
+var keywords = []string{
+	"break", "case", "chan", "const", "continue", "default", "defer", "else",
+	"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+	"map", "package", "range", "return", "select", "struct", "switch", "type",
+	"var",
+}
+
+// The operators and punctuation of Go.
+var operators = []string{
+	"+", "&", "+=", "&=", "&&", "==", "!=", "(", ")",
+	"-", "|", "-=", "|=", "||", "<", "<=", "[", "]",
+	"*", "^", "*=", "^=", "<-", ">", ">=", "{", "}",
+	"/", "<<", "/=", "<<=", "++", "=", ":=", ",", ";",
+	"%", ">>", "%=", ">>=", "--", "!", "...", ".", ":",
+	"&^", "&^=", "~",
+}
+
+// Holds a map of each keyword and operator TokenType after which a newline
+// inserts a semicolon.
+var endsStatement = map[eztok.TokenType]bool{
+	eztok.TokenTypeIdentifier: true,
+	TokenTypeInt:              true,
+	TokenTypeFloat:            true,
+	TokenTypeImaginary:        true,
+	TokenTypeRune:             true,
+	TokenTypeString:           true,
+	"break":                   true,
+	"continue":                true,
+	"fallthrough":             true,
+	"return":                  true,
+	"++":                      true,
+	"--":                      true,
+	")":                       true,
+	"]":                       true,
+	"}":                       true,
+}
+
+// A Tokenizer that tokenizes Go source code, following the Go language
+// specification. A semicolon is automatically inserted at the end of a line
+// (or of the input) whose last Token is an identifier, a literal, one of the
+// keywords 'break', 'continue', 'fallthrough' or 'return', or one of the
+// operators '++', '--', ')', ']' or '}'. An inserted semicolon is a Token with
+// a TokenType of TokenTypeSemicolon and a Value of "\n", whose Origin is the
+// newline. A '/* */' comment containing a newline also ends a line, in which
+// case the semicolon follows the comment and has the Origin of the comment.
+type Tokenizer struct {
+	// If true, comments are returned as TokenTypeComment Token objects.
+	// Otherwise, they are skipped.
+	KeepComments  bool
+	nodeTokenizer *eztok.InOrderNodeTokenizer
+}
+
+// Returns a new Tokenizer that skips comments.
+func NewTokenizer() *Tokenizer {
+	// Try longer operators first, so that shorter ones do not shadow them.
+	sortedOperators := append([]string{}, operators...)
+	sort.SliceStable(sortedOperators, func(i, j int) bool {
+		return len(sortedOperators[i]) > len(sortedOperators[j])
+	})
+	nodes := []eztok.Node{
+		WhitespaceNode,
+		CommentNode,
+		IdentifierNode,
+		NumberNode,
+		RuneNode,
+		StringNode,
+		RawStringNode,
+	}
+	for _, operator := range sortedOperators {
+		nodes = append(nodes, eztok.NewStringMatchNode(eztok.TokenType(operator), operator))
+	}
+	return &Tokenizer{false, eztok.NewInOrderNodeTokenizer(nodes...)}
+}
+
+// For as long as eztok.IsEOF does not return true, tokenizes the next Token,
+// inserting semicolons at the end of lines as described by Tokenizer. A byte
+// order mark at the start of the input is skipped.
+func (tizer *Tokenizer) Tokenize(ctx eztok.Context) ([]*eztok.Token, error) {
+	toks := []*eztok.Token{}
+	insertSemicolon := false
+	if ctx.PeekRune(0) == '\uFEFF' {
+		ctx.NextRune()
+	}
+	for {
+		// A "\r\n" line break is handled as a whole, so that the Origin of an
+		// inserted semicolon is on the line it ends.
+		isCRLF := ctx.PeekRune(0) == '\r' && ctx.PeekRune(1) == '\n'
+		if eztok.IsEOF(ctx) || ctx.PeekRune(0) == '\n' || isCRLF {
+			if insertSemicolon {
+				toks = append(toks, newInsertedSemicolon(ctx.GetNextOrigin()))
+				insertSemicolon = false
+			}
+			if eztok.IsEOF(ctx) {
+				break
+			}
+			if isCRLF {
+				ctx.NextRune()
+			}
+			ctx.NextRune()
+			continue
+		}
+
+		tok, err := tizer.nodeTokenizer.TokenizeNext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if tok == nil {
+			continue
+		}
+		if tok.TokenType == TokenTypeComment {
+			if tizer.KeepComments {
+				toks = append(toks, tok)
+			}
+			if insertSemicolon && strings.ContainsRune(tok.Value.(string), '\n') {
+				toks = append(toks, newInsertedSemicolon(tok.Origin))
+				insertSemicolon = false
+			}
+			continue
+		}
+		toks = append(toks, tok)
+		insertSemicolon = endsStatement[tok.TokenType]
+	}
+	if fallible, ok := ctx.(eztok.FallibleContext); ok && fallible.Err() != nil {
+		return nil, fallible.Err()
+	}
+	return toks, nil
+}
+
+// Returns a semicolon Token inserted at the end of a line.
+func newInsertedSemicolon(origin *eztok.Origin) *eztok.Token {
+	tok := eztok.NewToken(TokenTypeSemicolon, "\n")
+	tok.Origin = origin
+	return tok
+}
@@ -0,0 +1,159 @@
+package golang
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// Compares the Tokenizer with go/scanner on every Go file of the standard
+// library: the kind, literal and line of each Token, including each inserted
+// semicolon. Files that go/scanner rejects are skipped. Each directory of the
+// standard library is compared in parallel. With -short, only the packages
+// within GOROOT/src/go are compared.
+func TestTokenizerMatchesGoScanner(t *testing.T) {
+	root := filepath.Join(runtime.GOROOT(), "src")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Skipf("cannot find the standard library: %v", err)
+	}
+	if testing.Short() {
+		t.Run("go", func(t *testing.T) {
+			compareGoFilesWithGoScanner(t, filepath.Join(root, "go"), true)
+		})
+		return
+	}
+	t.Run(".", func(t *testing.T) {
+		t.Parallel()
+		compareGoFilesWithGoScanner(t, root, false)
+	})
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != "testdata" {
+			dir := filepath.Join(root, entry.Name())
+			t.Run(entry.Name(), func(t *testing.T) {
+				t.Parallel()
+				compareGoFilesWithGoScanner(t, dir, true)
+			})
+		}
+	}
+}
+
+// Compares the Tokenizer with go/scanner on each Go file within dir, and within
+// its subdirectories other than testdata if recursive is true.
+func compareGoFilesWithGoScanner(t *testing.T, dir string, recursive bool) {
+	tizer := NewTokenizer()
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && (!recursive || entry.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		want, ok := goScannerTokens(src)
+		if !ok {
+			return nil
+		}
+		toks, err := eztok.TokenizeString(tizer, string(src))
+		if err != nil {
+			t.Errorf("%v: %v", path, err)
+			return nil
+		}
+		got := make([]string, len(toks))
+		for i, tok := range toks {
+			got[i] = goTestTokenString(tok)
+		}
+		for i := 0; i < len(got) || i < len(want); i++ {
+			if i >= len(got) || i >= len(want) || got[i] != want[i] {
+				t.Errorf("%v: Token %v is %v, want %v", path, i, goTestIndex(got, i), goTestIndex(want, i))
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Returns a string of the kind, literal and line of each token go/scanner
+// scans in src, and false if src has a syntax error.
+func goScannerTokens(src []byte) ([]string, bool) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var scan scanner.Scanner
+	failed := false
+	scan.Init(file, src, func(token.Position, string) { failed = true }, 0)
+	toks := []string{}
+	for {
+		pos, kind, lit := scan.Scan()
+		if kind == token.EOF {
+			break
+		}
+		line := file.Line(pos)
+		switch kind {
+		case token.IDENT, token.INT, token.FLOAT, token.IMAG:
+			toks = append(toks, fmt.Sprintf("%v %q at line %v", kind, lit, line))
+		case token.CHAR:
+			value, _, _, err := strconv.UnquoteChar(lit[1:len(lit)-1], '\'')
+			if err != nil {
+				return nil, false
+			}
+			toks = append(toks, fmt.Sprintf("CHAR %q at line %v", value, line))
+		case token.STRING:
+			value, err := strconv.Unquote(lit)
+			if err != nil {
+				return nil, false
+			}
+			toks = append(toks, fmt.Sprintf("STRING %q at line %v", value, line))
+		case token.SEMICOLON:
+			toks = append(toks, fmt.Sprintf("; %q at line %v", lit, line))
+		default:
+			toks = append(toks, fmt.Sprintf("%v %q at line %v", kind, kind.String(), line))
+		}
+	}
+	return toks, !failed
+}
+
+// Returns a string of tok in the format of goScannerTokens.
+func goTestTokenString(tok *eztok.Token) string {
+	kinds := map[eztok.TokenType]string{
+		eztok.TokenTypeIdentifier: "IDENT",
+		TokenTypeInt:              "INT",
+		TokenTypeFloat:            "FLOAT",
+		TokenTypeImaginary:        "IMAG",
+		TokenTypeRune:             "CHAR",
+		TokenTypeString:           "STRING",
+	}
+	kind, ok := kinds[tok.TokenType]
+	if !ok {
+		kind = string(tok.TokenType)
+	}
+	return fmt.Sprintf("%v %q at line %v", kind, tok.Value, tok.Origin.LineNum)
+}
+
+// Returns strs[i], or "nothing" if i is out of range.
+func goTestIndex(strs []string, i int) string {
+	if i < len(strs) {
+		return strs[i]
+	}
+	return "nothing"
+}
@@ -0,0 +1,257 @@
+package ini
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// TokenType definitions returned by Tokenizer.
+const (
+	TokenTypeNewline eztok.TokenType = "newline"
+	TokenTypeComment eztok.TokenType = "comment"
+	TokenTypeSection eztok.TokenType = "section"
+	TokenTypeKey     eztok.TokenType = "key"
+	TokenTypeValue   eztok.TokenType = "value"
+)
+
+// The position of a Tokenizer within a line.
+type lineState int
+
+const (
+	// Before any Token of the line.
+	lineStateStart lineState = iota
+	// After the key of a key-value pair.
+	lineStateAfterKey
+	// After the section header or value of the line.
+	lineStateEnd
+)
+
+// A Tokenizer that tokenizes INI files. Each line is blank, a comment (whose
+// first non-whitespace rune is a ';' or '#'), a section header (such as
+// [name]), or a key-value pair (such as name = value). A section header
+// returns a TokenTypeSection Token with the name of the section as its Value.
+// A key-value pair returns a TokenTypeKey Token with the key as its Value,
+// followed by a TokenTypeValue Token with the value as its Value if the key is
+// followed by one of the Delimiters (a key alone on its line has no value).
+// Keys, values and section names have surrounding whitespace removed, but are
+// otherwise as written. A TokenTypeNewline Token is returned for each line
+// break.
+type Tokenizer struct {
+	// If true, comments are returned as TokenTypeComment Token objects.
+	// Otherwise, they are skipped.
+	KeepComments bool
+	// If true, a ';' or '#' that follows whitespace within a value (or after
+	// its delimiter) begins a comment that ends the value.
+	InlineComments bool
+	// The runes that separate a key from its value.
+	Delimiters string
+	// The InOrderNodeTokenizer used in each lineState.
+	nodeTokenizers map[lineState]*eztok.InOrderNodeTokenizer
+}
+
+// Returns a new Tokenizer that skips comments, does not allow inline comments,
+// and whose Delimiters are '=' and ':'.
+func NewTokenizer() *Tokenizer {
+	tizer := &Tokenizer{
+		KeepComments:   false,
+		InlineComments: false,
+		Delimiters:     "=:",
+	}
+	tizer.nodeTokenizers = map[lineState]*eztok.InOrderNodeTokenizer{
+		lineStateStart: eztok.NewInOrderNodeTokenizer(
+			NewlineNode,
+			WhitespaceNode,
+			CommentNode,
+			SectionNode,
+			tizer.newKeyNode(),
+		),
+		lineStateAfterKey: eztok.NewInOrderNodeTokenizer(
+			NewlineNode,
+			tizer.newValueNode(),
+		),
+		lineStateEnd: eztok.NewInOrderNodeTokenizer(
+			NewlineNode,
+			WhitespaceNode,
+			CommentNode,
+		),
+	}
+	return tizer
+}
+
+// For as long as eztok.IsEOF does not return true, tokenizes the next Token
+// as described by Tokenizer.
+func (tizer *Tokenizer) Tokenize(ctx eztok.Context) ([]*eztok.Token, error) {
+	toks := []*eztok.Token{}
+	state := lineStateStart
+	for !eztok.IsEOF(ctx) {
+		tok, err := tizer.nodeTokenizers[state].TokenizeNext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if tok == nil || (tok.TokenType == TokenTypeComment && !tizer.KeepComments) {
+			continue
+		}
+		switch tok.TokenType {
+		case TokenTypeNewline:
+			state = lineStateStart
+		case TokenTypeKey:
+			state = lineStateAfterKey
+		case TokenTypeSection, TokenTypeValue:
+			state = lineStateEnd
+		}
+		toks = append(toks, tok)
+	}
+	if fallible, ok := ctx.(eztok.FallibleContext); ok && fallible.Err() != nil {
+		return nil, fallible.Err()
+	}
+	return toks, nil
+}
+
+// A node that matches a space, tab or carriage return (that is not part of a
+// line break) and skips it (i.e. generates no Token).
+var WhitespaceNode = eztok.NewCallbackNodeWithPattern(
+	`[\t\r ]`,
+	func(ctx eztok.Context) bool {
+		return isWhitespaceRune(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.NextRune(); !isWhitespaceRune(r) {
+			return nil, fmt.Errorf("expected a whitespace rune but got a '%c' rune", r)
+		}
+		return nil, nil
+	},
+)
+
+// A node that matches a line break ("\n" or "\r\n"). Returns a Token with a
+// TokenType of TokenTypeNewline and a Value of "\n".
+var NewlineNode = eztok.NewCallbackNodeWithPattern(
+	`\r?\n`,
+	func(ctx eztok.Context) bool {
+		return isAtLineEnd(ctx)
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if ctx.PeekRune(0) == '\r' {
+			ctx.NextRune()
+		}
+		if r := ctx.NextRune(); r != '\n' {
+			return nil, fmt.Errorf("expected a newline but got a '%c' rune", r)
+		}
+		return eztok.NewToken(TokenTypeNewline, "\n"), nil
+	},
+)
+
+// A node that matches a ';' or '#' comment, up to (but not including) the end
+// of its line. Returns a Token with a TokenType of TokenTypeComment and the
+// text of the comment (including its ';' or '#') as its Value.
+var CommentNode = eztok.NewCallbackNodeWithPattern(
+	`[;#]`,
+	func(ctx eztok.Context) bool {
+		return isCommentRune(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		text := eztok.ReadRunesUntil(ctx, func(r rune) bool {
+			return isAtLineEnd(ctx)
+		})
+		return eztok.NewToken(TokenTypeComment, text), nil
+	},
+)
+
+// A node that matches a section header: a name encased in '[' and ']'.
+// Returns a Token with a TokenType of TokenTypeSection and the name (without
+// surrounding whitespace) as its Value.
+var SectionNode = eztok.NewCallbackNodeWithPattern(
+	`\[`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '['
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.NextRune(); r != '[' {
+			return nil, fmt.Errorf("expected a '[' rune but got a '%c' rune", r)
+		}
+		name := eztok.ReadRunesUntil(ctx, func(r rune) bool {
+			return r == ']' || isAtLineEnd(ctx)
+		})
+		if ctx.PeekRune(0) != ']' {
+			return nil, fmt.Errorf("unterminated section header '[%v'", name)
+		}
+		ctx.NextRune()
+		return eztok.NewToken(TokenTypeSection, strings.TrimSpace(name)), nil
+	},
+)
+
+// Returns a new node that matches the key of a key-value pair, which continues
+// until one of tizer.Delimiters or the end of its line. Returns a Token with a
+// TokenType of TokenTypeKey and the key (without surrounding whitespace) as
+// its Value.
+func (tizer *Tokenizer) newKeyNode() *eztok.CallbackNode {
+	return eztok.NewCallbackNodeWithPattern(
+		`[^\t\n\r ;#\[]`,
+		func(ctx eztok.Context) bool {
+			r := ctx.PeekRune(0)
+			return !isWhitespaceRune(r) && r != '\n' && !isCommentRune(r) && r != '['
+		},
+		func(ctx eztok.Context) (*eztok.Token, error) {
+			key := strings.TrimSpace(eztok.ReadRunesUntil(ctx, func(r rune) bool {
+				return strings.ContainsRune(tizer.Delimiters, r) || isAtLineEnd(ctx)
+			}))
+			if len(key) <= 0 {
+				return nil, fmt.Errorf("missing key before '%c'", ctx.PeekRune(0))
+			}
+			return eztok.NewToken(TokenTypeKey, key), nil
+		},
+	)
+}
+
+// Returns a new node that matches one of tizer.Delimiters followed by a value,
+// which continues until the end of its line (or an inline comment if
+// tizer.InlineComments is true). Returns a Token with a TokenType of
+// TokenTypeValue and the value (without surrounding whitespace) as its Value,
+// whose Origin is that of the value rather than the delimiter.
+func (tizer *Tokenizer) newValueNode() *eztok.CallbackNode {
+	return eztok.NewCallbackNode(
+		func(ctx eztok.Context) bool {
+			return strings.ContainsRune(tizer.Delimiters, ctx.PeekRune(0))
+		},
+		func(ctx eztok.Context) (*eztok.Token, error) {
+			if r := ctx.NextRune(); !strings.ContainsRune(tizer.Delimiters, r) {
+				return nil, fmt.Errorf("expected one of '%v' but got a '%c' rune", tizer.Delimiters, r)
+			}
+			followsWhitespace := len(eztok.ReadRunesUntilNot(ctx, func(r rune) bool {
+				return isWhitespaceRune(r) && !isAtLineEnd(ctx)
+			})) > 0
+			origin := ctx.GetNextOrigin()
+			var builder strings.Builder
+			// An inline comment may also directly follow the whitespace after
+			// the delimiter, leaving the value empty.
+			atComment := tizer.InlineComments && followsWhitespace && isCommentRune(ctx.PeekRune(0))
+			for !atComment && !eztok.IsEOF(ctx) && !isAtLineEnd(ctx) {
+				r := ctx.NextRune()
+				builder.WriteRune(r)
+				if tizer.InlineComments && isWhitespaceRune(r) && isCommentRune(ctx.PeekRune(0)) {
+					break
+				}
+			}
+			tok := eztok.NewToken(TokenTypeValue, strings.TrimSpace(builder.String()))
+			tok.Origin = origin
+			return tok, nil
+		},
+	)
+}
+
+// Returns true if r is a space, tab or carriage return.
+func isWhitespaceRune(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r'
+}
+
+// Returns true if a line break ("\n" or "\r\n") begins at the current rune in
+// the Context.
+func isAtLineEnd(ctx eztok.Context) bool {
+	return ctx.PeekRune(0) == '\n' || (ctx.PeekRune(0) == '\r' && ctx.PeekRune(1) == '\n')
+}
+
+// Returns true if r begins a comment.
+func isCommentRune(r rune) bool {
+	return r == ';' || r == '#'
+}
@@ -0,0 +1,76 @@
+package ini
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+func TestTokenizer(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		inlineComments bool
+		// The Token objects returned, or "" if tokenizing fails.
+		want string
+	}{
+		{"sections and keys", "[ s ]\na = b\r\nk: v\nflag", false,
+			`section "s" at 1:1, newline "\n" at 1:6, key "a" at 2:1, value "b" at 2:5, newline "\n" at 2:6, ` +
+				`key "k" at 3:1, value "v" at 3:4, newline "\n" at 3:5, key "flag" at 4:1`},
+		{"comment lines", " # c\n[x] ; y", false,
+			`comment "# c" at 1:2, newline "\n" at 1:5, section "x" at 2:1, comment "; y" at 2:5`},
+		{"empty value", "a = \n", false, `key "a" at 1:1, value "" at 1:5, newline "\n" at 1:5`},
+		{"no inline comments", "a = b ; c", false, `key "a" at 1:1, value "b ; c" at 1:5`},
+		{"no inline comment after delimiter", "a = ;c", false, `key "a" at 1:1, value ";c" at 1:5`},
+		{"inline comment", "a = b ; c", true, `key "a" at 1:1, value "b" at 1:5, comment "; c" at 1:7`},
+		{"inline comment after delimiter", "a = ;c", true, `key "a" at 1:1, value "" at 1:5, comment ";c" at 1:5`},
+		{"';' directly after delimiter", "a =;c", true, `key "a" at 1:1, value ";c" at 1:4`},
+		{"';' within value", "a = b;c", true, `key "a" at 1:1, value "b;c" at 1:5`},
+		{"unterminated section", "[s", false, ""},
+		{"missing key", "=v", false, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tizer := NewTokenizer()
+			tizer.KeepComments = true
+			tizer.InlineComments = test.inlineComments
+			toks, err := eztok.TokenizeString(tizer, test.input)
+			if test.want == "" {
+				if err == nil {
+					t.Fatalf("tokenizing %q returned %v, want an error", test.input, iniTestString(toks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizing %q returned error: %v", test.input, err)
+			}
+			if got := iniTestString(toks); got != test.want {
+				t.Fatalf("tokenizing %q returned:\n%v\nwant:\n%v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizerDelimiters(t *testing.T) {
+	tizer := NewTokenizer()
+	tizer.Delimiters = "="
+	toks, err := eztok.TokenizeString(tizer, "a:b = c:d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := iniTestString(toks), `key "a:b" at 1:1, value "c:d" at 1:7`; got != want {
+		t.Fatalf("Tokenize returned %v, want %v", got, want)
+	}
+}
+
+// Returns a string of the TokenType, Value and Origin line and column of each
+// Token in toks, separated by commas.
+func iniTestString(toks []*eztok.Token) string {
+	strs := make([]string, len(toks))
+	for i, tok := range toks {
+		strs[i] = fmt.Sprintf("%v %q at %v:%v", tok.TokenType, tok.Value, tok.Origin.LineNum, tok.Origin.ColNum)
+	}
+	return strings.Join(strs, ", ")
+}
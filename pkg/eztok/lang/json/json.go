@@ -0,0 +1,311 @@
+package json
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// TokenType definitions returned by the JSON and JSON5 tokenizers. Strings
+// are returned as eztok.TokenTypeString, and numbers as eztok.TokenTypeInteger
+// or eztok.TokenTypeFloat.
+const (
+	TokenTypeLeftBrace    eztok.TokenType = "{"
+	TokenTypeRightBrace   eztok.TokenType = "}"
+	TokenTypeLeftBracket  eztok.TokenType = "["
+	TokenTypeRightBracket eztok.TokenType = "]"
+	TokenTypeColon        eztok.TokenType = ":"
+	TokenTypeComma        eztok.TokenType = ","
+	TokenTypeTrue         eztok.TokenType = "true"
+	TokenTypeFalse        eztok.TokenType = "false"
+	TokenTypeNull         eztok.TokenType = "null"
+)
+
+// Holds a map of escape rune (a rune following a '\' in a JSON string) to the
+// actual string contents of the escape. The 'u' escape is handled separately.
+var escapedRuneToString = map[rune]string{
+	'"':  "\"",
+	'\\': "\\",
+	'/':  "/",
+	'b':  "\b",
+	'f':  "\f",
+	'n':  "\n",
+	'r':  "\r",
+	't':  "\t",
+}
+
+// Returns a new InOrderNodeTokenizer that tokenizes JSON text, strictly
+// following RFC 8259. Each structural character returns a Token whose
+// TokenType is the character (such as TokenTypeLeftBrace), with a rune Value.
+// See StringNode, NumberNode and LiteralNode for the other Token objects
+// returned.
+func NewTokenizer() *eztok.InOrderNodeTokenizer {
+	return eztok.NewInOrderNodeTokenizer(append([]eztok.Node{
+		WhitespaceNode,
+		StringNode,
+		NumberNode,
+		LiteralNode,
+	}, newStructuralNodes()...)...)
+}
+
+// Returns a new Node for each JSON structural character.
+func newStructuralNodes() []eztok.Node {
+	return []eztok.Node{
+		eztok.NewRuneMatchNode(TokenTypeLeftBrace, '{'),
+		eztok.NewRuneMatchNode(TokenTypeRightBrace, '}'),
+		eztok.NewRuneMatchNode(TokenTypeLeftBracket, '['),
+		eztok.NewRuneMatchNode(TokenTypeRightBracket, ']'),
+		eztok.NewRuneMatchNode(TokenTypeColon, ':'),
+		eztok.NewRuneMatchNode(TokenTypeComma, ','),
+	}
+}
+
+// A node that matches a JSON whitespace rune (space, tab, line feed or
+// carriage return) and skips it (i.e. generates no Token).
+var WhitespaceNode = eztok.NewCallbackNodeWithPattern(
+	`[\t\n\r ]`,
+	func(ctx eztok.Context) bool {
+		return isWhitespaceRune(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.NextRune(); !isWhitespaceRune(r) {
+			return nil, fmt.Errorf("expected a whitespace rune but got a '%c' rune", r)
+		}
+		return nil, nil
+	},
+)
+
+// A node that matches a JSON string encased in double quotes ("). Control
+// runes must be escaped, and '\u' escapes of UTF-16 surrogate pairs are
+// combined into a single rune. A '\u' escape of a surrogate that is not part
+// of a pair is replaced by unicode.ReplacementChar. Returns a Token with a
+// TokenType of eztok.TokenTypeString and the unescaped string as its Value.
+var StringNode = eztok.NewCallbackNodeWithPattern(
+	`"`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '"'
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.NextRune(); r != '"' {
+			return nil, fmt.Errorf("expected a '\"' rune but got a '%c' rune", r)
+		}
+		var builder strings.Builder
+		for {
+			if eztok.IsEOF(ctx) {
+				return nil, fmt.Errorf("unterminated string '%v'", builder.String())
+			}
+			r := ctx.NextRune()
+			switch {
+			case r == '"':
+				return eztok.NewToken(eztok.TokenTypeString, builder.String()), nil
+			case r < 0x20:
+				return nil, fmt.Errorf("unescaped control rune %U in string '%v'", r, builder.String())
+			case r == '\\':
+				if eztok.IsEOF(ctx) {
+					return nil, fmt.Errorf("unterminated string '%v'", builder.String())
+				}
+				escape := ctx.NextRune()
+				if escape == 'u' {
+					unescaped, err := readUnicodeEscape(ctx)
+					if err != nil {
+						return nil, fmt.Errorf("%v while tokenizing string '%v'", err, builder.String())
+					}
+					builder.WriteRune(unescaped)
+				} else if replStr, ok := escapedRuneToString[escape]; ok {
+					builder.WriteString(replStr)
+				} else {
+					return nil, fmt.Errorf("unknown escape '%c' while tokenizing string '%v'", escape, builder.String())
+				}
+			default:
+				builder.WriteRune(r)
+			}
+		}
+	},
+)
+
+// A node that matches a JSON number: an optional '-', an integer part without
+// leading zeros, an optional fraction and an optional exponent. A number
+// without a fraction or exponent that fits in an int64 returns a Token with a
+// TokenType of eztok.TokenTypeInteger and an int64 Value. Any other number
+// returns a Token with a TokenType of eztok.TokenTypeFloat and the nearest
+// float64 as its Value (which is an infinity if out of range).
+var NumberNode = eztok.NewCallbackNodeWithPattern(
+	`-?[0-9]`,
+	func(ctx eztok.Context) bool {
+		r := ctx.PeekRune(0)
+		return isDigitRune(r) || (r == '-' && isDigitRune(ctx.PeekRune(1)))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		str := ""
+		if ctx.PeekRune(0) == '-' {
+			str += string(ctx.NextRune())
+		}
+		str, isInteger, err := readDecimalNumber(ctx, str, false)
+		if err != nil {
+			return nil, err
+		}
+		return newNumberToken(str, isInteger), nil
+	},
+)
+
+// A node that matches one of the JSON literal names 'true', 'false' and
+// 'null'. Returns a Token with a TokenType of TokenTypeTrue (with a Value of
+// true), TokenTypeFalse (with a Value of false) or TokenTypeNull (with a nil
+// Value). Any other word is an error.
+var LiteralNode = eztok.NewCallbackNodeWithPattern(
+	`[A-Za-z]`,
+	func(ctx eztok.Context) bool {
+		return isASCIILetterRune(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		word := eztok.ReadRunesUntilNot(ctx, func(r rune) bool {
+			return isASCIILetterRune(r) || isDigitRune(r) || r == '_'
+		})
+		if tok := newLiteralToken(word); tok != nil {
+			return tok, nil
+		}
+		return nil, fmt.Errorf("expected one of 'true', 'false' or 'null' but got '%v'", word)
+	},
+)
+
+// Returns the Token of the JSON literal name word, or nil if word is not one.
+func newLiteralToken(word string) *eztok.Token {
+	switch word {
+	case "true":
+		return eztok.NewToken(TokenTypeTrue, true)
+	case "false":
+		return eztok.NewToken(TokenTypeFalse, false)
+	case "null":
+		return eztok.NewToken(TokenTypeNull, nil)
+	}
+	return nil
+}
+
+// Consumes the remainder of a decimal number whose sign (if any) has already
+// been consumed into str, and returns str followed by the consumed runes.
+// Also returns true if the number has no fraction or exponent. If
+// allowBareDot is true, the integer part may be omitted before a fraction,
+// and the digits of a fraction may be omitted after an integer part.
+func readDecimalNumber(ctx eztok.Context, str string, allowBareDot bool) (string, bool, error) {
+	intPart := ""
+	if ctx.PeekRune(0) == '0' {
+		intPart = string(ctx.NextRune())
+		if isDigitRune(ctx.PeekRune(0)) {
+			return "", false, fmt.Errorf("invalid leading zero in number '%v%v'", str,
+				intPart+eztok.ReadRunesUntilNot(ctx, isDigitRune))
+		}
+	} else {
+		intPart = eztok.ReadRunesUntilNot(ctx, isDigitRune)
+	}
+	str += intPart
+	if len(intPart) <= 0 && (!allowBareDot || ctx.PeekRune(0) != '.') {
+		return "", false, fmt.Errorf("expected a digit after '%v'", str)
+	}
+
+	isInteger := true
+	if ctx.PeekRune(0) == '.' {
+		str += string(ctx.NextRune())
+		fraction := eztok.ReadRunesUntilNot(ctx, isDigitRune)
+		if len(fraction) <= 0 && (!allowBareDot || len(intPart) <= 0) {
+			return "", false, fmt.Errorf("expected a digit after '%v'", str)
+		}
+		str += fraction
+		isInteger = false
+	}
+	if r := ctx.PeekRune(0); r == 'e' || r == 'E' {
+		str += string(ctx.NextRune())
+		if r := ctx.PeekRune(0); r == '+' || r == '-' {
+			str += string(ctx.NextRune())
+		}
+		exponent := eztok.ReadRunesUntilNot(ctx, isDigitRune)
+		if len(exponent) <= 0 {
+			return "", false, fmt.Errorf("expected a digit after '%v'", str)
+		}
+		str += exponent
+		isInteger = false
+	}
+	return str, isInteger, nil
+}
+
+// Returns the Token of the decimal number str, which was read by
+// readDecimalNumber.
+func newNumberToken(str string, isInteger bool) *eztok.Token {
+	if isInteger {
+		if intVal, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return eztok.NewToken(eztok.TokenTypeInteger, intVal)
+		}
+	}
+	// The syntax has already been checked, so the only possible error is
+	// that the number is out of range, in which case floatVal is an infinity.
+	floatVal, _ := strconv.ParseFloat(str, 64)
+	return eztok.NewToken(eztok.TokenTypeFloat, floatVal)
+}
+
+// Consumes the 4 hexadecimal digits of a '\u' escape (whose '\u' has already
+// been consumed) and returns the escaped rune. If the escape is of a high
+// surrogate that is followed by a '\u' escape of a low surrogate, the second
+// escape is also consumed and the rune of the surrogate pair is returned.
+func readUnicodeEscape(ctx eztok.Context) (rune, error) {
+	r, ok := peekHexRunes(ctx, 0, 4)
+	if !ok {
+		return 0, fmt.Errorf("invalid escape '\\u%v'", eztok.PeekString(ctx, 4))
+	}
+	eztok.SkipRunes(ctx, 4)
+	if !utf16.IsSurrogate(r) {
+		return r, nil
+	}
+	if r < 0xDC00 && ctx.PeekRune(0) == '\\' && ctx.PeekRune(1) == 'u' {
+		if low, ok := peekHexRunes(ctx, 2, 4); ok && low >= 0xDC00 && low <= 0xDFFF {
+			eztok.SkipRunes(ctx, 6)
+			return utf16.DecodeRune(r, low), nil
+		}
+	}
+	return unicode.ReplacementChar, nil
+}
+
+// Returns the value of the count hexadecimal digits starting relative runes
+// ahead of the current rune in the Context, and true if they all are
+// hexadecimal digits. Does not consume any runes.
+func peekHexRunes(ctx eztok.Context, relative int, count int) (rune, bool) {
+	value := rune(0)
+	for i := 0; i < count; i++ {
+		digit, ok := hexDigitValue(ctx.PeekRune(relative + i))
+		if !ok {
+			return 0, false
+		}
+		value = value*16 + digit
+	}
+	return value, true
+}
+
+// Returns the value of the hexadecimal digit r, and true if r is one.
+func hexDigitValue(r rune) (rune, bool) {
+	switch {
+	case r >= '0' && r <= '9':
+		return r - '0', true
+	case r >= 'a' && r <= 'f':
+		return r - 'a' + 10, true
+	case r >= 'A' && r <= 'F':
+		return r - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// Returns true if r is a JSON whitespace rune.
+func isWhitespaceRune(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// Returns true if r is an ASCII decimal digit.
+func isDigitRune(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// Returns true if r is an ASCII letter.
+func isASCIILetterRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
@@ -0,0 +1,311 @@
+package json
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// Holds a map of escape rune (a rune following a '\' in a JSON5 string) to the
+// actual string contents of the escape. Any other rune that is not a decimal
+// digit, 'x', 'u' or a line terminator escapes to itself.
+var json5EscapedRuneToString = map[rune]string{
+	'b': "\b",
+	'f': "\f",
+	'n': "\n",
+	'r': "\r",
+	't': "\t",
+	'v': "\v",
+}
+
+// Returns a new InOrderNodeTokenizer that tokenizes JSON5 text, a superset of
+// JSON following the JSON5 specification. Returns the same Token objects as
+// NewTokenizer, with the following additions:
+//   - JSON5WhitespaceNode and JSON5CommentNode skip whitespace and comments.
+//   - JSON5StringNode allows single-quoted strings and more escapes.
+//   - JSON5NumberNode allows hexadecimal numbers, Infinity, NaN, a leading
+//     '+', and a leading or trailing decimal point.
+//   - JSON5IdentifierNode returns a Token for unquoted member names.
+func NewJSON5Tokenizer() *eztok.InOrderNodeTokenizer {
+	return eztok.NewInOrderNodeTokenizer(append([]eztok.Node{
+		JSON5WhitespaceNode,
+		JSON5CommentNode,
+		JSON5StringNode,
+		JSON5NumberNode,
+		JSON5IdentifierNode,
+	}, newStructuralNodes()...)...)
+}
+
+// A node that matches a JSON5 whitespace rune and skips it (i.e. generates no
+// Token). Besides the JSON whitespace runes, this includes vertical tab, form
+// feed, the Unicode line and paragraph separators, the byte order mark, and
+// any rune in the Unicode Zs category.
+var JSON5WhitespaceNode = eztok.NewCallbackNodeWithPattern(
+	`[\t\n\v\f\r \x{A0}\x{2028}\x{2029}\x{FEFF}\p{Zs}]`,
+	func(ctx eztok.Context) bool {
+		return isJSON5WhitespaceRune(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.NextRune(); !isJSON5WhitespaceRune(r) {
+			return nil, fmt.Errorf("expected a whitespace rune but got a '%c' rune", r)
+		}
+		return nil, nil
+	},
+)
+
+// A node that matches a '//' comment (up to the end of its line) or a '/* */'
+// comment, and skips it (i.e. generates no Token).
+var JSON5CommentNode = eztok.NewCallbackNodeWithPattern(
+	`/[/*]`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '/' && (ctx.PeekRune(1) == '/' || ctx.PeekRune(1) == '*')
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if ctx.NextRune() != '/' {
+			return nil, fmt.Errorf("expected a comment")
+		}
+		switch ctx.NextRune() {
+		case '/':
+			eztok.ReadRunesUntil(ctx, isJSON5LineTerminatorRune)
+		case '*':
+			for !eztok.HasPrefix(ctx, "*/") {
+				if eztok.IsEOF(ctx) {
+					return nil, fmt.Errorf("unterminated block comment")
+				}
+				ctx.NextRune()
+			}
+			eztok.SkipRunes(ctx, 2)
+		default:
+			return nil, fmt.Errorf("expected a comment")
+		}
+		return nil, nil
+	},
+)
+
+// A node that matches a JSON5 string encased in double (") or single (')
+// quotes. Besides the JSON escapes, allows '\v', '\0', '\xHH', a backslash
+// before a line terminator (which continues the string onto the next line),
+// and a backslash before any other rune that is not a decimal digit (which
+// escapes to that rune). A line feed or carriage return must be escaped.
+// Returns a Token with a TokenType of eztok.TokenTypeString and the
+// unescaped string as its Value.
+var JSON5StringNode = eztok.NewCallbackNodeWithPattern(
+	`["']`,
+	func(ctx eztok.Context) bool {
+		r := ctx.PeekRune(0)
+		return r == '"' || r == '\''
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		quoteRune := ctx.NextRune()
+		if quoteRune != '"' && quoteRune != '\'' {
+			return nil, fmt.Errorf("expected a quote rune but got a '%c' rune", quoteRune)
+		}
+		var builder strings.Builder
+		for {
+			if eztok.IsEOF(ctx) {
+				return nil, fmt.Errorf("unterminated string '%v'", builder.String())
+			}
+			r := ctx.NextRune()
+			switch {
+			case r == quoteRune:
+				return eztok.NewToken(eztok.TokenTypeString, builder.String()), nil
+			case r == '\n' || r == '\r':
+				return nil, fmt.Errorf("unescaped line break in string '%v'", builder.String())
+			case r == '\\':
+				if eztok.IsEOF(ctx) {
+					return nil, fmt.Errorf("unterminated string '%v'", builder.String())
+				}
+				if err := readJSON5Escape(ctx, &builder); err != nil {
+					return nil, fmt.Errorf("%v while tokenizing string '%v'", err, builder.String())
+				}
+			default:
+				builder.WriteRune(r)
+			}
+		}
+	},
+)
+
+// A node that matches a JSON5 number: an optional '+' or '-', followed by a
+// hexadecimal integer ('0x' or '0X' followed by hexadecimal digits) or a
+// decimal number, or a '+' or '-' followed by 'Infinity' or 'NaN' (without a
+// sign, these are matched by JSON5IdentifierNode). A decimal number may omit
+// the digits before or after its decimal point (but not both). Returns the
+// same Token objects as NumberNode; a hexadecimal integer that does not fit in
+// an int64 returns a eztok.TokenTypeFloat Token.
+var JSON5NumberNode = eztok.NewPatternNode(
+	`[+\-]?\.?[0-9]|[+\-](?:Infinity|NaN)`,
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		sign := ""
+		if r := ctx.PeekRune(0); r == '+' || r == '-' {
+			sign = string(ctx.NextRune())
+		}
+		if eztok.HasPrefix(ctx, "Infinity") {
+			eztok.SkipRunes(ctx, len("Infinity"))
+			if sign == "-" {
+				return eztok.NewToken(eztok.TokenTypeFloat, math.Inf(-1)), nil
+			}
+			return eztok.NewToken(eztok.TokenTypeFloat, math.Inf(1)), nil
+		}
+		if eztok.HasPrefix(ctx, "NaN") {
+			eztok.SkipRunes(ctx, len("NaN"))
+			return eztok.NewToken(eztok.TokenTypeFloat, math.NaN()), nil
+		}
+		if ctx.PeekRune(0) == '0' && (ctx.PeekRune(1) == 'x' || ctx.PeekRune(1) == 'X') {
+			header := sign + eztok.PeekString(ctx, 2)
+			eztok.SkipRunes(ctx, 2)
+			digits := eztok.ReadRunesUntilNot(ctx, func(r rune) bool {
+				_, ok := hexDigitValue(r)
+				return ok
+			})
+			if len(digits) <= 0 {
+				return nil, fmt.Errorf("expected a hexadecimal digit after '%v'", header)
+			}
+			return newHexNumberToken(sign, digits), nil
+		}
+		str, isInteger, err := readDecimalNumber(ctx, sign, true)
+		if err != nil {
+			return nil, err
+		}
+		return newNumberToken(str, isInteger), nil
+	},
+)
+
+// A node that matches an unquoted JSON5 member name, which is an ECMAScript
+// 5.1 IdentifierName (possibly containing '\uHHHH' escapes). Returns a Token
+// with a TokenType of eztok.TokenTypeIdentifier and the unescaped name as its
+// Value. The names 'true', 'false' and 'null' return the same Token objects
+// as LiteralNode, and the names 'Infinity' and 'NaN' return a
+// eztok.TokenTypeFloat Token; a parser must also accept these Token objects
+// as member names.
+var JSON5IdentifierNode = eztok.NewCallbackNodeWithPattern(
+	`[\p{Lu}\p{Ll}\p{Lt}\p{Lm}\p{Lo}\p{Nl}$_\\]`,
+	func(ctx eztok.Context) bool {
+		r := ctx.PeekRune(0)
+		return isJSON5IdentifierStartRune(r) || r == '\\'
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		var builder strings.Builder
+		for !eztok.IsEOF(ctx) {
+			r := ctx.PeekRune(0)
+			if r == '\\' {
+				if ctx.PeekRune(1) != 'u' {
+					return nil, fmt.Errorf("invalid escape '\\%c' in identifier '%v'", ctx.PeekRune(1), builder.String())
+				}
+				escaped, ok := peekHexRunes(ctx, 2, 4)
+				if !ok {
+					return nil, fmt.Errorf("invalid escape '%v' in identifier '%v'", eztok.PeekString(ctx, 6), builder.String())
+				}
+				r = escaped
+			}
+			if !isJSON5IdentifierStartRune(r) && (builder.Len() <= 0 || !isJSON5IdentifierPartRune(r)) {
+				if ctx.PeekRune(0) == '\\' {
+					return nil, fmt.Errorf("invalid rune %U escaped in identifier '%v'", r, builder.String())
+				}
+				break
+			}
+			if ctx.PeekRune(0) == '\\' {
+				eztok.SkipRunes(ctx, 6)
+			} else {
+				ctx.NextRune()
+			}
+			builder.WriteRune(r)
+		}
+
+		name := builder.String()
+		if tok := newLiteralToken(name); tok != nil {
+			return tok, nil
+		}
+		switch name {
+		case "Infinity":
+			return eztok.NewToken(eztok.TokenTypeFloat, math.Inf(1)), nil
+		case "NaN":
+			return eztok.NewToken(eztok.TokenTypeFloat, math.NaN()), nil
+		}
+		return eztok.NewToken(eztok.TokenTypeIdentifier, name), nil
+	},
+)
+
+// Consumes the escape following a '\' (which has already been consumed) in a
+// JSON5 string, and writes its contents to builder.
+func readJSON5Escape(ctx eztok.Context, builder *strings.Builder) error {
+	escape := ctx.NextRune()
+	switch {
+	case escape == '\r':
+		// A line continuation, possibly of a CRLF line break.
+		if ctx.PeekRune(0) == '\n' {
+			ctx.NextRune()
+		}
+	case isJSON5LineTerminatorRune(escape):
+		// A line continuation.
+	case escape == '0' && !isDigitRune(ctx.PeekRune(0)):
+		builder.WriteRune(0)
+	case isDigitRune(escape):
+		return fmt.Errorf("unknown escape '%c'", escape)
+	case escape == 'x':
+		r, ok := peekHexRunes(ctx, 0, 2)
+		if !ok {
+			return fmt.Errorf("invalid escape '\\x%v'", eztok.PeekString(ctx, 2))
+		}
+		eztok.SkipRunes(ctx, 2)
+		builder.WriteRune(r)
+	case escape == 'u':
+		r, err := readUnicodeEscape(ctx)
+		if err != nil {
+			return err
+		}
+		builder.WriteRune(r)
+	default:
+		if replStr, ok := json5EscapedRuneToString[escape]; ok {
+			builder.WriteString(replStr)
+		} else {
+			builder.WriteRune(escape)
+		}
+	}
+	return nil
+}
+
+// Returns the Token of the hexadecimal integer with the provided sign ("",
+// "+" or "-") and hexadecimal digits.
+func newHexNumberToken(sign string, digits string) *eztok.Token {
+	if intVal, err := strconv.ParseInt(sign+digits, 16, 64); err == nil {
+		return eztok.NewToken(eztok.TokenTypeInteger, intVal)
+	}
+	floatVal := 0.0
+	for _, r := range digits {
+		digit, _ := hexDigitValue(r)
+		floatVal = floatVal*16 + float64(digit)
+	}
+	if sign == "-" {
+		floatVal = -floatVal
+	}
+	return eztok.NewToken(eztok.TokenTypeFloat, floatVal)
+}
+
+// Returns true if r is a JSON5 whitespace rune.
+func isJSON5WhitespaceRune(r rune) bool {
+	switch r {
+	case '\t', '\n', '\v', '\f', '\r', ' ', '\u00A0', '\u2028', '\u2029', '\uFEFF':
+		return true
+	}
+	return unicode.Is(unicode.Zs, r)
+}
+
+// Returns true if r is a JSON5 line terminator rune.
+func isJSON5LineTerminatorRune(r rune) bool {
+	return r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029'
+}
+
+// Returns true if r can begin an ECMAScript 5.1 IdentifierName.
+func isJSON5IdentifierStartRune(r rune) bool {
+	return r == '$' || r == '_' || unicode.In(r, unicode.Lu, unicode.Ll, unicode.Lt, unicode.Lm, unicode.Lo, unicode.Nl)
+}
+
+// Returns true if r can continue an ECMAScript 5.1 IdentifierName.
+func isJSON5IdentifierPartRune(r rune) bool {
+	return isJSON5IdentifierStartRune(r) || r == '\u200C' || r == '\u200D' ||
+		unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
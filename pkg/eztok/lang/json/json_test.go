@@ -0,0 +1,131 @@
+package json
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// Hand-written cases, named in the style of JSONTestSuite (which is not
+// vendored, and is not run). A y_ case must tokenize, and an n_ case must fail
+// if the text cannot be tokenized as JSON (cases that are only invalid to a
+// parser, such as "[1,]", are left out). An i_ case may do either in RFC 8259,
+// so its result is pinned.
+var jsonTestCases = []struct {
+	name  string
+	input string
+	// The Token objects returned, or "" if tokenizing fails.
+	want string
+	// The Token objects returned by the JSON5 tokenizer, if they differ.
+	want5 string
+}{
+	{"y_array_empty", `[]`, "[ ]", ""},
+	{"y_object_empty", `{}`, "{ }", ""},
+	{"y_object_literals", `{"a":true,"b":false,"c":null}`,
+		"{ string:a : true:true , string:b : false:false , string:c : null:<nil> }", ""},
+	{"y_structure_whitespace", " [\t\n\r1 ] ", "[ integer:1 ]", ""},
+	{"y_number_minus_zero", `[-0]`, "[ integer:0 ]", ""},
+	{"y_number_min_int64", `[-9223372036854775808]`, "[ integer:-9223372036854775808 ]", ""},
+	{"y_number_capital_e", `[1E22]`, "[ float:1e+22 ]", ""},
+	{"y_number_neg_exp", `[1e-2]`, "[ float:0.01 ]", ""},
+	{"y_number_real_fraction_exponent", `[-123.456e+78]`, "[ float:-1.23456e+80 ]", ""},
+	{"y_number_0e1", `[0e1]`, "[ float:0 ]", ""},
+	{"y_string_unicode_escapes", `["\u012a\u12ab"]`, "[ string:\u012a\u12ab ]", ""},
+	{"y_string_surrogate_pair", `["\uD801\udc37"]`, "[ string:\U00010437 ]", ""},
+	{"y_string_escapes", `["\"\\\/\b\f\n\r\t"]`, "[ string:\"\\/\b\f\n\r\t ]", ""},
+	{"y_string_utf8", "[\"\u20ac\U0001D11E\"]", "[ string:\u20ac\U0001D11E ]", ""},
+	{"y_string_escaped_null", `["\u0000"]`, "[ string:\x00 ]", ""},
+
+	{"n_number_leading_zero", `[01]`, "", ""},
+	{"n_number_minus_only", `[-]`, "", ""},
+	{"n_number_starting_with_dot", `[.1]`, "", "[ float:0.1 ]"},
+	{"n_number_ending_with_dot", `[1.]`, "", "[ float:1 ]"},
+	{"n_number_no_exponent_digits", `[1e]`, "", ""},
+	{"n_number_no_signed_exponent_digits", `[1e+]`, "", ""},
+	{"n_number_plus", `[+1]`, "", "[ integer:1 ]"},
+	{"n_number_hex", `[0x1]`, "", "[ integer:1 ]"},
+	{"n_number_infinity", `[Infinity]`, "", "[ float:+Inf ]"},
+	{"n_number_NaN", `[NaN]`, "", "[ float:NaN ]"},
+	{"n_incomplete_true", `[tru]`, "", "[ identifier:tru ]"},
+	{"n_capitalized_True", `[True]`, "", "[ identifier:True ]"},
+	{"n_string_single_quote", `['a']`, "", "[ string:a ]"},
+	{"n_string_unescaped_tab", "[\"a\t\"]", "", "[ string:a\t ]"},
+	{"n_string_invalid_escape", `["\x"]`, "", ""},
+	{"n_string_invalid_unicode_escape", `["\u00G0"]`, "", ""},
+	{"n_string_no_quote_end", `["abc`, "", ""},
+	{"n_string_backslash_at_end", `["\`, "", ""},
+	{"n_structure_trailing_word", `[1]x`, "", "[ integer:1 ] identifier:x"},
+	{"n_structure_comment", "// c\n1", "", "integer:1"},
+	{"n_structure_no_break_space", "\u00A0[]", "", "[ ]"},
+	{"n_structure_unquoted_word", `[a]`, "", "[ identifier:a ]"},
+
+	{"i_number_huge_exp", `[1e400]`, "[ float:+Inf ]", ""},
+	{"i_number_neg_huge_exp", `[-1e400]`, "[ float:-Inf ]", ""},
+	{"i_number_too_big_int", `[9223372036854775808]`, "[ float:9.223372036854776e+18 ]", ""},
+	{"i_number_very_precise", `[1.000000000000000000000000000000000000001]`, "[ float:1 ]", ""},
+	{"i_string_lone_high_surrogate", `["\uD800"]`, "[ string:\uFFFD ]", ""},
+	{"i_string_inverted_surrogates", `["\uDC00\uD800"]`, "[ string:\uFFFD\uFFFD ]", ""},
+	{"i_string_high_surrogate_then_rune", "[\"\\uD800\U00010000\"]", "[ string:\uFFFD\U00010000 ]", ""},
+	{"i_string_invalid_utf8", "[\"\xff\"]", "[ string:\uFFFD ]", ""},
+	{"i_structure_UTF-8_BOM", "\uFEFF[]", "", "[ ]"},
+}
+
+func TestTokenizer(t *testing.T) {
+	for _, test := range jsonTestCases {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := eztok.TokenizeString(NewTokenizer(), test.input)
+			if test.want == "" {
+				if err == nil {
+					t.Fatalf("tokenizing %q returned %v, want an error", test.input, jsonTestString(toks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizing %q returned error: %v", test.input, err)
+			}
+			if got := jsonTestString(toks); got != test.want {
+				t.Fatalf("tokenizing %q returned:\n%v\nwant:\n%v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestJSON5Tokenizer(t *testing.T) {
+	for _, test := range jsonTestCases {
+		t.Run(test.name, func(t *testing.T) {
+			want := test.want5
+			if want == "" {
+				want = test.want
+			}
+			toks, err := eztok.TokenizeString(NewJSON5Tokenizer(), test.input)
+			if want == "" {
+				if err == nil {
+					t.Fatalf("tokenizing %q returned %v, want an error", test.input, jsonTestString(toks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizing %q returned error: %v", test.input, err)
+			}
+			if got := jsonTestString(toks); got != want {
+				t.Fatalf("tokenizing %q returned:\n%v\nwant:\n%v", test.input, got, want)
+			}
+		})
+	}
+}
+
+// Returns a string of each Token in toks, separated by spaces. A structural
+// Token is written as its TokenType, and any other as its TokenType and Value.
+func jsonTestString(toks []*eztok.Token) string {
+	strs := make([]string, len(toks))
+	for i, tok := range toks {
+		if _, ok := tok.Value.(rune); ok {
+			strs[i] = string(tok.TokenType)
+		} else {
+			strs[i] = fmt.Sprintf("%v:%v", tok.TokenType, tok.Value)
+		}
+	}
+	return strings.Join(strs, " ")
+}
@@ -0,0 +1,146 @@
+package sql
+
+import "strings"
+
+// Represents the lexical rules of a dialect of SQL. The rules common to all
+// dialects (doubling a quote to escape it within a string, '--' and '/* */'
+// comments, and so on) are always applied.
+type Dialect struct {
+	// The name of the dialect.
+	Name string
+	// A map of each rune that begins a quoted identifier to the rune that ends
+	// it, such as '"' to '"' or '[' to ']'. The ending rune is escaped within
+	// a quoted identifier by doubling it.
+	IdentifierQuotes map[rune]rune
+	// If true, a string may also be encased in double quotes ("), unless '"'
+	// begins a quoted identifier.
+	DoubleQuotedStrings bool
+	// If true, a '\' within a string escapes the following rune.
+	BackslashEscapes bool
+	// If true, a string prefixed by 'E' (such as E'a\tb') is an escape string,
+	// in which a '\' escapes the following rune.
+	EscapeStrings bool
+	// If true, strings may be dollar-quoted, such as $$text$$ or
+	// $tag$text$tag$.
+	DollarQuotedStrings bool
+	// If true, block comments may be nested within each other.
+	NestedComments bool
+	// If true, a '#' begins a comment that ends at the end of its line.
+	HashComments bool
+	// If true, a '?' is a positional parameter (which may be followed by its
+	// number, such as ?1).
+	QuestionParameters bool
+	// If true, a '$' followed by a number (such as $1) is a positional
+	// parameter.
+	DollarParameters bool
+	// The runes that begin a named parameter when followed by a name, such
+	// as ":" for :name or "@" for @name.
+	NamedParameterPrefixes string
+	// The keywords of the dialect, in upper case. Keywords are recognised
+	// regardless of case.
+	Keywords map[string]bool
+}
+
+// The keywords common to most dialects of SQL.
+var standardKeywords = newKeywordSet(
+	"ADD", "ALL", "ALTER", "AND", "ANY", "AS", "ASC", "BETWEEN", "BY", "CASE",
+	"CAST", "CHECK", "COLUMN", "CONSTRAINT", "CREATE", "CROSS", "CURRENT_DATE",
+	"CURRENT_TIME", "CURRENT_TIMESTAMP", "DEFAULT", "DELETE", "DESC",
+	"DISTINCT", "DROP", "ELSE", "END", "ESCAPE", "EXCEPT", "EXISTS", "FALSE",
+	"FETCH", "FOR", "FOREIGN", "FROM", "FULL", "GRANT", "GROUP", "HAVING",
+	"IN", "INDEX", "INNER", "INSERT", "INTERSECT", "INTO", "IS", "JOIN", "KEY",
+	"LEFT", "LIKE", "LIMIT", "NATURAL", "NOT", "NULL", "OFFSET", "ON", "OR",
+	"ORDER", "OUTER", "PRIMARY", "REFERENCES", "RETURNING", "REVOKE", "RIGHT",
+	"SELECT", "SET", "SOME", "TABLE", "THEN", "TO", "TRUE", "UNION", "UNIQUE",
+	"UPDATE", "USING", "VALUES", "VIEW", "WHEN", "WHERE", "WITH",
+)
+
+// The lexical rules shared by most dialects of SQL: double-quoted
+// identifiers, '?' positional parameters, and :name named parameters (the host
+// variables of embedded SQL). A "::" is an operator, not a named parameter.
+var DialectANSI = &Dialect{
+	Name:                   "ANSI",
+	IdentifierQuotes:       map[rune]rune{'"': '"'},
+	QuestionParameters:     true,
+	NamedParameterPrefixes: ":",
+	Keywords:               standardKeywords,
+}
+
+// The lexical rules of PostgreSQL: double-quoted identifiers, E'...' escape
+// strings, dollar-quoted strings, nested block comments, and $1 positional
+// parameters. PostgreSQL itself has no named parameters, so :name is a ':'
+// followed by an identifier, as within the array slice a[1:n]. (The :name
+// variables of psql are replaced by psql before the server sees them.)
+var DialectPostgreSQL = &Dialect{
+	Name:                "PostgreSQL",
+	IdentifierQuotes:    map[rune]rune{'"': '"'},
+	EscapeStrings:       true,
+	DollarQuotedStrings: true,
+	NestedComments:      true,
+	DollarParameters:    true,
+	Keywords: extendKeywordSet(standardKeywords,
+		"ANALYSE", "ANALYZE", "ARRAY", "ILIKE", "LATERAL", "ONLY", "SIMILAR",
+		"VARIADIC", "WINDOW"),
+}
+
+// The lexical rules of MySQL: backquoted identifiers, double-quoted strings,
+// backslash escapes within strings, '#' comments, and '?' positional and
+// :name named parameters.
+var DialectMySQL = &Dialect{
+	Name:                   "MySQL",
+	IdentifierQuotes:       map[rune]rune{'`': '`'},
+	DoubleQuotedStrings:    true,
+	BackslashEscapes:       true,
+	HashComments:           true,
+	QuestionParameters:     true,
+	NamedParameterPrefixes: ":",
+	Keywords: extendKeywordSet(standardKeywords,
+		"DIV", "DUPLICATE", "IGNORE", "MOD", "REGEXP", "REPLACE", "RLIKE",
+		"SHOW", "STRAIGHT_JOIN", "XOR"),
+}
+
+// The lexical rules of SQLite: double-quoted, backquoted and bracketed
+// identifiers, and '?', ?1, :name, @name and $name parameters.
+var DialectSQLite = &Dialect{
+	Name:                   "SQLite",
+	IdentifierQuotes:       map[rune]rune{'"': '"', '`': '`', '[': ']'},
+	QuestionParameters:     true,
+	NamedParameterPrefixes: ":@$",
+	Keywords: extendKeywordSet(standardKeywords,
+		"ABORT", "AUTOINCREMENT", "GLOB", "PRAGMA", "REGEXP", "REPLACE",
+		"VACUUM"),
+}
+
+// The lexical rules of Microsoft SQL Server: double-quoted and bracketed
+// identifiers, and @name named parameters.
+var DialectSQLServer = &Dialect{
+	Name:                   "SQLServer",
+	IdentifierQuotes:       map[rune]rune{'"': '"', '[': ']'},
+	NamedParameterPrefixes: "@",
+	Keywords: extendKeywordSet(standardKeywords,
+		"DECLARE", "EXEC", "EXECUTE", "MERGE", "OUTPUT", "PROC", "PROCEDURE",
+		"TOP"),
+}
+
+// Returns true if name is a keyword of the Dialect, regardless of case.
+func (dialect *Dialect) IsKeyword(name string) bool {
+	return dialect.Keywords[strings.ToUpper(name)]
+}
+
+// Returns a new set of the keywords of base and the provided keywords.
+func extendKeywordSet(base map[string]bool, keywords ...string) map[string]bool {
+	set := newKeywordSet(keywords...)
+	for keyword := range base {
+		set[keyword] = true
+	}
+	return set
+}
+
+// Returns a set of the provided keywords.
+func newKeywordSet(keywords ...string) map[string]bool {
+	set := make(map[string]bool, len(keywords))
+	for _, keyword := range keywords {
+		set[keyword] = true
+	}
+	return set
+}
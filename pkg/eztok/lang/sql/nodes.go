@@ -0,0 +1,508 @@
+package sql
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// Represents a positional or named parameter.
+type Parameter struct {
+	// The rune the parameter begins with, such as '?', '$', ':' or '@'.
+	Prefix rune
+	// The name of a named parameter, without its Prefix.
+	Name string
+	// The number of a numbered positional parameter (such as 1 for $1 or ?1),
+	// or 0 if it has none.
+	Number int
+}
+
+// Holds a map of escape rune (a rune following a '\' in a string of a Dialect
+// with BackslashEscapes) to the string it represents. Any other escaped rune
+// represents itself. Escaped '%' and '_' runes keep their '\', so that they
+// still escape the wildcards of a LIKE pattern.
+var backslashEscapes = map[rune]string{
+	'0': "\x00",
+	'b': "\b",
+	'n': "\n",
+	'r': "\r",
+	't': "\t",
+	'Z': "\x1a",
+	'%': `\%`,
+	'_': `\_`,
+}
+
+// Holds a map of escape rune (a rune following a '\' in an escape string) to
+// the string it represents. Octal, '\x', '\u' and '\U' escapes are handled
+// separately, and any other escaped rune represents itself.
+var escapeStringEscapes = map[rune]string{
+	'b': "\b",
+	'f': "\f",
+	'n': "\n",
+	'r': "\r",
+	't': "\t",
+}
+
+// A node that matches a whitespace rune and skips it (i.e. generates no
+// Token).
+var WhitespaceNode = eztok.NewCallbackNodeWithPattern(
+	`[\t\n\v\f\r \x{85}\x{A0}\p{Z}]`,
+	func(ctx eztok.Context) bool {
+		return unicode.IsSpace(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.NextRune(); !unicode.IsSpace(r) {
+			return nil, fmt.Errorf("expected a whitespace rune but got a '%c' rune", r)
+		}
+		return nil, nil
+	},
+)
+
+// Returns a new node that matches a '--' comment (up to, but not including,
+// the end of its line) or a '/* */' comment, and a '#' comment if
+// dialect.HashComments is true. Returns a Token with a TokenType of
+// TokenTypeComment and the text of the comment (including its delimiters) as
+// its Value.
+func NewCommentNode(dialect *Dialect) *eztok.CallbackNode {
+	pattern := `--|/\*`
+	if dialect.HashComments {
+		pattern += `|#`
+	}
+	isLineComment := func(ctx eztok.Context) bool {
+		return eztok.HasPrefix(ctx, "--") || (dialect.HashComments && ctx.PeekRune(0) == '#')
+	}
+	return eztok.NewCallbackNodeWithPattern(
+		pattern,
+		func(ctx eztok.Context) bool {
+			return isLineComment(ctx) || eztok.HasPrefix(ctx, "/*")
+		},
+		func(ctx eztok.Context) (*eztok.Token, error) {
+			if isLineComment(ctx) {
+				text := eztok.ReadRunesUntil(ctx, func(r rune) bool { return r == '\n' })
+				return eztok.NewToken(TokenTypeComment, text), nil
+			}
+			if !eztok.HasPrefix(ctx, "/*") {
+				return nil, fmt.Errorf("expected a comment")
+			}
+			eztok.SkipRunes(ctx, 2)
+			var builder strings.Builder
+			builder.WriteString("/*")
+			for depth := 1; depth > 0; {
+				switch {
+				case eztok.IsEOF(ctx):
+					return nil, fmt.Errorf("unterminated comment")
+				case eztok.HasPrefix(ctx, "*/"):
+					eztok.SkipRunes(ctx, 2)
+					builder.WriteString("*/")
+					depth--
+				case dialect.NestedComments && eztok.HasPrefix(ctx, "/*"):
+					eztok.SkipRunes(ctx, 2)
+					builder.WriteString("/*")
+					depth++
+				default:
+					builder.WriteRune(ctx.NextRune())
+				}
+			}
+			return eztok.NewToken(TokenTypeComment, builder.String()), nil
+		},
+	)
+}
+
+// Returns a new node that matches a string encased in single quotes ('), and
+// in double quotes (") if dialect.DoubleQuotedStrings is true and '"' does not
+// begin a quoted identifier. A quote within the string is escaped by doubling
+// it. A single-quoted string may be prefixed by 'N' (a national character
+// string), or by 'E' (an escape string) if dialect.EscapeStrings is true.
+// Within an escape string, or any string if dialect.BackslashEscapes is true,
+// a '\' escapes the following rune. Returns a Token with a TokenType of
+// eztok.TokenTypeString and the unescaped string as its Value.
+func NewStringNode(dialect *Dialect) *eztok.CallbackNode {
+	pattern := `[Nn]?'`
+	if dialect.EscapeStrings {
+		pattern = `[EeNn]?'`
+	}
+	if allowsDoubleQuotedStrings(dialect) {
+		pattern += `|"`
+	}
+	return eztok.NewCallbackNodeWithPattern(
+		pattern,
+		func(ctx eztok.Context) bool {
+			return stringPrefixLength(dialect, ctx) >= 0
+		},
+		func(ctx eztok.Context) (*eztok.Token, error) {
+			prefixLength := stringPrefixLength(dialect, ctx)
+			if prefixLength < 0 {
+				return nil, fmt.Errorf("expected a string")
+			}
+			isEscapeString := false
+			if prefixLength > 0 {
+				prefix := ctx.NextRune()
+				isEscapeString = prefix == 'E' || prefix == 'e'
+			}
+			quote := ctx.NextRune()
+			var builder strings.Builder
+			for {
+				if eztok.IsEOF(ctx) {
+					return nil, fmt.Errorf("unterminated string '%v'", builder.String())
+				}
+				r := ctx.NextRune()
+				switch {
+				case r == quote && ctx.PeekRune(0) == quote:
+					builder.WriteRune(ctx.NextRune())
+				case r == quote:
+					return eztok.NewToken(eztok.TokenTypeString, builder.String()), nil
+				case r == '\\' && (isEscapeString || dialect.BackslashEscapes):
+					if eztok.IsEOF(ctx) {
+						return nil, fmt.Errorf("unterminated string '%v'", builder.String())
+					}
+					var err error
+					if isEscapeString {
+						err = readEscapeStringEscape(ctx, &builder)
+					} else {
+						readBackslashEscape(ctx, &builder)
+					}
+					if err != nil {
+						return nil, fmt.Errorf("%v while tokenizing string '%v'", err, builder.String())
+					}
+				default:
+					builder.WriteRune(r)
+				}
+			}
+		},
+	)
+}
+
+// Returns a new node that matches an identifier encased in one of the quotes
+// of dialect.IdentifierQuotes. The ending quote is escaped within the
+// identifier by doubling it. Returns a Token with a TokenType of
+// TokenTypeQuotedIdentifier and the unescaped identifier as its Value.
+func NewQuotedIdentifierNode(dialect *Dialect) *eztok.CallbackNode {
+	quotes := []string{}
+	for quote := range dialect.IdentifierQuotes {
+		quotes = append(quotes, regexp.QuoteMeta(string(quote)))
+	}
+	sort.Strings(quotes)
+	return eztok.NewCallbackNodeWithPattern(
+		strings.Join(quotes, "|"),
+		func(ctx eztok.Context) bool {
+			_, ok := dialect.IdentifierQuotes[ctx.PeekRune(0)]
+			return ok
+		},
+		func(ctx eztok.Context) (*eztok.Token, error) {
+			open := ctx.NextRune()
+			closeQuote, ok := dialect.IdentifierQuotes[open]
+			if !ok {
+				return nil, fmt.Errorf("expected a quoted identifier but got a '%c' rune", open)
+			}
+			var builder strings.Builder
+			for {
+				if eztok.IsEOF(ctx) {
+					return nil, fmt.Errorf("unterminated quoted identifier '%v'", builder.String())
+				}
+				r := ctx.NextRune()
+				if r == closeQuote {
+					if ctx.PeekRune(0) != closeQuote {
+						break
+					}
+					ctx.NextRune()
+				}
+				builder.WriteRune(r)
+			}
+			if builder.Len() <= 0 {
+				return nil, fmt.Errorf("zero-length quoted identifier")
+			}
+			return eztok.NewToken(TokenTypeQuotedIdentifier, builder.String()), nil
+		},
+	)
+}
+
+// A node that matches a dollar-quoted string, which begins with a '$', an
+// optional tag and a '$' (such as $$ or $body$), and ends with the same
+// delimiter. No escaping is done within a dollar-quoted string. Returns a Token
+// with a TokenType of eztok.TokenTypeString and the contents of the string
+// (without its delimiters) as its Value.
+var DollarQuotedStringNode = eztok.NewCallbackNodeWithPattern(
+	`\$(?:[\p{L}_][\p{L}\p{Nd}_]*)?\$`,
+	func(ctx eztok.Context) bool {
+		return dollarQuoteLength(ctx) > 0
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		length := dollarQuoteLength(ctx)
+		if length <= 0 {
+			return nil, fmt.Errorf("expected a dollar-quoted string")
+		}
+		delimiter := eztok.PeekString(ctx, length)
+		eztok.SkipRunes(ctx, length)
+		var builder strings.Builder
+		for !eztok.HasPrefix(ctx, delimiter) {
+			if eztok.IsEOF(ctx) {
+				return nil, fmt.Errorf("unterminated dollar-quoted string %v", delimiter)
+			}
+			builder.WriteRune(ctx.NextRune())
+		}
+		eztok.SkipRunes(ctx, length)
+		return eztok.NewToken(eztok.TokenTypeString, builder.String()), nil
+	},
+)
+
+// Returns a new node that matches the parameters of dialect: a '?' (which may
+// be followed by its number) if dialect.QuestionParameters is true, a '$'
+// followed by its number if dialect.DollarParameters is true, and a name
+// prefixed by one of dialect.NamedParameterPrefixes. Returns a Token with a
+// TokenType of TokenTypePositionalParameter or TokenTypeNamedParameter, and a
+// *Parameter Value.
+func NewParameterNode(dialect *Dialect) *eztok.CallbackNode {
+	patterns := []string{}
+	if dialect.QuestionParameters {
+		patterns = append(patterns, `\?`)
+	}
+	if dialect.DollarParameters {
+		patterns = append(patterns, `\$[0-9]`)
+	}
+	for _, prefix := range dialect.NamedParameterPrefixes {
+		patterns = append(patterns, regexp.QuoteMeta(string(prefix))+`[\p{L}_]`)
+	}
+	isPositional := func(ctx eztok.Context) bool {
+		r := ctx.PeekRune(0)
+		return (r == '?' && dialect.QuestionParameters) ||
+			(r == '$' && dialect.DollarParameters && isDigitRune(ctx.PeekRune(1)))
+	}
+	isNamed := func(ctx eztok.Context) bool {
+		return strings.ContainsRune(dialect.NamedParameterPrefixes, ctx.PeekRune(0)) &&
+			isIdentifierStartRune(ctx.PeekRune(1))
+	}
+	return eztok.NewCallbackNodeWithPattern(
+		strings.Join(patterns, "|"),
+		func(ctx eztok.Context) bool {
+			return isPositional(ctx) || isNamed(ctx)
+		},
+		func(ctx eztok.Context) (*eztok.Token, error) {
+			switch {
+			case isPositional(ctx):
+				param := &Parameter{Prefix: ctx.NextRune()}
+				if digits := eztok.ReadRunesUntilNot(ctx, isDigitRune); len(digits) > 0 {
+					number, err := strconv.Atoi(digits)
+					if err != nil {
+						return nil, fmt.Errorf("parameter number '%v' is out of range", digits)
+					}
+					param.Number = number
+				}
+				return eztok.NewToken(TokenTypePositionalParameter, param), nil
+			case isNamed(ctx):
+				param := &Parameter{Prefix: ctx.NextRune()}
+				param.Name = eztok.ReadRunesUntilNot(ctx, isIdentifierRune)
+				return eztok.NewToken(TokenTypeNamedParameter, param), nil
+			}
+			return nil, fmt.Errorf("expected a parameter")
+		},
+	)
+}
+
+// Returns a new node that matches an identifier: a letter or '_', followed by
+// any letters, digits, '_' or '$'. Returns a Token with a TokenType of
+// TokenTypeKeyword and the keyword in upper case as its Value if the
+// identifier is a keyword of dialect. Otherwise, returns a Token with a
+// TokenType of eztok.TokenTypeIdentifier and the identifier as its Value.
+func NewIdentifierNode(dialect *Dialect) *eztok.CallbackNode {
+	return eztok.NewCallbackNodeWithPattern(
+		`[\p{L}_]`,
+		func(ctx eztok.Context) bool {
+			return isIdentifierStartRune(ctx.PeekRune(0))
+		},
+		func(ctx eztok.Context) (*eztok.Token, error) {
+			if r := ctx.PeekRune(0); !isIdentifierStartRune(r) {
+				return nil, fmt.Errorf("expected an identifier but got a '%c' rune", r)
+			}
+			word := eztok.ReadRunesUntilNot(ctx, isIdentifierRune)
+			if dialect.IsKeyword(word) {
+				return eztok.NewToken(TokenTypeKeyword, strings.ToUpper(word)), nil
+			}
+			return eztok.NewToken(eztok.TokenTypeIdentifier, word), nil
+		},
+	)
+}
+
+// A node that matches a number: a hexadecimal integer prefixed by '0x', or
+// decimal digits with an optional fraction and an optional exponent (such as
+// 42, 3.14, .5 or 1e-3). Returns a Token with a TokenType of TokenTypeNumber
+// and the text of the number as its Value, since SQL numbers are exact and
+// may not fit in any Go numeric type.
+var NumberNode = eztok.NewCallbackNodeWithPattern(
+	`[0-9]|\.[0-9]`,
+	func(ctx eztok.Context) bool {
+		r := ctx.PeekRune(0)
+		return isDigitRune(r) || (r == '.' && isDigitRune(ctx.PeekRune(1)))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if x := ctx.PeekRune(1); ctx.PeekRune(0) == '0' && (x == 'x' || x == 'X') && isHexDigitRune(ctx.PeekRune(2)) {
+			text := eztok.PeekString(ctx, 2)
+			eztok.SkipRunes(ctx, 2)
+			text += eztok.ReadRunesUntilNot(ctx, isHexDigitRune)
+			return eztok.NewToken(TokenTypeNumber, text), nil
+		}
+		text := eztok.ReadRunesUntilNot(ctx, isDigitRune)
+		if ctx.PeekRune(0) == '.' {
+			text += string(ctx.NextRune())
+			text += eztok.ReadRunesUntilNot(ctx, isDigitRune)
+		}
+		if len(text) <= 0 || text == "." {
+			return nil, fmt.Errorf("expected a digit after '%v'", text)
+		}
+		if e := ctx.PeekRune(0); e == 'e' || e == 'E' {
+			signLength := 0
+			if sign := ctx.PeekRune(1); sign == '+' || sign == '-' {
+				signLength = 1
+			}
+			if isDigitRune(ctx.PeekRune(1 + signLength)) {
+				text += eztok.PeekString(ctx, 1+signLength)
+				eztok.SkipRunes(ctx, 1+signLength)
+				text += eztok.ReadRunesUntilNot(ctx, isDigitRune)
+			}
+		}
+		return eztok.NewToken(TokenTypeNumber, text), nil
+	},
+)
+
+// Returns true if dialect allows strings encased in double quotes.
+func allowsDoubleQuotedStrings(dialect *Dialect) bool {
+	_, isIdentifierQuote := dialect.IdentifierQuotes['"']
+	return dialect.DoubleQuotedStrings && !isIdentifierQuote
+}
+
+// Returns the number of prefix runes before the opening quote of a string of
+// dialect that begins at the current rune in the Context, or -1 if no string
+// begins there.
+func stringPrefixLength(dialect *Dialect, ctx eztok.Context) int {
+	switch r := ctx.PeekRune(0); {
+	case r == '\'' || (r == '"' && allowsDoubleQuotedStrings(dialect)):
+		return 0
+	case ctx.PeekRune(1) != '\'':
+		return -1
+	case r == 'N' || r == 'n' || (dialect.EscapeStrings && (r == 'E' || r == 'e')):
+		return 1
+	}
+	return -1
+}
+
+// Returns the number of runes in the opening delimiter of a dollar-quoted
+// string that begins at the current rune in the Context, or 0 if none does.
+func dollarQuoteLength(ctx eztok.Context) int {
+	if ctx.PeekRune(0) != '$' {
+		return 0
+	}
+	length := 1
+	if isIdentifierStartRune(ctx.PeekRune(length)) {
+		for length++; isIdentifierRune(ctx.PeekRune(length)) && ctx.PeekRune(length) != '$'; length++ {
+		}
+	}
+	if ctx.PeekRune(length) != '$' {
+		return 0
+	}
+	return length + 1
+}
+
+// Consumes the rune following a '\' in a string of a Dialect with
+// BackslashEscapes, and writes the string it represents to builder.
+func readBackslashEscape(ctx eztok.Context, builder *strings.Builder) {
+	escape := ctx.NextRune()
+	if replStr, ok := backslashEscapes[escape]; ok {
+		builder.WriteString(replStr)
+	} else {
+		builder.WriteRune(escape)
+	}
+}
+
+// Consumes the escape sequence following a '\' in an escape string, and
+// writes the string it represents to builder. An octal or '\x' escape is a
+// single byte.
+func readEscapeStringEscape(ctx eztok.Context, builder *strings.Builder) error {
+	escape := ctx.NextRune()
+	switch {
+	case isOctalDigitRune(escape):
+		value := escape - '0'
+		for i := 0; i < 2 && isOctalDigitRune(ctx.PeekRune(0)); i++ {
+			value = value*8 + ctx.NextRune() - '0'
+		}
+		if value > 0xFF {
+			return fmt.Errorf("octal escape value %v > 255", value)
+		}
+		builder.WriteByte(byte(value))
+	case escape == 'x' && isHexDigitRune(ctx.PeekRune(0)):
+		value, _ := hexDigitValue(ctx.NextRune())
+		if digit, ok := hexDigitValue(ctx.PeekRune(0)); ok {
+			ctx.NextRune()
+			value = value*16 + digit
+		}
+		builder.WriteByte(byte(value))
+	case escape == 'u' || escape == 'U':
+		count := 4
+		if escape == 'U' {
+			count = 8
+		}
+		value := rune(0)
+		for i := 0; i < count; i++ {
+			digit, ok := hexDigitValue(ctx.PeekRune(i))
+			if !ok {
+				return fmt.Errorf("invalid escape '\\%c%v'", escape, eztok.PeekString(ctx, count))
+			}
+			value = value*16 + digit
+		}
+		if !utf8.ValidRune(value) {
+			return fmt.Errorf("invalid Unicode escape value %U", value)
+		}
+		eztok.SkipRunes(ctx, count)
+		builder.WriteRune(value)
+	default:
+		if replStr, ok := escapeStringEscapes[escape]; ok {
+			builder.WriteString(replStr)
+		} else {
+			builder.WriteRune(escape)
+		}
+	}
+	return nil
+}
+
+// Returns the value of the hexadecimal digit r, and true if r is one.
+func hexDigitValue(r rune) (rune, bool) {
+	switch {
+	case r >= '0' && r <= '9':
+		return r - '0', true
+	case r >= 'a' && r <= 'f':
+		return r - 'a' + 10, true
+	case r >= 'A' && r <= 'F':
+		return r - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// Returns true if r can begin an identifier.
+func isIdentifierStartRune(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+// Returns true if r can continue an identifier.
+func isIdentifierRune(r rune) bool {
+	return isIdentifierStartRune(r) || unicode.IsDigit(r) || r == '$'
+}
+
+// Returns true if r is an ASCII decimal digit.
+func isDigitRune(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// Returns true if r is an octal digit.
+func isOctalDigitRune(r rune) bool {
+	return r >= '0' && r <= '7'
+}
+
+// Returns true if r is a hexadecimal digit.
+func isHexDigitRune(r rune) bool {
+	_, ok := hexDigitValue(r)
+	return ok
+}
@@ -0,0 +1,106 @@
+package sql
+
+import (
+	"sort"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// TokenType definitions returned by Tokenizer. Strings are returned as
+// eztok.TokenTypeString with the unescaped string as their Value, and
+// identifiers that are not keywords as eztok.TokenTypeIdentifier with the
+// identifier as written as their Value. The TokenType of an operator or
+// punctuation Token is the operator itself (such as "<>" or "("), with a
+// Value of the operator.
+const (
+	TokenTypeKeyword             eztok.TokenType = "keyword"
+	TokenTypeQuotedIdentifier    eztok.TokenType = "quoted_identifier"
+	TokenTypeNumber              eztok.TokenType = "number"
+	TokenTypePositionalParameter eztok.TokenType = "positional_parameter"
+	TokenTypeNamedParameter      eztok.TokenType = "named_parameter"
+	TokenTypeComment             eztok.TokenType = "comment"
+)
+
+// The operators and punctuation of the supported dialects of SQL.
+var operators = []string{
+	"(", ")", "[", "]", ",", ";", ".", "*", "+", "-", "/", "%", "=", "==",
+	"<>", "!=", "<", ">", "<=", ">=", "<=>", "||", "&&", "&", "|", "^", "~",
+	"!", "<<", ">>", ":", "::", ":=", "=>", "->", "->>", "?", "?|", "?&", "@",
+	"@>", "<@", "#", "#>", "#>>", "~*", "!~", "!~*",
+}
+
+// A Tokenizer that tokenizes SQL following the lexical rules of a Dialect.
+// Keywords are recognised regardless of case. Whitespace is skipped.
+type Tokenizer struct {
+	// If true, comments are returned as TokenTypeComment Token objects.
+	// Otherwise, they are skipped.
+	KeepComments  bool
+	nodeTokenizer *eztok.InOrderNodeTokenizer
+}
+
+// Returns a new Tokenizer that follows the lexical rules of dialect, and that
+// skips comments.
+func NewTokenizer(dialect *Dialect) *Tokenizer {
+	spellings := append([]string{}, operators...)
+	// Try longer operators first, so that shorter ones do not shadow them.
+	sort.Slice(spellings, func(i, j int) bool {
+		if len(spellings[i]) != len(spellings[j]) {
+			return len(spellings[i]) > len(spellings[j])
+		}
+		return spellings[i] < spellings[j]
+	})
+	nodes := []eztok.Node{
+		WhitespaceNode,
+		NewCommentNode(dialect),
+		NewStringNode(dialect),
+	}
+	if len(dialect.IdentifierQuotes) > 0 {
+		nodes = append(nodes, NewQuotedIdentifierNode(dialect))
+	}
+	if dialect.DollarQuotedStrings {
+		nodes = append(nodes, DollarQuotedStringNode)
+	}
+	if dialect.QuestionParameters || dialect.DollarParameters || len(dialect.NamedParameterPrefixes) > 0 {
+		nodes = append(nodes, NewParameterNode(dialect))
+	}
+	nodes = append(nodes, NewIdentifierNode(dialect), NumberNode)
+	for _, spelling := range spellings {
+		if !isShadowedOperator(dialect, spelling) {
+			nodes = append(nodes, eztok.NewStringMatchNode(eztok.TokenType(spelling), spelling))
+		}
+	}
+	return &Tokenizer{
+		KeepComments:  false,
+		nodeTokenizer: eztok.NewInOrderNodeTokenizer(nodes...),
+	}
+}
+
+// For as long as eztok.IsEOF does not return true, tokenizes the next Token
+// as described by Tokenizer.
+func (tizer *Tokenizer) Tokenize(ctx eztok.Context) ([]*eztok.Token, error) {
+	toks := []*eztok.Token{}
+	for !eztok.IsEOF(ctx) {
+		tok, err := tizer.nodeTokenizer.TokenizeNext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if tok == nil || (tok.TokenType == TokenTypeComment && !tizer.KeepComments) {
+			continue
+		}
+		toks = append(toks, tok)
+	}
+	if fallible, ok := ctx.(eztok.FallibleContext); ok && fallible.Err() != nil {
+		return nil, fallible.Err()
+	}
+	return toks, nil
+}
+
+// Returns true if the operator spelling can never be parsed under dialect,
+// since its first rune always begins a comment, parameter or quoted
+// identifier instead.
+func isShadowedOperator(dialect *Dialect, spelling string) bool {
+	_, isIdentifierQuote := dialect.IdentifierQuotes[rune(spelling[0])]
+	return (spelling[0] == '#' && dialect.HashComments) ||
+		(spelling[0] == '?' && dialect.QuestionParameters) ||
+		(len(spelling) == 1 && isIdentifierQuote)
+}
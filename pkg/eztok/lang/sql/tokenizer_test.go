@@ -0,0 +1,180 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+func TestTokenizerQuoteEscaping(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect *Dialect
+		input   string
+		// The Token objects returned, or "" if tokenizing fails.
+		want string
+	}{
+		{"doubled quote", DialectANSI, "'it''s'", `string "it's"`},
+		{"only a doubled quote", DialectANSI, "''''", `string "'"`},
+		{"empty string", DialectANSI, "''", `string ""`},
+		{"doubled quote at end", DialectANSI, "'a''", ""},
+		{"adjacent strings", DialectANSI, "'a' 'b'", `string "a", string "b"`},
+		{"doubled quote in identifier", DialectANSI, `"a""b"`, `quoted_identifier "a\"b"`},
+		{"backslash is not an escape", DialectANSI, `'a\'`, `string "a\\"`},
+		{"backslash escape", DialectMySQL, `'a\'b'`, `string "a'b"`},
+		{"backslash at end", DialectMySQL, `'a\'`, ""},
+		{"doubled quote with backslash escapes", DialectMySQL, "'it''s'", `string "it's"`},
+		{"doubled double quote", DialectMySQL, `"a""b"`, `string "a\"b"`},
+		{"escape string", DialectPostgreSQL, `E'a\'b'`, `string "a'b"`},
+		{"doubled quote in escape string", DialectPostgreSQL, "e'a''b'", `string "a'b"`},
+		{"escape string prefix is an identifier", DialectMySQL, `E'a\'b'`, `identifier "E", string "a'b"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := eztok.TokenizeString(NewTokenizer(test.dialect), test.input)
+			if test.want == "" {
+				if err == nil {
+					t.Fatalf("tokenizing %q returned %v, want an error", test.input, sqlTestString(toks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizing %q returned error: %v", test.input, err)
+			}
+			if got := sqlTestString(toks); got != test.want {
+				t.Fatalf("tokenizing %q returned:\n%v\nwant:\n%v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizerDollarQuoting(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect *Dialect
+		input   string
+		// The Token objects returned, or "" if tokenizing fails.
+		want string
+	}{
+		{"empty tag", DialectPostgreSQL, "$$a$b$$", `string "a$b"`},
+		{"tag", DialectPostgreSQL, "$tag$a $$ b$tag$", `string "a $$ b"`},
+		{"tags are case-sensitive", DialectPostgreSQL, "$tag$a$TAG$b$tag$", `string "a$TAG$b"`},
+		{"tag with digits", DialectPostgreSQL, "$_t1$x$_t1$", `string "x"`},
+		{"no escaping", DialectPostgreSQL, `$$a''\b$$`, `string "a''\\b"`},
+		{"spans lines", DialectPostgreSQL, "$$a\nb$$ c", `string "a\nb", identifier "c"`},
+		{"unterminated", DialectPostgreSQL, "$$a", ""},
+		{"unterminated tag", DialectPostgreSQL, "$a$x", ""},
+		{"positional parameter", DialectPostgreSQL, "$1", "positional_parameter $1"},
+		// A tag cannot begin with a digit, so "$1" is a parameter.
+		{"parameter before a tag", DialectPostgreSQL, "$1$x$1$", ""},
+		{"within an identifier", DialectPostgreSQL, "x$y$z$y$", `identifier "x$y$z$y$"`},
+		{"not supported", DialectMySQL, "$$a$$", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := eztok.TokenizeString(NewTokenizer(test.dialect), test.input)
+			if test.want == "" {
+				if err == nil {
+					t.Fatalf("tokenizing %q returned %v, want an error", test.input, sqlTestString(toks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizing %q returned error: %v", test.input, err)
+			}
+			if got := sqlTestString(toks); got != test.want {
+				t.Fatalf("tokenizing %q returned:\n%v\nwant:\n%v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizerParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect *Dialect
+		input   string
+		// The Token objects returned, or "" if tokenizing fails.
+		want string
+	}{
+		{"ANSI ?", DialectANSI, "?", "positional_parameter ?"},
+		{"ANSI ?2", DialectANSI, "?2", "positional_parameter ?2"},
+		{"ANSI :name", DialectANSI, ":name", "named_parameter :name"},
+		{"ANSI @name", DialectANSI, "@name", `@ "@", identifier "name"`},
+		{"ANSI $name", DialectANSI, "$name", ""},
+		{"ANSI $1", DialectANSI, "$1", ""},
+		{"ANSI a::b", DialectANSI, "a::b", `identifier "a", :: "::", identifier "b"`},
+		{"ANSI :1", DialectANSI, ":1", `: ":", number "1"`},
+		{"PostgreSQL ?", DialectPostgreSQL, "?", `? "?"`},
+		{"PostgreSQL ?2", DialectPostgreSQL, "?2", `? "?", number "2"`},
+		{"PostgreSQL :name", DialectPostgreSQL, ":name", `: ":", identifier "name"`},
+		{"PostgreSQL @name", DialectPostgreSQL, "@name", `@ "@", identifier "name"`},
+		{"PostgreSQL $name", DialectPostgreSQL, "$name", ""},
+		{"PostgreSQL $1", DialectPostgreSQL, "$1", "positional_parameter $1"},
+		{"PostgreSQL a::b", DialectPostgreSQL, "a::b", `identifier "a", :: "::", identifier "b"`},
+		{"PostgreSQL :1", DialectPostgreSQL, ":1", `: ":", number "1"`},
+		{"PostgreSQL array slice", DialectPostgreSQL, "a[1:n]", `identifier "a", [ "[", number "1", : ":", identifier "n", ] "]"`},
+		{"MySQL ?", DialectMySQL, "?", "positional_parameter ?"},
+		{"MySQL ?2", DialectMySQL, "?2", "positional_parameter ?2"},
+		{"MySQL :name", DialectMySQL, ":name", "named_parameter :name"},
+		{"MySQL @name", DialectMySQL, "@name", `@ "@", identifier "name"`},
+		{"MySQL $name", DialectMySQL, "$name", ""},
+		{"MySQL $1", DialectMySQL, "$1", ""},
+		{"MySQL a::b", DialectMySQL, "a::b", `identifier "a", :: "::", identifier "b"`},
+		{"MySQL :1", DialectMySQL, ":1", `: ":", number "1"`},
+		{"SQLite ?", DialectSQLite, "?", "positional_parameter ?"},
+		{"SQLite ?2", DialectSQLite, "?2", "positional_parameter ?2"},
+		{"SQLite :name", DialectSQLite, ":name", "named_parameter :name"},
+		{"SQLite @name", DialectSQLite, "@name", "named_parameter @name"},
+		{"SQLite $name", DialectSQLite, "$name", "named_parameter $name"},
+		{"SQLite $1", DialectSQLite, "$1", ""},
+		{"SQLite a::b", DialectSQLite, "a::b", `identifier "a", :: "::", identifier "b"`},
+		{"SQLite :1", DialectSQLite, ":1", `: ":", number "1"`},
+		{"SQLServer ?", DialectSQLServer, "?", `? "?"`},
+		{"SQLServer ?2", DialectSQLServer, "?2", `? "?", number "2"`},
+		{"SQLServer :name", DialectSQLServer, ":name", `: ":", identifier "name"`},
+		{"SQLServer @name", DialectSQLServer, "@name", "named_parameter @name"},
+		{"SQLServer $name", DialectSQLServer, "$name", ""},
+		{"SQLServer $1", DialectSQLServer, "$1", ""},
+		{"SQLServer a::b", DialectSQLServer, "a::b", `identifier "a", :: "::", identifier "b"`},
+		{"SQLServer :1", DialectSQLServer, ":1", `: ":", number "1"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := eztok.TokenizeString(NewTokenizer(test.dialect), test.input)
+			if test.want == "" {
+				if err == nil {
+					t.Fatalf("tokenizing %q returned %v, want an error", test.input, sqlTestString(toks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizing %q returned error: %v", test.input, err)
+			}
+			if got := sqlTestString(toks); got != test.want {
+				t.Fatalf("tokenizing %q returned:\n%v\nwant:\n%v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+// Returns a string of the TokenType of each Token in toks, followed by its
+// Value if it is a string or a *Parameter, separated by commas.
+func sqlTestString(toks []*eztok.Token) string {
+	strs := make([]string, len(toks))
+	for i, tok := range toks {
+		strs[i] = string(tok.TokenType)
+		switch value := tok.Value.(type) {
+		case string:
+			strs[i] += fmt.Sprintf(" %q", value)
+		case *Parameter:
+			strs[i] += fmt.Sprintf(" %c%v", value.Prefix, value.Name)
+			if value.Number != 0 {
+				strs[i] += fmt.Sprint(value.Number)
+			}
+		}
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,55 @@
+package toml
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Represents a TOML local date, such as 1979-05-27.
+type LocalDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// Represents a TOML local time, such as 07:32:00.999999.
+type LocalTime struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+// Represents a TOML local date-time, such as 1979-05-27T07:32:00.
+type LocalDateTime struct {
+	Date LocalDate
+	Time LocalTime
+}
+
+// Returns a string representation of the LocalDate in the form YYYY-MM-DD.
+func (date LocalDate) ToString() string {
+	return fmt.Sprintf("%04d-%02d-%02d", date.Year, int(date.Month), date.Day)
+}
+
+// Returns a string representation of the LocalTime in the form HH:MM:SS,
+// followed by a fraction of a second if Nanosecond is not 0.
+func (t LocalTime) ToString() string {
+	str := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond != 0 {
+		str += strings.TrimRight(fmt.Sprintf(".%09d", t.Nanosecond), "0")
+	}
+	return str
+}
+
+// Returns a string representation of the LocalDateTime in the form
+// YYYY-MM-DDTHH:MM:SS, followed by a fraction of a second if it has one.
+func (dateTime LocalDateTime) ToString() string {
+	return dateTime.Date.ToString() + "T" + dateTime.Time.ToString()
+}
+
+// Returns a time.Time of the LocalDateTime in loc.
+func (dateTime LocalDateTime) In(loc *time.Location) time.Time {
+	return time.Date(dateTime.Date.Year, dateTime.Date.Month, dateTime.Date.Day,
+		dateTime.Time.Hour, dateTime.Time.Minute, dateTime.Time.Second, dateTime.Time.Nanosecond, loc)
+}
@@ -0,0 +1,563 @@
+package toml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+// Holds a map of escape rune (a rune following a '\' in a basic string) to the
+// actual string contents of the escape. The 'u' and 'U' escapes are handled
+// separately.
+var escapedRuneToString = map[rune]string{
+	'"':  "\"",
+	'\\': "\\",
+	'b':  "\b",
+	'f':  "\f",
+	'n':  "\n",
+	'r':  "\r",
+	't':  "\t",
+}
+
+// A node that matches a space or tab and skips it (i.e. generates no Token).
+var WhitespaceNode = eztok.NewCallbackNodeWithPattern(
+	`[\t ]`,
+	func(ctx eztok.Context) bool {
+		return isWhitespaceRune(ctx.PeekRune(0))
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if r := ctx.NextRune(); !isWhitespaceRune(r) {
+			return nil, fmt.Errorf("expected a whitespace rune but got a '%c' rune", r)
+		}
+		return nil, nil
+	},
+)
+
+// A node that matches a line break ("\n" or "\r\n"). Returns a Token with a
+// TokenType of TokenTypeNewline and a Value of "\n".
+var NewlineNode = eztok.NewCallbackNodeWithPattern(
+	`\r?\n`,
+	func(ctx eztok.Context) bool {
+		return newlineLength(ctx, 0) > 0
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		length := newlineLength(ctx, 0)
+		if length <= 0 {
+			return nil, fmt.Errorf("expected a newline")
+		}
+		eztok.SkipRunes(ctx, length)
+		return eztok.NewToken(TokenTypeNewline, "\n"), nil
+	},
+)
+
+// A node that matches a '#' comment, up to (but not including) the end of its
+// line. Returns a Token with a TokenType of TokenTypeComment and the text of
+// the comment (including its '#') as its Value.
+var CommentNode = eztok.NewCallbackNodeWithPattern(
+	`#`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '#'
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		text := eztok.ReadRunesUntil(ctx, func(r rune) bool {
+			return newlineLength(ctx, 0) > 0
+		})
+		for _, r := range text {
+			if isControlRune(r) {
+				return nil, fmt.Errorf("control rune %U in comment", r)
+			}
+		}
+		return eztok.NewToken(TokenTypeComment, text), nil
+	},
+)
+
+// A node that matches a key or a part of a dotted key: a bare key of ASCII
+// letters, digits, '_' and '-', or a single-line basic or literal string.
+// Returns a Token with a TokenType of TokenTypeKey and the (unescaped) key as
+// its Value.
+var KeyNode = eztok.NewCallbackNodeWithPattern(
+	`[A-Za-z0-9_\-"']`,
+	func(ctx eztok.Context) bool {
+		r := ctx.PeekRune(0)
+		return isBareKeyRune(r) || r == '"' || r == '\''
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if quote := ctx.PeekRune(0); quote == '"' || quote == '\'' {
+			key, err := readString(ctx, quote, false)
+			if err != nil {
+				return nil, err
+			}
+			return eztok.NewToken(TokenTypeKey, key), nil
+		}
+		key := eztok.ReadRunesUntilNot(ctx, isBareKeyRune)
+		if len(key) <= 0 {
+			return nil, fmt.Errorf("expected a key but got a '%c' rune", ctx.PeekRune(0))
+		}
+		return eztok.NewToken(TokenTypeKey, key), nil
+	},
+)
+
+// A node that matches a basic string ("..."), a multi-line basic string
+// ("""..."""), a literal string ('...') or a multi-line literal string
+// (encased in three single quotes). Escape sequences are only replaced within
+// basic strings. A line break immediately following the opening delimiter of
+// a multi-line string is removed, as is a '\' at the end of a line of a
+// multi-line basic string, together with all whitespace and line breaks
+// following it. Returns a Token with a TokenType of eztok.TokenTypeString and
+// the string as its Value.
+var StringNode = eztok.NewCallbackNodeWithPattern(
+	`"|'`,
+	func(ctx eztok.Context) bool {
+		return ctx.PeekRune(0) == '"' || ctx.PeekRune(0) == '\''
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		quote := ctx.PeekRune(0)
+		if quote != '"' && quote != '\'' {
+			return nil, fmt.Errorf("expected a string but got a '%c' rune", quote)
+		}
+		str, err := readString(ctx, quote, eztok.HasPrefix(ctx, strings.Repeat(string(quote), 3)))
+		if err != nil {
+			return nil, err
+		}
+		return eztok.NewToken(eztok.TokenTypeString, str), nil
+	},
+)
+
+// A node that matches an offset date-time, local date-time, local date or local
+// time, as defined by RFC 3339 (except that the 'T' between a date and time
+// may be a space). Returns a Token with a TokenType of TokenTypeOffsetDateTime
+// (with a time.Time Value), TokenTypeLocalDateTime (with a LocalDateTime
+// Value), TokenTypeLocalDate (with a LocalDate Value) or TokenTypeLocalTime
+// (with a LocalTime Value).
+var DateTimeNode = eztok.NewCallbackNodeWithPattern(
+	`[0-9]{4}-|[0-9]{2}:`,
+	func(ctx eztok.Context) bool {
+		return isDateStart(ctx, 0) || isTimeStart(ctx, 0)
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		if !isDateStart(ctx, 0) {
+			t, err := readTime(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return newValueToken(ctx, TokenTypeLocalTime, t, t.ToString())
+		}
+		date, err := readDate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		switch r := ctx.PeekRune(0); {
+		case (r == 'T' || r == 't') && isTimeStart(ctx, 1):
+		case r == ' ' && isTimeStart(ctx, 1):
+		default:
+			return newValueToken(ctx, TokenTypeLocalDate, date, date.ToString())
+		}
+		ctx.NextRune()
+		t, err := readTime(ctx)
+		if err != nil {
+			return nil, err
+		}
+		dateTime := LocalDateTime{date, t}
+		switch r := ctx.PeekRune(0); {
+		case r == 'Z' || r == 'z':
+			ctx.NextRune()
+			return newValueToken(ctx, TokenTypeOffsetDateTime, dateTime.In(time.UTC), dateTime.ToString()+"Z")
+		case r == '+' || r == '-':
+			ctx.NextRune()
+			hour, hourOk := readFixedDigits(ctx, 2)
+			colon := ctx.NextRune()
+			minute, minuteOk := readFixedDigits(ctx, 2)
+			if !hourOk || colon != ':' || !minuteOk || hour > 23 || minute > 59 {
+				return nil, fmt.Errorf("invalid time offset after '%v'", dateTime.ToString())
+			}
+			offset := (hour*60 + minute) * 60
+			if r == '-' {
+				offset = -offset
+			}
+			zone := time.FixedZone("", offset)
+			return newValueToken(ctx, TokenTypeOffsetDateTime, dateTime.In(zone), dateTime.ToString())
+		}
+		return newValueToken(ctx, TokenTypeLocalDateTime, dateTime, dateTime.ToString())
+	},
+)
+
+// A node that matches an integer (decimal, or hexadecimal, octal or binary
+// with a '0x', '0o' or '0b' prefix) or a float (including inf and nan), each
+// of which may contain '_' between digits. Returns a Token with a TokenType of
+// eztok.TokenTypeInteger and an int64 Value, or of eztok.TokenTypeFloat and a
+// float64 Value.
+var NumberNode = eztok.NewCallbackNodeWithPattern(
+	`[+\-]?(?:[0-9]|inf|nan)`,
+	func(ctx eztok.Context) bool {
+		signLength := 0
+		if r := ctx.PeekRune(0); r == '+' || r == '-' {
+			signLength = 1
+		}
+		word := eztok.PeekString(ctx, signLength+3)[signLength:]
+		return isDigitRune(ctx.PeekRune(signLength)) || word == "inf" || word == "nan"
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		text := ""
+		if r := ctx.PeekRune(0); r == '+' || r == '-' {
+			text += string(ctx.NextRune())
+		}
+		switch word := eztok.PeekString(ctx, 3); word {
+		case "inf", "nan":
+			eztok.SkipRunes(ctx, 3)
+			value := math.NaN()
+			if word == "inf" {
+				value = math.Inf(1)
+				if text == "-" {
+					value = math.Inf(-1)
+				}
+			}
+			return newValueToken(ctx, eztok.TokenTypeFloat, value, text+word)
+		}
+
+		if base := basePrefixes[eztok.PeekString(ctx, 2)]; base != 0 && text == "" {
+			text = eztok.PeekString(ctx, 2)
+			eztok.SkipRunes(ctx, 2)
+			raw, digits := readDigitRun(ctx, func(r rune) bool {
+				digit, ok := hexDigitValue(r)
+				return ok && digit < rune(base)
+			})
+			text += raw
+			if len(digits) <= 0 {
+				return nil, fmt.Errorf("expected a digit after '%v'", text)
+			}
+			intVal, err := strconv.ParseInt(digits, base, 64)
+			if err != nil {
+				return nil, fmt.Errorf("integer '%v' is out of range", text)
+			}
+			return newValueToken(ctx, eztok.TokenTypeInteger, intVal, text)
+		}
+
+		number := text
+		raw, digits := readDigitRun(ctx, isDigitRune)
+		text += raw
+		number += digits
+		if len(digits) > 1 && digits[0] == '0' {
+			return nil, fmt.Errorf("invalid leading zero in number '%v'", text)
+		}
+		isInteger := true
+		if ctx.PeekRune(0) == '.' {
+			text += string(ctx.NextRune())
+			raw, digits := readDigitRun(ctx, isDigitRune)
+			text += raw
+			if len(digits) <= 0 {
+				return nil, fmt.Errorf("expected a digit after '%v'", text)
+			}
+			number += "." + digits
+			isInteger = false
+		}
+		if r := ctx.PeekRune(0); r == 'e' || r == 'E' {
+			text += string(ctx.NextRune())
+			number += "e"
+			if r := ctx.PeekRune(0); r == '+' || r == '-' {
+				text += string(ctx.NextRune())
+				number += string(r)
+			}
+			raw, digits := readDigitRun(ctx, isDigitRune)
+			text += raw
+			if len(digits) <= 0 {
+				return nil, fmt.Errorf("expected a digit after '%v'", text)
+			}
+			number += digits
+			isInteger = false
+		}
+
+		if isInteger {
+			intVal, err := strconv.ParseInt(number, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("integer '%v' is out of range", text)
+			}
+			return newValueToken(ctx, eztok.TokenTypeInteger, intVal, text)
+		}
+		// The syntax has already been checked, so the only possible error is
+		// that the number is out of range, in which case floatVal is an infinity.
+		floatVal, _ := strconv.ParseFloat(number, 64)
+		return newValueToken(ctx, eztok.TokenTypeFloat, floatVal, text)
+	},
+)
+
+// A node that matches the boolean 'true' or 'false'. Returns a Token with a
+// TokenType of TokenTypeBoolean and a bool Value.
+var BooleanNode = eztok.NewCallbackNodeWithPattern(
+	`true|false`,
+	func(ctx eztok.Context) bool {
+		return eztok.HasPrefix(ctx, "true") || eztok.HasPrefix(ctx, "false")
+	},
+	func(ctx eztok.Context) (*eztok.Token, error) {
+		for _, word := range []string{"true", "false"} {
+			if eztok.HasPrefix(ctx, word) {
+				eztok.SkipRunes(ctx, len(word))
+				return newValueToken(ctx, TokenTypeBoolean, word == "true", word)
+			}
+		}
+		return nil, fmt.Errorf("expected 'true' or 'false'")
+	},
+)
+
+// Holds a map of the prefix of a non-decimal integer to its base.
+var basePrefixes = map[string]int{
+	"0x": 16,
+	"0o": 8,
+	"0b": 2,
+}
+
+// Returns a Token with the provided TokenType and Value, if the value whose
+// text is text is followed by a rune that can end a value. Otherwise, returns
+// an error.
+func newValueToken(ctx eztok.Context, tokenType eztok.TokenType, value any, text string) (*eztok.Token, error) {
+	switch r := ctx.PeekRune(0); {
+	case eztok.IsEOF(ctx), isWhitespaceRune(r), newlineLength(ctx, 0) > 0:
+	case r == '#' || r == ',' || r == ']' || r == '}':
+	default:
+		return nil, fmt.Errorf("unexpected '%c' after value '%v'", r, text)
+	}
+	return eztok.NewToken(tokenType, value), nil
+}
+
+// Consumes a string encased in quote, which is a multi-line string if
+// multiline is true, and returns its contents.
+func readString(ctx eztok.Context, quote rune, multiline bool) (string, error) {
+	delimiter := string(quote)
+	if multiline {
+		delimiter = strings.Repeat(delimiter, 3)
+	}
+	eztok.SkipRunes(ctx, len(delimiter))
+	if multiline {
+		eztok.SkipRunes(ctx, newlineLength(ctx, 0))
+	}
+	var builder strings.Builder
+	for {
+		if eztok.IsEOF(ctx) {
+			return "", fmt.Errorf("unterminated string '%v'", builder.String())
+		}
+		if multiline && eztok.HasPrefix(ctx, delimiter) {
+			// Up to two quotes may precede the closing delimiter.
+			for extra := 0; extra < 2 && ctx.PeekRune(len(delimiter)) == quote; extra++ {
+				builder.WriteRune(ctx.NextRune())
+			}
+			eztok.SkipRunes(ctx, len(delimiter))
+			return builder.String(), nil
+		}
+		if length := newlineLength(ctx, 0); length > 0 {
+			if !multiline {
+				return "", fmt.Errorf("newline in string '%v'", builder.String())
+			}
+			builder.WriteString(eztok.PeekString(ctx, length))
+			eztok.SkipRunes(ctx, length)
+			continue
+		}
+		r := ctx.NextRune()
+		switch {
+		case !multiline && r == quote:
+			return builder.String(), nil
+		case r == '\\' && quote == '"':
+			if multiline && isLineEndingBackslash(ctx) {
+				eztok.ReadRunesUntilNot(ctx, func(r rune) bool {
+					return isWhitespaceRune(r) || newlineLength(ctx, 0) > 0
+				})
+				continue
+			}
+			if err := readEscape(ctx, &builder); err != nil {
+				return "", fmt.Errorf("%v while tokenizing string '%v'", err, builder.String())
+			}
+		case isControlRune(r):
+			return "", fmt.Errorf("unescaped control rune %U in string '%v'", r, builder.String())
+		default:
+			builder.WriteRune(r)
+		}
+	}
+}
+
+// Consumes the escape sequence following a '\' in a basic string, and writes
+// the string it represents to builder.
+func readEscape(ctx eztok.Context, builder *strings.Builder) error {
+	if eztok.IsEOF(ctx) {
+		return fmt.Errorf("unterminated escape")
+	}
+	escape := ctx.NextRune()
+	if replStr, ok := escapedRuneToString[escape]; ok {
+		builder.WriteString(replStr)
+		return nil
+	}
+	count := 0
+	switch escape {
+	case 'u':
+		count = 4
+	case 'U':
+		count = 8
+	default:
+		return fmt.Errorf("unknown escape '%c'", escape)
+	}
+	value := rune(0)
+	for i := 0; i < count; i++ {
+		digit, ok := hexDigitValue(ctx.PeekRune(i))
+		if !ok {
+			return fmt.Errorf("invalid escape '\\%c%v'", escape, eztok.PeekString(ctx, count))
+		}
+		value = value*16 + digit
+	}
+	if !utf8.ValidRune(value) {
+		return fmt.Errorf("invalid Unicode escape value %U", value)
+	}
+	eztok.SkipRunes(ctx, count)
+	builder.WriteRune(value)
+	return nil
+}
+
+// Returns true if the '\' that was just consumed from a multi-line basic
+// string is followed by only whitespace until the end of its line.
+func isLineEndingBackslash(ctx eztok.Context) bool {
+	i := 0
+	for isWhitespaceRune(ctx.PeekRune(i)) {
+		i++
+	}
+	return newlineLength(ctx, i) > 0
+}
+
+// Consumes a local date in the form YYYY-MM-DD and returns it.
+func readDate(ctx eztok.Context) (LocalDate, error) {
+	year, yearOk := readFixedDigits(ctx, 4)
+	dash := ctx.NextRune()
+	month, monthOk := readFixedDigits(ctx, 2)
+	dash2 := ctx.NextRune()
+	day, dayOk := readFixedDigits(ctx, 2)
+	date := LocalDate{year, time.Month(month), day}
+	if !yearOk || dash != '-' || !monthOk || dash2 != '-' || !dayOk {
+		return LocalDate{}, fmt.Errorf("expected a date in the form YYYY-MM-DD")
+	}
+	// The day after the last day of a month normalises to the next month.
+	if month < 1 || month > 12 || day < 1 || time.Date(year, date.Month, day, 0, 0, 0, 0, time.UTC).Day() != day {
+		return LocalDate{}, fmt.Errorf("invalid date '%v'", date.ToString())
+	}
+	return date, nil
+}
+
+// Consumes a local time in the form HH:MM:SS, with an optional fraction of a
+// second, and returns it. Digits of the fraction beyond nanoseconds are
+// truncated.
+func readTime(ctx eztok.Context) (LocalTime, error) {
+	hour, hourOk := readFixedDigits(ctx, 2)
+	colon := ctx.NextRune()
+	minute, minuteOk := readFixedDigits(ctx, 2)
+	colon2 := ctx.NextRune()
+	second, secondOk := readFixedDigits(ctx, 2)
+	t := LocalTime{hour, minute, second, 0}
+	if !hourOk || colon != ':' || !minuteOk || colon2 != ':' || !secondOk {
+		return LocalTime{}, fmt.Errorf("expected a time in the form HH:MM:SS")
+	}
+	if hour > 23 || minute > 59 || second > 59 {
+		return LocalTime{}, fmt.Errorf("invalid time '%v'", t.ToString())
+	}
+	if ctx.PeekRune(0) == '.' && isDigitRune(ctx.PeekRune(1)) {
+		ctx.NextRune()
+		fraction := eztok.ReadRunesUntilNot(ctx, isDigitRune)
+		for i, scale := 0, 100000000; i < len(fraction) && scale > 0; i, scale = i+1, scale/10 {
+			t.Nanosecond += int(fraction[i]-'0') * scale
+		}
+	}
+	return t, nil
+}
+
+// Consumes count runes and returns the value of them as a decimal number, and
+// true if they all are decimal digits.
+func readFixedDigits(ctx eztok.Context, count int) (int, bool) {
+	value := 0
+	for i := 0; i < count; i++ {
+		r := ctx.NextRune()
+		if !isDigitRune(r) {
+			return 0, false
+		}
+		value = value*10 + int(r-'0')
+	}
+	return value, true
+}
+
+// Consumes a run of digits, in which each '_' must be between two digits.
+// Returns the consumed runes, and the digits without any '_'.
+func readDigitRun(ctx eztok.Context, isDigit func(r rune) bool) (string, string) {
+	var raw, digits strings.Builder
+	for {
+		r := ctx.PeekRune(0)
+		if r == '_' && digits.Len() > 0 && isDigit(ctx.PeekRune(1)) {
+			raw.WriteRune(ctx.NextRune())
+		} else if isDigit(r) {
+			raw.WriteRune(ctx.NextRune())
+			digits.WriteRune(r)
+		} else {
+			return raw.String(), digits.String()
+		}
+	}
+}
+
+// Returns true if a local date begins relative runes ahead of the current rune
+// in the Context.
+func isDateStart(ctx eztok.Context, relative int) bool {
+	for i := 0; i < 4; i++ {
+		if !isDigitRune(ctx.PeekRune(relative + i)) {
+			return false
+		}
+	}
+	return ctx.PeekRune(relative+4) == '-'
+}
+
+// Returns true if a local time begins relative runes ahead of the current rune
+// in the Context.
+func isTimeStart(ctx eztok.Context, relative int) bool {
+	return isDigitRune(ctx.PeekRune(relative)) && isDigitRune(ctx.PeekRune(relative+1)) &&
+		ctx.PeekRune(relative+2) == ':'
+}
+
+// Returns the number of runes in the line break that begins relative runes
+// ahead of the current rune in the Context, or 0 if none does.
+func newlineLength(ctx eztok.Context, relative int) int {
+	switch {
+	case ctx.PeekRune(relative) == '\n':
+		return 1
+	case ctx.PeekRune(relative) == '\r' && ctx.PeekRune(relative+1) == '\n':
+		return 2
+	}
+	return 0
+}
+
+// Returns the value of the hexadecimal digit r, and true if r is one.
+func hexDigitValue(r rune) (rune, bool) {
+	switch {
+	case r >= '0' && r <= '9':
+		return r - '0', true
+	case r >= 'a' && r <= 'f':
+		return r - 'a' + 10, true
+	case r >= 'A' && r <= 'F':
+		return r - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// Returns true if r is a TOML whitespace rune (a space or tab).
+func isWhitespaceRune(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// Returns true if r is a control rune that must be escaped within a string
+// (any control rune other than a tab).
+func isControlRune(r rune) bool {
+	return (r < 0x20 && r != '\t') || r == 0x7F
+}
+
+// Returns true if r may be part of a bare key.
+func isBareKeyRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || isDigitRune(r) || r == '_' || r == '-'
+}
+
+// Returns true if r is an ASCII decimal digit.
+func isDigitRune(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,125 @@
+package toml
+
+import "github.com/tpillow/eztok/pkg/eztok"
+
+// TokenType definitions returned by Tokenizer. String values are returned as
+// eztok.TokenTypeString with the unescaped string as their Value, integers as
+// eztok.TokenTypeInteger with an int64 Value, and floats as
+// eztok.TokenTypeFloat with a float64 Value. The TokenType of a punctuation
+// Token is the punctuation itself (such as "=" or "[["), with a Value of the
+// punctuation.
+const (
+	TokenTypeNewline        eztok.TokenType = "newline"
+	TokenTypeComment        eztok.TokenType = "comment"
+	TokenTypeKey            eztok.TokenType = "key"
+	TokenTypeBoolean        eztok.TokenType = "boolean"
+	TokenTypeOffsetDateTime eztok.TokenType = "offset_date_time"
+	TokenTypeLocalDateTime  eztok.TokenType = "local_date_time"
+	TokenTypeLocalDate      eztok.TokenType = "local_date"
+	TokenTypeLocalTime      eztok.TokenType = "local_time"
+)
+
+// A Tokenizer that tokenizes TOML documents. Since the same text may be a key
+// or a value (such as 1234 or true), each Token is tokenized as a key or as a
+// value depending on where it is: keys are expected at the start of a line,
+// within a table header, and within an inline table before each '='. Each
+// part of a dotted key is a separate TokenTypeKey Token, with a "." Token
+// between them. A TokenTypeNewline Token is returned for each line break,
+// except within an array or inline table, where line breaks are not
+// significant.
+type Tokenizer struct {
+	// If true, comments are returned as TokenTypeComment Token objects.
+	// Otherwise, they are skipped.
+	KeepComments   bool
+	keyTokenizer   *eztok.InOrderNodeTokenizer
+	valueTokenizer *eztok.InOrderNodeTokenizer
+}
+
+// Returns a new Tokenizer that skips comments.
+func NewTokenizer() *Tokenizer {
+	return &Tokenizer{
+		KeepComments: false,
+		keyTokenizer: eztok.NewInOrderNodeTokenizer(
+			WhitespaceNode,
+			NewlineNode,
+			CommentNode,
+			KeyNode,
+			eztok.NewStringMatchNode("[[", "[["),
+			eztok.NewStringMatchNode("]]", "]]"),
+			eztok.NewStringMatchNode("[", "["),
+			eztok.NewStringMatchNode("]", "]"),
+			eztok.NewStringMatchNode(".", "."),
+			eztok.NewStringMatchNode("=", "="),
+			eztok.NewStringMatchNode("}", "}"),
+			eztok.NewStringMatchNode(",", ","),
+		),
+		valueTokenizer: eztok.NewInOrderNodeTokenizer(
+			WhitespaceNode,
+			NewlineNode,
+			CommentNode,
+			StringNode,
+			DateTimeNode,
+			NumberNode,
+			BooleanNode,
+			eztok.NewStringMatchNode("[", "["),
+			eztok.NewStringMatchNode("]", "]"),
+			eztok.NewStringMatchNode("{", "{"),
+			eztok.NewStringMatchNode("}", "}"),
+			eztok.NewStringMatchNode(",", ","),
+		),
+	}
+}
+
+// For as long as eztok.IsEOF does not return true, tokenizes the next Token
+// as described by Tokenizer.
+func (tizer *Tokenizer) Tokenize(ctx eztok.Context) ([]*eztok.Token, error) {
+	toks := []*eztok.Token{}
+	// The open arrays ('[') and inline tables ('{') containing the current
+	// rune, innermost last.
+	brackets := []eztok.TokenType{}
+	expectKey := true
+	for !eztok.IsEOF(ctx) {
+		nodeTokenizer := tizer.valueTokenizer
+		if expectKey {
+			nodeTokenizer = tizer.keyTokenizer
+		}
+		tok, err := nodeTokenizer.TokenizeNext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if tok == nil || (tok.TokenType == TokenTypeComment && !tizer.KeepComments) {
+			continue
+		}
+		switch tok.TokenType {
+		case TokenTypeNewline:
+			if len(brackets) > 0 {
+				continue
+			}
+			expectKey = true
+		case "=":
+			expectKey = false
+		case "[":
+			// A '[' where a key is expected outside of any brackets begins a
+			// table header, which contains a key.
+			if !expectKey || len(brackets) > 0 {
+				brackets = append(brackets, tok.TokenType)
+				expectKey = false
+			}
+		case "{":
+			brackets = append(brackets, tok.TokenType)
+			expectKey = true
+		case "]", "}":
+			if len(brackets) > 0 {
+				brackets = brackets[:len(brackets)-1]
+				expectKey = false
+			}
+		case ",":
+			expectKey = len(brackets) > 0 && brackets[len(brackets)-1] == "{"
+		}
+		toks = append(toks, tok)
+	}
+	if fallible, ok := ctx.(eztok.FallibleContext); ok && fallible.Err() != nil {
+		return nil, fallible.Err()
+	}
+	return toks, nil
+}
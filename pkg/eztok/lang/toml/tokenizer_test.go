@@ -0,0 +1,117 @@
+package toml
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tpillow/eztok/pkg/eztok"
+)
+
+func TestTokenizer(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		// The Token objects returned, or "" if tokenizing fails.
+		want string
+	}{
+		{"dotted keys", "a . b.c = 1", `key "a" at 1:1, . at 1:3, key "b" at 1:5, . at 1:6, key "c" at 1:7, = at 1:9, integer 1 at 1:11`},
+		{"quoted keys", `"a.b".'c d'."A" = 'x'`,
+			`key "a.b" at 1:1, . at 1:6, key "c d" at 1:7, . at 1:12, key "A" at 1:13, = at 1:17, string "x" at 1:19`},
+		{"integer key", "1234 = true", `key "1234" at 1:1, = at 1:6, boolean true at 1:8`},
+		{"boolean key", "true = 1", `key "true" at 1:1, = at 1:6, integer 1 at 1:8`},
+		{"float key", "3.14 = 1", `key "3" at 1:1, . at 1:2, key "14" at 1:3, = at 1:6, integer 1 at 1:8`},
+		{"date key", "1979-05-27 = 1", `key "1979-05-27" at 1:1, = at 1:12, integer 1 at 1:14`},
+		{"table headers", "[a.b]\n[[ c ]]\nd = 1",
+			`[ at 1:1, key "a" at 1:2, . at 1:3, key "b" at 1:4, ] at 1:5, newline "\n" at 1:6, ` +
+				`[[ at 2:1, key "c" at 2:4, ]] at 2:6, newline "\n" at 2:8, key "d" at 3:1, = at 3:3, integer 1 at 3:5`},
+		{"inline tables in an array", "x = [\n  { a = 1, b = [2,\n 3] }, # c\n  {c.d = 'e'},\n]\ny = 4",
+			`key "x" at 1:1, = at 1:3, [ at 1:5, { at 2:3, key "a" at 2:5, = at 2:7, integer 1 at 2:9, , at 2:10, ` +
+				`key "b" at 2:12, = at 2:14, [ at 2:16, integer 2 at 2:17, , at 2:18, integer 3 at 3:2, ] at 3:3, } at 3:5, , at 3:6, ` +
+				`{ at 4:3, key "c" at 4:4, . at 4:5, key "d" at 4:6, = at 4:8, string "e" at 4:10, } at 4:13, , at 4:14, ` +
+				`] at 5:1, newline "\n" at 5:2, key "y" at 6:1, = at 6:3, integer 4 at 6:5`},
+		{"multi-line basic string", "s = \"\"\"\nThe quick \\\n\n   brown \\  \r\n fox\\n\"\"\"\"",
+			`key "s" at 1:1, = at 1:3, string "The quick brown fox\n\"" at 1:5`},
+		{"multi-line literal string", "s = '''\na \\\nb'''", `key "s" at 1:1, = at 1:3, string "a \\\nb" at 1:5`},
+		{"CRLF", "a = 1\r\n[t]\r\nb = \"\"\"\r\nx\r\n\"\"\"\r\n",
+			`key "a" at 1:1, = at 1:3, integer 1 at 1:5, newline "\n" at 1:6, [ at 2:1, key "t" at 2:2, ] at 2:3, newline "\n" at 2:4, ` +
+				`key "b" at 3:1, = at 3:3, string "x\r\n" at 3:5, newline "\n" at 5:4`},
+		{"lone CR", "a = 1\rb = 2", ""},
+		{"local date", "d = 1979-05-27", `key "d" at 1:1, = at 1:3, local_date toml.LocalDate{Year:1979, Month:5, Day:27} at 1:5`},
+		{"local time", "t = 07:32:00.9999999999",
+			`key "t" at 1:1, = at 1:3, local_time toml.LocalTime{Hour:7, Minute:32, Second:0, Nanosecond:999999999} at 1:5`},
+		{"local date-time", "dt = 1979-05-27 07:32:00",
+			`key "dt" at 1:1, = at 1:4, local_date_time toml.LocalDateTime{Date:toml.LocalDate{Year:1979, Month:5, Day:27}, ` +
+				`Time:toml.LocalTime{Hour:7, Minute:32, Second:0, Nanosecond:0}} at 1:6`},
+		{"offset date-time in UTC", "odt = 1979-05-27t07:32:00.5z",
+			`key "odt" at 1:1, = at 1:5, offset_date_time time.Date(1979, time.May, 27, 7, 32, 0, 500000000, time.UTC) at 1:7`},
+		{"offset date-time", "odt = 1979-05-27T00:32:00-07:00",
+			`key "odt" at 1:1, = at 1:5, offset_date_time time.Date(1979, time.May, 27, 0, 32, 0, 0, time.Location("")) at 1:7`},
+		{"leap day", "d = 2000-02-29", `key "d" at 1:1, = at 1:3, local_date toml.LocalDate{Year:2000, Month:2, Day:29} at 1:5`},
+		{"invalid day", "d = 1979-02-30", ""},
+		{"invalid leap day", "d = 1900-02-29", ""},
+		{"invalid month", "d = 1979-13-01", ""},
+		{"invalid hour", "t = 25:00:00", ""},
+		{"invalid second", "t = 00:00:60", ""},
+		{"invalid offset", "odt = 1979-05-27T00:32:00+24:00", ""},
+		{"missing seconds", "t = 07:32", ""},
+		{"integers", "a = [+1_000, -0, 0xdead_BEEF, 0o17, 0b101]",
+			`key "a" at 1:1, = at 1:3, [ at 1:5, integer 1000 at 1:6, , at 1:12, integer 0 at 1:14, , at 1:16, ` +
+				`integer 3735928559 at 1:18, , at 1:29, integer 15 at 1:31, , at 1:35, integer 5 at 1:37, ] at 1:42`},
+		{"floats", "a = [-inf, +inf, nan, 0.1, 1e2, 1_0.0_1E-1]",
+			`key "a" at 1:1, = at 1:3, [ at 1:5, float -Inf at 1:6, , at 1:10, float +Inf at 1:12, , at 1:16, ` +
+				`float NaN at 1:18, , at 1:21, float 0.1 at 1:23, , at 1:26, float 100 at 1:28, , at 1:31, float 1.001 at 1:33, ] at 1:43`},
+		{"leading zero", "a = 01", ""},
+		{"two fractions", "a = 0.1.2", ""},
+		{"double '_'", "a = 1__0", ""},
+		{"trailing '_'", "a = 1_", ""},
+		{"signed hexadecimal", "a = +0x1", ""},
+		{"fraction without digits", "a = 1.", ""},
+		{"integer out of range", "a = 9223372036854775808", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := eztok.TokenizeString(NewTokenizer(), test.input)
+			if test.want == "" {
+				if err == nil {
+					t.Fatalf("tokenizing %q returned %v, want an error", test.input, tomlTestString(toks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizing %q returned error: %v", test.input, err)
+			}
+			if got := tomlTestString(toks); got != test.want {
+				t.Fatalf("tokenizing %q returned:\n%v\nwant:\n%v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizerKeepsComments(t *testing.T) {
+	tizer := NewTokenizer()
+	tizer.KeepComments = true
+	toks, err := eztok.TokenizeString(tizer, "# c\na = 1 #d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `comment "# c" at 1:1, newline "\n" at 1:4, key "a" at 2:1, = at 2:3, integer 1 at 2:5, comment "#d" at 2:7`
+	if got := tomlTestString(toks); got != want {
+		t.Fatalf("Tokenize returned %v, want %v", got, want)
+	}
+}
+
+// Returns a string of the TokenType, Value and Origin line and column of each
+// Token in toks, separated by commas. The Value of a punctuation Token, which
+// is its TokenType, is left out, and any other Value is written as Go syntax.
+func tomlTestString(toks []*eztok.Token) string {
+	strs := make([]string, len(toks))
+	for i, tok := range toks {
+		if tok.Value == string(tok.TokenType) {
+			strs[i] = fmt.Sprintf("%v at %v:%v", tok.TokenType, tok.Origin.LineNum, tok.Origin.ColNum)
+		} else {
+			strs[i] = fmt.Sprintf("%v %#v at %v:%v", tok.TokenType, tok.Value, tok.Origin.LineNum, tok.Origin.ColNum)
+		}
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,408 @@
+package eztok
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The name of the LexerMode that holds the rules declared before any mode
+// header in a lexer spec.
+const LexerSpecDefaultMode = "main"
+
+// Represents what a LexerRule does with the runes it matches.
+type LexerRuleKind int
+
+// LexerRuleKind definitions.
+const (
+	// The matched runes generate no Token.
+	LexerRuleSkip LexerRuleKind = iota
+	// The rule matches a literal string, and generates a Token whose Value
+	// is that string.
+	LexerRuleLiteral
+	// The rule matches a regular expression, and generates a Token whose
+	// Value is the matched string.
+	LexerRuleRegex
+)
+
+// Represents how a LexerRule changes the current LexerMode once it matches.
+type LexerAction int
+
+// LexerAction definitions.
+const (
+	// The current LexerMode is unchanged.
+	LexerActionNone LexerAction = iota
+	// LexerRule.PushMode is pushed onto the stack of modes, becoming the
+	// current LexerMode.
+	LexerActionPush
+	// The current LexerMode is popped from the stack of modes, returning to
+	// the LexerMode that pushed it.
+	LexerActionPop
+)
+
+// Represents a single rule of a LexerSpec.
+type LexerRule struct {
+	Kind LexerRuleKind
+	// The TokenType of the generated Token objects. Empty for LexerRuleSkip.
+	TokenType TokenType
+	// The literal string matched by a LexerRuleLiteral, or the regular
+	// expression matched by any other LexerRule.
+	Pattern string
+	Action  LexerAction
+	// The name of the LexerMode pushed by LexerActionPush.
+	PushMode string
+	// Where the LexerRule was declared in the spec.
+	Origin *Origin
+}
+
+// Returns the regular expression matched by the LexerRule.
+func (rule *LexerRule) Regex() string {
+	if rule.Kind == LexerRuleLiteral {
+		return regexp.QuoteMeta(rule.Pattern)
+	}
+	return rule.Pattern
+}
+
+// Represents a named set of LexerRule objects that are active together.
+type LexerMode struct {
+	Name string
+	// The rules of the mode, in the order they were declared. Where several
+	// rules match, the longest match wins, and ties go to the earliest rule.
+	Rules []*LexerRule
+}
+
+// Represents a tokenizer described by a lexer spec. See ParseLexerSpec.
+type LexerSpec struct {
+	// The modes of the spec, in the order they were declared. The first is
+	// the initial mode.
+	Modes []*LexerMode
+}
+
+// Returns the LexerMode named name, or nil if there is none.
+func (spec *LexerSpec) Mode(name string) *LexerMode {
+	for _, mode := range spec.Modes {
+		if mode.Name == name {
+			return mode
+		}
+	}
+	return nil
+}
+
+// Reads the file at path and parses it as a lexer spec. See ParseLexerSpec.
+func LoadLexerSpec(path string) (*LexerSpec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLexerSpec(string(content), path)
+}
+
+// Parses content as a lexer spec, using name as the Origin.Name of errors
+// and LexerRule.Origin. A lexer spec is line-based, and looks like:
+//
+//	# A '#' starts a comment that runs to the end of the line.
+//	skip     /[ \t\r\n]+/
+//	keywords keyword if else while
+//	literal  plus "+"
+//	regex    identifier /[A-Za-z_][A-Za-z0-9_]*/
+//	literal  quote "\"" push string
+//
+//	[string]
+//	regex    text /[^"\\]+/
+//	literal  quote "\"" pop
+//
+// Each line declares a rule of the current mode, or starts a new mode with a
+// "[name]" header. Rules declared before any header belong to the mode named
+// LexerSpecDefaultMode. The first mode is the initial mode. The rules are:
+//
+//	skip     PATTERN [ACTION]             matches PATTERN, generating no Token
+//	literal  TYPE "text" [ACTION]         matches text, generating a TYPE Token
+//	regex    TYPE /regex/ [ACTION]        matches regex, generating a TYPE Token
+//	keywords TYPE word...                 a literal rule for each word
+//
+// Rule names, TYPEs, MODEs and keywords are words, which are separated by
+// whitespace and must not contain '#', '[', ']', '"' or '/'. A keyword may
+// also be written as a "text". A PATTERN is either a "text" or a /regex/.
+// Strings use Go syntax, and a '/' within a regex is written as "\/". Regexes
+// use the syntax of the regexp package, but must not match the empty string
+// nor use assertions such as '^', '$' or '\b'. An ACTION is either
+// "push MODE", which makes MODE the current mode, or "pop", which returns to
+// the mode that pushed the current mode. Returns an error holding the Origin
+// of the first problem found.
+func ParseLexerSpec(content string, name string) (*LexerSpec, error) {
+	toks, err := lexerSpecTokenizer.Tokenize(NewStringContext(content, name))
+	if err != nil {
+		return nil, err
+	}
+	spec := &LexerSpec{[]*LexerMode{}}
+	var mode *LexerMode
+	for start := 0; start < len(toks); {
+		end := start
+		for end < len(toks) && toks[end].TokenType != lexerSpecTokenTypeNewline {
+			end++
+		}
+		line := toks[start:end]
+		start = end + 1
+		if len(line) <= 0 {
+			continue
+		}
+
+		if line[0].TokenType == lexerSpecTokenTypeOpenBracket {
+			if len(line) != 3 || line[1].TokenType != lexerSpecTokenTypeWord || line[2].TokenType != lexerSpecTokenTypeCloseBracket {
+				return nil, fmt.Errorf("expected a mode header of the form '[name]' at %v", line[0].Origin.ToString())
+			}
+			modeName := line[1].Value.(string)
+			if spec.Mode(modeName) != nil {
+				return nil, fmt.Errorf("duplicate mode '%v' at %v", modeName, line[1].Origin.ToString())
+			}
+			mode = &LexerMode{modeName, []*LexerRule{}}
+			spec.Modes = append(spec.Modes, mode)
+			continue
+		}
+		if mode == nil {
+			mode = &LexerMode{LexerSpecDefaultMode, []*LexerRule{}}
+			spec.Modes = append(spec.Modes, mode)
+		}
+		rules, err := parseLexerSpecRules(line)
+		if err != nil {
+			return nil, err
+		}
+		mode.Rules = append(mode.Rules, rules...)
+	}
+
+	if len(spec.Modes) <= 0 {
+		return nil, fmt.Errorf("expected at least one rule at %v", NewOrigin(name, 1, 1).ToString())
+	}
+	for _, mode := range spec.Modes {
+		for _, rule := range mode.Rules {
+			if rule.Action == LexerActionPush && spec.Mode(rule.PushMode) == nil {
+				return nil, fmt.Errorf("push of unknown mode '%v' at %v", rule.PushMode, rule.Origin.ToString())
+			}
+		}
+	}
+	return spec, nil
+}
+
+// Parses the LexerRule objects declared by a line of a lexer spec.
+func parseLexerSpecRules(line []*Token) ([]*LexerRule, error) {
+	kindTok := line[0]
+	if kindTok.TokenType != lexerSpecTokenTypeWord {
+		return nil, fmt.Errorf("expected a rule or mode header at %v", kindTok.Origin.ToString())
+	}
+	args := line[1:]
+	rule := &LexerRule{Origin: kindTok.Origin}
+	switch kindTok.Value {
+	case "skip":
+		rule.Kind = LexerRuleSkip
+		if len(args) <= 0 || (args[0].TokenType != lexerSpecTokenTypeString && args[0].TokenType != lexerSpecTokenTypeRegex) {
+			return nil, fmt.Errorf("expected a \"text\" or /regex/ pattern after 'skip' at %v", kindTok.Origin.ToString())
+		}
+		rule.Pattern = args[0].Value.(string)
+		if args[0].TokenType == lexerSpecTokenTypeString {
+			rule.Pattern = regexp.QuoteMeta(rule.Pattern)
+		}
+	case "literal", "regex", "keywords":
+		if len(args) <= 0 || args[0].TokenType != lexerSpecTokenTypeWord {
+			return nil, fmt.Errorf("expected a token type after '%v' at %v", kindTok.Value, kindTok.Origin.ToString())
+		}
+		rule.TokenType = TokenType(args[0].Value.(string))
+		if kindTok.Value == "keywords" {
+			return parseLexerSpecKeywords(rule, args[1:])
+		}
+		patternType, patternDesc := lexerSpecTokenTypeString, "\"text\""
+		rule.Kind = LexerRuleLiteral
+		if kindTok.Value == "regex" {
+			patternType, patternDesc = lexerSpecTokenTypeRegex, "/regex/"
+			rule.Kind = LexerRuleRegex
+		}
+		if len(args) <= 1 || args[1].TokenType != patternType {
+			return nil, fmt.Errorf("expected a %v pattern after the token type at %v", patternDesc, args[0].Origin.ToString())
+		}
+		rule.Pattern = args[1].Value.(string)
+		args = args[1:]
+	default:
+		return nil, fmt.Errorf("unknown rule '%v' (must be one of 'skip', 'literal', 'regex', 'keywords') at %v",
+			kindTok.Value, kindTok.Origin.ToString())
+	}
+	if err := validateLexerRulePattern(rule, args[0].Origin); err != nil {
+		return nil, err
+	}
+
+	action := args[1:]
+	if len(action) > 0 && action[0].TokenType == lexerSpecTokenTypeWord && action[0].Value == "pop" {
+		rule.Action = LexerActionPop
+		action = action[1:]
+	} else if len(action) > 0 && action[0].TokenType == lexerSpecTokenTypeWord && action[0].Value == "push" {
+		if len(action) <= 1 || action[1].TokenType != lexerSpecTokenTypeWord {
+			return nil, fmt.Errorf("expected a mode name after 'push' at %v", action[0].Origin.ToString())
+		}
+		rule.Action = LexerActionPush
+		rule.PushMode = action[1].Value.(string)
+		action = action[2:]
+	}
+	if len(action) > 0 {
+		return nil, fmt.Errorf("unexpected '%v' (expected 'push MODE', 'pop' or the end of the line) at %v",
+			action[0].Value, action[0].Origin.ToString())
+	}
+	return []*LexerRule{rule}, nil
+}
+
+// Parses the words of a keywords rule into a LexerRuleLiteral per word, each
+// a copy of rule.
+func parseLexerSpecKeywords(rule *LexerRule, words []*Token) ([]*LexerRule, error) {
+	if len(words) <= 0 {
+		return nil, fmt.Errorf("expected at least one keyword after the token type at %v", rule.Origin.ToString())
+	}
+	rules := make([]*LexerRule, len(words))
+	for i, word := range words {
+		if word.TokenType != lexerSpecTokenTypeWord && word.TokenType != lexerSpecTokenTypeString {
+			return nil, fmt.Errorf("expected a keyword at %v", word.Origin.ToString())
+		}
+		keywordRule := *rule
+		keywordRule.Kind = LexerRuleLiteral
+		keywordRule.Pattern = word.Value.(string)
+		if err := validateLexerRulePattern(&keywordRule, word.Origin); err != nil {
+			return nil, err
+		}
+		rules[i] = &keywordRule
+	}
+	return rules, nil
+}
+
+// Returns an error holding origin if the pattern of rule is empty, invalid,
+// uses an assertion, or matches the empty string.
+func validateLexerRulePattern(rule *LexerRule, origin *Origin) error {
+	if rule.Kind == LexerRuleLiteral {
+		if len(rule.Pattern) <= 0 {
+			return fmt.Errorf("empty literal at %v", origin.ToString())
+		}
+		return nil
+	}
+	re, err := syntax.Parse(rule.Pattern, syntax.Perl)
+	if err != nil {
+		return fmt.Errorf("invalid regex /%v/: %v at %v", rule.Pattern, err, origin.ToString())
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return fmt.Errorf("invalid regex /%v/: %v at %v", rule.Pattern, err, origin.ToString())
+	}
+	for _, inst := range prog.Inst {
+		if inst.Op == syntax.InstEmptyWidth {
+			return fmt.Errorf("regex /%v/ uses an assertion, which is not supported at %v", rule.Pattern, origin.ToString())
+		}
+	}
+	if regexp.MustCompile(`^(?:` + rule.Pattern + `)$`).MatchString("") {
+		return fmt.Errorf("regex /%v/ matches the empty string at %v", rule.Pattern, origin.ToString())
+	}
+	return nil
+}
+
+// TokenType definitions of the tokens of a lexer spec.
+const (
+	lexerSpecTokenTypeNewline      TokenType = "newline"
+	lexerSpecTokenTypeOpenBracket  TokenType = "["
+	lexerSpecTokenTypeCloseBracket TokenType = "]"
+	lexerSpecTokenTypeWord         TokenType = "word"
+	lexerSpecTokenTypeString       TokenType = "string"
+	lexerSpecTokenTypeRegex        TokenType = "regex"
+)
+
+// The Tokenizer used to tokenize a lexer spec.
+var lexerSpecTokenizer = NewInOrderNodeTokenizer(
+	// Horizontal whitespace
+	NewCallbackNode(
+		func(ctx Context) bool {
+			return isHorizontalSpace(ctx.PeekRune(0))
+		},
+		func(ctx Context) (*Token, error) {
+			ReadRunesUntilNot(ctx, isHorizontalSpace)
+			return nil, nil
+		},
+	),
+	// Comments
+	NewCallbackNode(
+		func(ctx Context) bool {
+			return ctx.PeekRune(0) == '#'
+		},
+		func(ctx Context) (*Token, error) {
+			ReadRunesUntil(ctx, isLineBreakRune)
+			return nil, nil
+		},
+	),
+	NewCallbackNode(
+		func(ctx Context) bool {
+			return isLineBreakRune(ctx.PeekRune(0))
+		},
+		func(ctx Context) (*Token, error) {
+			ctx.NextRune()
+			return NewToken(lexerSpecTokenTypeNewline, nil), nil
+		},
+	),
+	NewRuneMatchNode(lexerSpecTokenTypeOpenBracket, '['),
+	NewRuneMatchNode(lexerSpecTokenTypeCloseBracket, ']'),
+	// Strings, using Go syntax
+	NewCallbackNode(
+		func(ctx Context) bool {
+			return ctx.PeekRune(0) == '"'
+		},
+		func(ctx Context) (*Token, error) {
+			quoted := string(ctx.NextRune())
+			escaped := false
+			for !IsEOF(ctx) && !isLineBreakRune(ctx.PeekRune(0)) {
+				r := ctx.NextRune()
+				quoted += string(r)
+				if r == '"' && !escaped {
+					str, err := strconv.Unquote(quoted)
+					if err != nil {
+						return nil, fmt.Errorf("invalid string %v: %v", quoted, err)
+					}
+					return NewToken(lexerSpecTokenTypeString, str), nil
+				}
+				escaped = r == '\\' && !escaped
+			}
+			return nil, fmt.Errorf("unterminated string %v", quoted)
+		},
+	),
+	// Regexes, in which "\/" is a '/'
+	NewCallbackNode(
+		func(ctx Context) bool {
+			return ctx.PeekRune(0) == '/'
+		},
+		func(ctx Context) (*Token, error) {
+			ctx.NextRune()
+			pattern := strings.Builder{}
+			for !IsEOF(ctx) && !isLineBreakRune(ctx.PeekRune(0)) {
+				r := ctx.NextRune()
+				if r == '/' {
+					return NewToken(lexerSpecTokenTypeRegex, pattern.String()), nil
+				}
+				if r == '\\' && ctx.PeekRune(0) == '/' {
+					r = ctx.NextRune()
+				} else if r == '\\' && !IsEOF(ctx) && !isLineBreakRune(ctx.PeekRune(0)) {
+					pattern.WriteRune(r)
+					r = ctx.NextRune()
+				}
+				pattern.WriteRune(r)
+			}
+			return nil, fmt.Errorf("unterminated regex /%v", pattern.String())
+		},
+	),
+	// Words, such as rule names, token types and mode names
+	NewCallbackNode(
+		func(ctx Context) bool {
+			return isLexerSpecWordRune(ctx.PeekRune(0))
+		},
+		func(ctx Context) (*Token, error) {
+			return NewToken(lexerSpecTokenTypeWord, ReadRunesUntilNot(ctx, isLexerSpecWordRune)), nil
+		},
+	),
+)
+
+// Returns true if r may be part of a word in a lexer spec.
+func isLexerSpecWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !isLineBreakRune(r) && r != NilRune && !strings.ContainsRune("#[]\"/", r)
+}
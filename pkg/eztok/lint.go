@@ -0,0 +1,147 @@
+package eztok
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// Represents a problem with the configuration of a tokenizer, found by
+// InOrderNodeTokenizer.Lint.
+type LintIssue struct {
+	// The index of the Node the issue is about.
+	Index int
+	// The indices of the Node objects before it that cause the issue, in
+	// ascending order.
+	ShadowedBy []int
+	// A description of the issue.
+	Message string
+	// A suggestion of how to resolve the issue.
+	Suggestion string
+}
+
+// Returns a string representation of the LintIssue containing its Message and
+// Suggestion.
+func (issue *LintIssue) ToString() string {
+	return fmt.Sprintf("%v (%v)", issue.Message, issue.Suggestion)
+}
+
+// Returns the issues found with the order of InOrderNodeTokenizer.Nodes. An
+// issue is found for each Node that can never parse a Token, since wherever it
+// could, a Node before it parses a Token instead. Such as a NewStringMatchNode
+// for "float" placed after IdentifierNode, or one for "==" placed after one
+// for "=". Only Node objects that are a PatternNode are analysed.
+func (tizer *InOrderNodeTokenizer) Lint() []*LintIssue {
+	dfa, ruleNodes, _, err := compileNodePatterns(tizer.Nodes)
+	if err != nil {
+		// The nodes are too complex to be analysed.
+		return []*LintIssue{}
+	}
+	issues := []*LintIssue{}
+	for _, shadowed := range findShadowedNodes(dfa, ruleNodes) {
+		issue := &LintIssue{Index: shadowed.Index, ShadowedBy: shadowed.ShadowedBy}
+		name := describeNode(tizer.Nodes[shadowed.Index], shadowed.Index)
+		if len(shadowed.ShadowedBy) <= 0 {
+			issue.Message = fmt.Sprintf("%v can never parse a token, since its pattern matches no runes", name)
+			issue.Suggestion = "remove it, or fix its pattern"
+			issues = append(issues, issue)
+			continue
+		}
+
+		shadowers := make([]string, len(shadowed.ShadowedBy))
+		for i, index := range shadowed.ShadowedBy {
+			shadowers[i] = describeNode(tizer.Nodes[index], index)
+		}
+		issue.Message = fmt.Sprintf("%v can never parse a token, since %v always parses one first",
+			name, strings.Join(shadowers, " or "))
+		first := shadowed.ShadowedBy[0]
+		literal, isLiteral := nodeLiteral(tizer.Nodes[shadowed.Index])
+		switch {
+		case isLiteral && allLiteralPrefixes(tizer.Nodes, shadowed.ShadowedBy, literal):
+			issue.Suggestion = fmt.Sprintf("move it before node %v, so that the longer literal is tried first", first)
+		case isLiteral:
+			issue.Suggestion = fmt.Sprintf("move it before node %v, so that the literal is tried first", first)
+		default:
+			issue.Suggestion = fmt.Sprintf("move it before node %v, or remove it", first)
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// Returns an error describing each issue found by InOrderNodeTokenizer.Lint, or
+// nil if there are none.
+func (tizer *InOrderNodeTokenizer) Validate() error {
+	issues := tizer.Lint()
+	if len(issues) <= 0 {
+		return nil
+	}
+	descriptions := make([]string, len(issues))
+	for i, issue := range issues {
+		descriptions[i] = issue.ToString()
+	}
+	return fmt.Errorf("invalid node order: %v", strings.Join(descriptions, "; "))
+}
+
+// Returns a description of node, which is at index within the Nodes of a
+// tokenizer.
+func describeNode(node Node, index int) string {
+	if callbackNode, ok := node.(*CallbackNode); ok {
+		switch Node(callbackNode) {
+		case SkipWhitespaceNode:
+			return fmt.Sprintf("SkipWhitespaceNode (node %v)", index)
+		case IdentifierNode:
+			return fmt.Sprintf("IdentifierNode (node %v)", index)
+		case NumberNode:
+			return fmt.Sprintf("NumberNode (node %v)", index)
+		case DoubleQuotedEscapedStringNode:
+			return fmt.Sprintf("DoubleQuotedEscapedStringNode (node %v)", index)
+		case SingleQuotedEscapedStringNode:
+			return fmt.Sprintf("SingleQuotedEscapedStringNode (node %v)", index)
+		case PreprocessorDirectiveNode:
+			return fmt.Sprintf("PreprocessorDirectiveNode (node %v)", index)
+		}
+	}
+	if literal, ok := nodeLiteral(node); ok {
+		return fmt.Sprintf("node %v matching '%v'", index, literal)
+	}
+	if patternNode, ok := node.(PatternNode); ok {
+		pattern, _ := patternNode.CanParsePattern()
+		return fmt.Sprintf("node %v matching /%v/", index, pattern)
+	}
+	return fmt.Sprintf("node %v", index)
+}
+
+// Returns the literal string matched by the pattern of node, and true if its
+// pattern only matches a literal string.
+func nodeLiteral(node Node) (string, bool) {
+	patternNode, ok := node.(PatternNode)
+	if !ok {
+		return "", false
+	}
+	pattern, ok := patternNode.CanParsePattern()
+	if !ok {
+		return "", false
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	re = re.Simplify()
+	if re.Op == syntax.OpLiteral && re.Flags&syntax.FoldCase == 0 {
+		return string(re.Rune), true
+	}
+	return "", false
+}
+
+// Returns true if the Node at each of indices matches a literal prefix of
+// literal.
+func allLiteralPrefixes(nodes []Node, indices []int, literal string) bool {
+	for _, index := range indices {
+		prefix, ok := nodeLiteral(nodes[index])
+		if !ok || !strings.HasPrefix(literal, prefix) {
+			return false
+		}
+	}
+	return true
+}
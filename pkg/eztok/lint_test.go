@@ -0,0 +1,117 @@
+package eztok
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []Node
+		// Each issue returned, as its Index, ShadowedBy and ToString.
+		want []string
+	}{
+		{"correct order", []Node{SkipWhitespaceNode, NewStringMatchNode("==", "=="), NewRuneMatchNode("=", '='),
+			NewStringMatchNode("float", "float"), IdentifierNode, NumberNode}, nil},
+		{"float after NumberNode", []Node{NumberNode, NewRegexMatchNode("float", `[0-9]+\.[0-9]+`)}, []string{
+			`1 [0]: node 1 matching /[0-9]+\.[0-9]+/ can never parse a token, since NumberNode (node 0) always parses one first ` +
+				`(move it before node 0, or remove it)`}},
+		{"float after integer", []Node{NewRegexMatchNode("int", `[0-9]+`), NewRegexMatchNode("float", `[0-9]+\.[0-9]+`)}, []string{
+			`1 [0]: node 1 matching /[0-9]+\.[0-9]+/ can never parse a token, since node 0 matching /[0-9]+/ always parses one first ` +
+				`(move it before node 0, or remove it)`}},
+		// A float beginning with '.' is not matched by the integer node.
+		{"float with optional integer part", []Node{NewRegexMatchNode("int", `[0-9]+`), NewRegexMatchNode("float", `[0-9]*\.[0-9]+`)}, nil},
+		{"float literal after IdentifierNode", []Node{IdentifierNode, NewStringMatchNode("float", "float")}, []string{
+			`1 [0]: node 1 matching 'float' can never parse a token, since IdentifierNode (node 0) always parses one first ` +
+				`(move it before node 0, so that the literal is tried first)`}},
+		{"'=' before '=='", []Node{NewRuneMatchNode("=", '='), NewStringMatchNode("==", "==")}, []string{
+			`1 [0]: node 1 matching '==' can never parse a token, since node 0 matching '=' always parses one first ` +
+				`(move it before node 0, so that the longer literal is tried first)`}},
+		{"several shadowed literals", []Node{NewRuneMatchNode("<", '<'), NewRuneMatchNode("=", '='),
+			NewStringMatchNode("<=", "<="), NewStringMatchNode("=<", "=<")}, []string{
+			`2 [0]: node 2 matching '<=' can never parse a token, since node 0 matching '<' always parses one first ` +
+				`(move it before node 0, so that the longer literal is tried first)`,
+			`3 [1]: node 3 matching '=<' can never parse a token, since node 1 matching '=' always parses one first ` +
+				`(move it before node 1, so that the longer literal is tried first)`}},
+		{"shadowed by several nodes", []Node{NewRegexMatchNode("int", `[0-9]+`), IdentifierNode, NewRegexMatchNode("word", `[a-z0-9]+`)}, []string{
+			`2 [0 1]: node 2 matching /[a-z0-9]+/ can never parse a token, since node 0 matching /[0-9]+/ or IdentifierNode (node 1) ` +
+				`always parses one first (move it before node 0, or remove it)`}},
+		{"case-insensitive keyword after IdentifierNode", []Node{IdentifierNode, NewRegexMatchNode("if", `(?i)if`)}, []string{
+			`1 [0]: node 1 matching /(?i)if/ can never parse a token, since IdentifierNode (node 0) always parses one first ` +
+				`(move it before node 0, or remove it)`}},
+		{"pattern matching no runes", []Node{SkipWhitespaceNode, NewRegexMatchNode("none", `[^\x00-\x{10FFFF}]`)}, []string{
+			`1 []: node 1 matching /[^\x00-\x{10FFFF}]/ can never parse a token, since its pattern matches no runes ` +
+				`(remove it, or fix its pattern)`}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tizer := NewInOrderNodeTokenizer(test.nodes...)
+			got, descriptions := []string{}, []string{}
+			for _, issue := range tizer.Lint() {
+				got = append(got, fmt.Sprintf("%v %v: %v", issue.Index, issue.ShadowedBy, issue.ToString()))
+				descriptions = append(descriptions, issue.ToString())
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Fatalf("Lint returned:\n%v\nwant:\n%v", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+			err := tizer.Validate()
+			if len(test.want) <= 0 {
+				if err != nil {
+					t.Fatalf("Validate returned error %v, want nil", err)
+				}
+				return
+			}
+			if want := "invalid node order: " + strings.Join(descriptions, "; "); err == nil || err.Error() != want {
+				t.Fatalf("Validate returned error %v, want %v", err, want)
+			}
+		})
+	}
+}
+
+// Checks that moving each shadowed Node where Lint suggests resolves its issue.
+func TestLintSuggestedReorderings(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []Node
+		// The index within nodes of each Node once reordered.
+		want []int
+	}{
+		{"float after integer", []Node{NewRegexMatchNode("int", `[0-9]+`), NewRegexMatchNode("float", `[0-9]+\.[0-9]+`)},
+			[]int{1, 0}},
+		{"keyword after IdentifierNode", []Node{SkipWhitespaceNode, IdentifierNode, NewStringMatchNode("if", "if")},
+			[]int{0, 2, 1}},
+		{"operators", []Node{NewRuneMatchNode("<", '<'), NewRuneMatchNode("=", '='),
+			NewStringMatchNode("<=", "<="), NewStringMatchNode("==", "=="), NewStringMatchNode("<==", "<==")},
+			[]int{4, 2, 0, 3, 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tizer := NewInOrderNodeTokenizer(append([]Node{}, test.nodes...)...)
+			for moves := 0; ; moves++ {
+				issues := tizer.Lint()
+				if len(issues) <= 0 {
+					break
+				}
+				if moves >= len(test.nodes) {
+					t.Fatalf("Lint still returns %v after %v moves", issues[0].ToString(), moves)
+				}
+				// Move the Node of the first issue before the first Node
+				// shadowing it.
+				from, to := issues[0].Index, issues[0].ShadowedBy[0]
+				node := tizer.Nodes[from]
+				copy(tizer.Nodes[to+1:from+1], tizer.Nodes[to:from])
+				tizer.Nodes[to] = node
+			}
+			if err := tizer.Validate(); err != nil {
+				t.Fatalf("Validate returned error %v after reordering, want nil", err)
+			}
+			for i, index := range test.want {
+				if tizer.Nodes[i] != test.nodes[index] {
+					t.Fatalf("node %v is not the node at %v of the original order, want order %v", i, index, test.want)
+				}
+			}
+		})
+	}
+}
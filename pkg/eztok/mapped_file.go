@@ -0,0 +1,94 @@
+package eztok
+
+import "os"
+
+// A file whose contents are exposed as a byte slice. On Linux the file is
+// memory-mapped, so its contents are never copied, and are read from disk as
+// they are used. Elsewhere the file is read into memory in full. Note that
+// creating a ByteContext over the contents (such as by MappedFile.Context)
+// reads them in full, to check whether they are entirely ASCII.
+type MappedFile struct {
+	// The path of the file.
+	path string
+	// The contents of the file. Nil once the MappedFile is closed.
+	data []byte
+	// True if data is memory-mapped and must be unmapped by Close.
+	mapped bool
+}
+
+// Returns a new MappedFile holding the contents of the file at path. The
+// MappedFile must be closed once its contents are no longer needed.
+func OpenMappedFile(path string) (*MappedFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= 0 {
+		return &MappedFile{path, []byte{}, false}, nil
+	}
+	data, mapped, err := mapFile(file, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	return &MappedFile{path, data, mapped}, nil
+}
+
+// Returns the contents of the MappedFile. The returned slice, and any slice of
+// it, must not be used after the MappedFile is closed.
+func (file *MappedFile) Bytes() []byte {
+	return file.data
+}
+
+// Returns a new ByteContext over the contents of the MappedFile, whose
+// Origin.Name is the path of the MappedFile. Nodes can call ByteContext.Slice
+// to reference the contents without copying. See NewByteContext.
+func (file *MappedFile) Context() *ByteContext {
+	return NewByteContext(file.data, file.path)
+}
+
+// Releases the contents of the MappedFile. Calling Close more than once has
+// no effect.
+func (file *MappedFile) Close() error {
+	data, mapped := file.data, file.mapped
+	file.data, file.mapped = nil, false
+	if mapped {
+		return unmapFile(data)
+	}
+	return nil
+}
+
+// Holds the result of TokenizeFileMapped. Token values that reference the
+// mapped file's contents remain valid until Close is called.
+type MappedTokens struct {
+	// The Token objects generated from the file.
+	Tokens []*Token
+	// The file the Token objects were generated from.
+	File *MappedFile
+}
+
+// Releases the file the Token objects were generated from.
+func (result *MappedTokens) Close() error {
+	return result.File.Close()
+}
+
+// Tokenize the contents of the file at path using the provided Tokenizer, by
+// exposing the file as a ByteContext over a MappedFile. The Origin.Name of the
+// Token objects generated will be path. The returned MappedTokens must be
+// closed once its Token objects are no longer needed.
+func TokenizeFileMapped(tokenizer Tokenizer, path string) (*MappedTokens, error) {
+	file, err := OpenMappedFile(path)
+	if err != nil {
+		return nil, err
+	}
+	toks, err := tokenizer.Tokenize(file.Context())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &MappedTokens{toks, file}, nil
+}
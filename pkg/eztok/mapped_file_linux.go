@@ -0,0 +1,23 @@
+//go:build linux
+
+package eztok
+
+import (
+	"os"
+	"syscall"
+)
+
+// Memory-maps the first size bytes of file as read-only. Returns the mapped
+// bytes, and true since they must be unmapped.
+func mapFile(file *os.File, size int64) ([]byte, bool, error) {
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, false, &os.PathError{Op: "mmap", Path: file.Name(), Err: err}
+	}
+	return data, true, nil
+}
+
+// Unmaps bytes returned by mapFile.
+func unmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,33 @@
+//go:build linux
+
+package eztok
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMappedFileIsMapped(t *testing.T) {
+	path := writeMappedTestFile(t, "abc")
+	file, err := OpenMappedFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if !file.mapped {
+		t.Fatalf("the MappedFile was not memory-mapped")
+	}
+	// The mapping shares the file's pages, so a write to the file is seen
+	// without reopening it.
+	osFile, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer osFile.Close()
+	if _, err := osFile.WriteAt([]byte("x"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(file.Bytes()); got != "axc" {
+		t.Fatalf("Bytes() = %q after writing to the file, want %q", got, "axc")
+	}
+}
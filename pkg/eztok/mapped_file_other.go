@@ -0,0 +1,23 @@
+//go:build !linux
+
+package eztok
+
+import (
+	"io"
+	"os"
+)
+
+// Reads the first size bytes of file into memory. Returns the read bytes, and
+// false since they do not need to be unmapped.
+func mapFile(file *os.File, size int64) ([]byte, bool, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(file, data); err != nil {
+		return nil, false, err
+	}
+	return data, false, nil
+}
+
+// Never called, since mapFile never returns mapped bytes.
+func unmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,27 @@
+//go:build !linux
+
+package eztok
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMappedFileIsRead(t *testing.T) {
+	path := writeMappedTestFile(t, "abc")
+	file, err := OpenMappedFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if file.mapped {
+		t.Fatalf("the MappedFile was memory-mapped")
+	}
+	// The contents were read into memory, so a write to the file is not seen.
+	if err := os.WriteFile(path, []byte("axc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(file.Bytes()); got != "abc" {
+		t.Fatalf("Bytes() = %q after writing to the file, want %q", got, "abc")
+	}
+}
@@ -0,0 +1,72 @@
+package eztok
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Writes content to a new file within a temporary directory, and returns its
+// path.
+func writeMappedTestFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMappedFile(t *testing.T) {
+	for _, content := range []string{"ab cd\néf", ""} {
+		path := writeMappedTestFile(t, content)
+		file, err := OpenMappedFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(file.Bytes()); got != content {
+			t.Fatalf("Bytes() = %q, want %q", got, content)
+		}
+		ctx := file.Context()
+		if got := ReadRunesUntil(ctx, func(r rune) bool { return false }); got != content {
+			t.Fatalf("Context() read %q, want %q", got, content)
+		}
+		if name := ctx.GetNextOrigin().Name; name != path {
+			t.Fatalf("Context() has Origin.Name %q, want %q", name, path)
+		}
+		for i := 0; i < 2; i++ {
+			if err := file.Close(); err != nil {
+				t.Fatalf("Close() call %v returned error: %v", i+1, err)
+			}
+		}
+		if file.Bytes() != nil {
+			t.Fatalf("Bytes() = %q after Close, want nil", file.Bytes())
+		}
+	}
+}
+
+func TestOpenMappedFileMissing(t *testing.T) {
+	if _, err := OpenMappedFile(filepath.Join(t.TempDir(), "missing.txt")); !os.IsNotExist(err) {
+		t.Fatalf("OpenMappedFile returned error %v, want a missing file", err)
+	}
+}
+
+func TestTokenizeFileMapped(t *testing.T) {
+	path := writeMappedTestFile(t, "ab\n cd")
+	tizer := NewInOrderNodeTokenizer(SkipWhitespaceNode, IdentifierNode)
+	result, err := TokenizeFileMapped(tizer, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Close()
+	if len(result.Tokens) != 2 || result.Tokens[1].Value != "cd" {
+		t.Fatalf("TokenizeFileMapped returned %v Token objects, want ab and cd", len(result.Tokens))
+	}
+	if origin := result.Tokens[1].Origin; origin.Name != path || origin.LineNum != 2 || origin.ColNum != 2 {
+		t.Fatalf("Origin = %v, want %v:2:2", origin.ToString(), path)
+	}
+
+	if _, err := TokenizeFileMapped(tizer, writeMappedTestFile(t, "ab +")); err == nil {
+		t.Fatalf("TokenizeFileMapped returned no error for an unexpected rune")
+	}
+}
@@ -3,13 +3,16 @@ package eztok
 import (
 	"fmt"
 	"log"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Returns a new CallbackNode whose CanParseToken function returns true if
 // Context.PeekRune(0) equals runeValue and whose ParseToken function returns a
 // Token with a TokenType of tokenType and a Value of runeValue.
 func NewRuneMatchNode(tokenType TokenType, runeValue rune) *CallbackNode {
-	return NewCallbackNode(
+	node := NewCallbackNode(
 		func(ctx Context) bool {
 			return ctx.PeekRune(0) == runeValue
 		},
@@ -21,6 +24,9 @@ func NewRuneMatchNode(tokenType TokenType, runeValue rune) *CallbackNode {
 			return NewToken(tokenType, runeValue), nil
 		},
 	)
+	node.canParsePattern = regexp.QuoteMeta(string(runeValue))
+	node.firstRunes = []*unicode.RangeTable{newRuneTable(runeValue)}
+	return node
 }
 
 // Returns a new CallbackNode whose CanParseToken function returns true if
@@ -30,23 +36,21 @@ func NewStringMatchNode(tokenType TokenType, value string) *CallbackNode {
 	if len(value) <= 0 {
 		log.Panicf("Cannot create a NewStringMatchNode with an empty string to match on.")
 	}
-	return NewCallbackNode(
+	runeCount := utf8.RuneCountInString(value)
+	node := NewCallbackNode(
 		func(ctx Context) bool {
-			// Peek full length of value first, to better cache for some context implementations
-			for i := len(value) - 1; i >= 0; i-- {
-				if ctx.PeekRune(i) != rune(value[i]) {
-					return false
-				}
-			}
-			return true
+			return HasPrefix(ctx, value)
 		},
 		func(ctx Context) (*Token, error) {
-			for i := 0; i < len(value); i++ {
-				if r := ctx.NextRune(); r != rune(value[i]) {
-					return nil, fmt.Errorf("expected word '%v' but got mismatched rune '%c' at position %v", value, r, i)
-				}
+			if !HasPrefix(ctx, value) {
+				return nil, fmt.Errorf("expected word '%v' but got '%v'", value, PeekString(ctx, runeCount))
 			}
+			SkipRunes(ctx, runeCount)
 			return NewToken(tokenType, value), nil
 		},
 	)
+	node.canParsePattern = regexp.QuoteMeta(value)
+	firstRune, _ := utf8.DecodeRuneInString(value)
+	node.firstRunes = []*unicode.RangeTable{newRuneTable(firstRune)}
+	return node
 }
@@ -0,0 +1,226 @@
+package eztok
+
+import (
+	"io"
+	"log"
+	"strings"
+	"unicode/utf8"
+)
+
+// The shared implementation of StringContext and ByteContext, which index
+// directly into their in-memory input.
+type memoryContext struct {
+	// Returns the rune beginning at the given byte offset of the input,
+	// and its size in bytes.
+	decode     func(offset int) (rune, int)
+	length     int
+	ascii      bool
+	originName string
+	offset     int
+	position   positionTracker
+	// The byte offsets of the runes that have been peeked, starting with the
+	// current rune, followed by the length of the input once it is reached.
+	// Only used if the input is not entirely ASCII.
+	peeked []int
+}
+
+// Return the rune that is relative runes ahead of the current
+// rune in the input. Returns NilRune if there is none. This is O(1) for any
+// relative, amortized over the runes of the input.
+func (ctx *memoryContext) PeekRune(relative int) rune {
+	if relative < 0 {
+		log.Panicf("PeekRune cannot peek negatively; tried peeking a relative '%v' runes", relative)
+	}
+	r, _ := ctx.peekRune(relative)
+	return r
+}
+
+// Returns the rune that is relative runes ahead of the current rune in the
+// input, and true if there is such a rune.
+func (ctx *memoryContext) peekRune(relative int) (rune, bool) {
+	if offset := ctx.offsetAfter(relative); offset < ctx.length {
+		r, _ := ctx.decode(offset)
+		return r, true
+	}
+	return NilRune, false
+}
+
+// Returns a reader of the runes ahead in the input. See NewPeekRuneReader.
+func (ctx *memoryContext) newPeekRuneReader() io.RuneReader {
+	return &memoryRuneReader{ctx, ctx.offset}
+}
+
+// Consume (i.e. advance the input by 1 rune) and return the
+// consumed rune. Returns NilRune if there is none.
+func (ctx *memoryContext) NextRune() rune {
+	if ctx.offset >= ctx.length {
+		return NilRune
+	}
+	r, size := ctx.decode(ctx.offset)
+	ctx.offset += size
+	ctx.position.advance(r, size)
+	if len(ctx.peeked) > 0 {
+		ctx.peeked = ctx.peeked[1:]
+	}
+	return r
+}
+
+// Returns the Origin information of the rune that would be returned
+// by a call to NextRune().
+func (ctx *memoryContext) GetNextOrigin() *Origin {
+	return ctx.position.origin(ctx.originName)
+}
+
+// Returns true if no runes remain in the input.
+func (ctx *memoryContext) IsEOF() bool {
+	return ctx.offset >= ctx.length
+}
+
+// Returns the byte offset into the input of the rune that would be returned
+// by a call to NextRune().
+func (ctx *memoryContext) Offset() int {
+	return ctx.offset
+}
+
+// Returns the byte offset into the input that is count runes ahead of the
+// current rune, or the length of the input if it holds fewer runes. If the
+// input is not entirely ASCII, the offsets of the runes peeked so far are
+// remembered, so that this is O(1) amortized over the runes of the input.
+func (ctx *memoryContext) offsetAfter(count int) int {
+	if ctx.ascii {
+		if ctx.offset+count >= ctx.length {
+			return ctx.length
+		}
+		return ctx.offset + count
+	}
+	if len(ctx.peeked) <= 0 {
+		ctx.peeked = append(ctx.peeked, ctx.offset)
+	}
+	for len(ctx.peeked) <= count {
+		last := ctx.peeked[len(ctx.peeked)-1]
+		if last >= ctx.length {
+			return ctx.length
+		}
+		_, size := ctx.decode(last)
+		ctx.peeked = append(ctx.peeked, last+size)
+	}
+	return ctx.peeked[count]
+}
+
+// The io.RuneReader returned by memoryContext.newPeekRuneReader.
+type memoryRuneReader struct {
+	ctx    *memoryContext
+	offset int
+}
+
+// Returns the next rune ahead in the input.
+func (reader *memoryRuneReader) ReadRune() (rune, int, error) {
+	if reader.offset >= reader.ctx.length {
+		return 0, 0, io.EOF
+	}
+	r, size := reader.ctx.decode(reader.offset)
+	reader.offset += size
+	return r, runeSize(r), nil
+}
+
+// A Context whose input rune stream is a string held in memory.
+type StringContext struct {
+	memoryContext
+	content string
+}
+
+// Returns a new StringContext with the provided parameters and default
+// PositionOptions. The originName will be used as the Origin.Name for all runes.
+func NewStringContext(content string, originName string) *StringContext {
+	return NewStringContextWithOptions(content, originName, PositionOptions{})
+}
+
+// Returns a new StringContext with the provided parameters. The originName will
+// be used as the Origin.Name for all runes.
+func NewStringContextWithOptions(content string, originName string, options PositionOptions) *StringContext {
+	ascii := true
+	for i := 0; i < len(content) && ascii; i++ {
+		ascii = content[i] < utf8.RuneSelf
+	}
+	ctx := &StringContext{content: content}
+	ctx.memoryContext = memoryContext{ctx.decode, len(content), ascii, originName, 0, newPositionTracker(options), nil}
+	return ctx
+}
+
+// Returns the rune beginning at the given byte offset of the content.
+func (ctx *StringContext) decode(offset int) (rune, int) {
+	if b := ctx.content[offset]; b < utf8.RuneSelf {
+		return rune(b), 1
+	}
+	return utf8.DecodeRuneInString(ctx.content[offset:])
+}
+
+// Returns the content between the byte offsets start and end, without copying.
+func (ctx *StringContext) Slice(start int, end int) string {
+	return ctx.content[start:end]
+}
+
+// Returns true if the next runes in the input equal the runes of prefix. Does
+// not consume any runes.
+func (ctx *StringContext) HasPrefix(prefix string) bool {
+	return strings.HasPrefix(ctx.content[ctx.offset:], prefix)
+}
+
+// Returns the next count runes in the input, or fewer if the input ends
+// first, without copying. Does not consume any runes.
+func (ctx *StringContext) PeekString(count int) string {
+	return ctx.content[ctx.offset:ctx.offsetAfter(count)]
+}
+
+// A Context whose input rune stream is a byte slice held in memory.
+type ByteContext struct {
+	memoryContext
+	content []byte
+}
+
+// Returns a new ByteContext with the provided parameters and default
+// PositionOptions. The originName will be used as the Origin.Name for all runes.
+// The content must not be modified while the ByteContext is in use.
+func NewByteContext(content []byte, originName string) *ByteContext {
+	return NewByteContextWithOptions(content, originName, PositionOptions{})
+}
+
+// Returns a new ByteContext with the provided parameters. The originName will
+// be used as the Origin.Name for all runes. The content must not be modified
+// while the ByteContext is in use. The content is read in full once, to check
+// whether it is entirely ASCII.
+func NewByteContextWithOptions(content []byte, originName string, options PositionOptions) *ByteContext {
+	ascii := true
+	for i := 0; i < len(content) && ascii; i++ {
+		ascii = content[i] < utf8.RuneSelf
+	}
+	ctx := &ByteContext{content: content}
+	ctx.memoryContext = memoryContext{ctx.decode, len(content), ascii, originName, 0, newPositionTracker(options), nil}
+	return ctx
+}
+
+// Returns the rune beginning at the given byte offset of the content.
+func (ctx *ByteContext) decode(offset int) (rune, int) {
+	if b := ctx.content[offset]; b < utf8.RuneSelf {
+		return rune(b), 1
+	}
+	return utf8.DecodeRune(ctx.content[offset:])
+}
+
+// Returns the content between the byte offsets start and end, without copying.
+func (ctx *ByteContext) Slice(start int, end int) []byte {
+	return ctx.content[start:end]
+}
+
+// Returns true if the next runes in the input equal the runes of prefix. Does
+// not consume any runes.
+func (ctx *ByteContext) HasPrefix(prefix string) bool {
+	end := ctx.offset + len(prefix)
+	return end <= ctx.length && string(ctx.content[ctx.offset:end]) == prefix
+}
+
+// Returns the next count runes in the input, or fewer if the input ends
+// first. Does not consume any runes.
+func (ctx *ByteContext) PeekString(count int) string {
+	return string(ctx.content[ctx.offset:ctx.offsetAfter(count)])
+}
@@ -0,0 +1,63 @@
+package eztok
+
+import "testing"
+
+func TestMemoryContextPeekRune(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"ascii", "abc def"},
+		{"multibyte", "héllo, wörld ☃ 𝄞!"},
+		{"invalid utf8", "a\xffb\xe2\x82c"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runes := []rune(test.content)
+			contexts := map[string]Context{
+				"StringContext": NewStringContext(test.content, "test"),
+				"ByteContext":   NewByteContext([]byte(test.content), "test"),
+			}
+			for name, ctx := range contexts {
+				for consumed := 0; consumed <= len(runes); consumed++ {
+					// Peek far ahead first, then near, then far again.
+					for _, relative := range []int{len(runes) + 1, 0, 3, 1, len(runes) - consumed - 1, 2} {
+						if relative < 0 {
+							continue
+						}
+						want := NilRune
+						if consumed+relative < len(runes) {
+							want = runes[consumed+relative]
+						}
+						if got := ctx.PeekRune(relative); got != want {
+							t.Fatalf("%v: PeekRune(%v) after %v runes = %q, want %q",
+								name, relative, consumed, got, want)
+						}
+					}
+					want := NilRune
+					if consumed < len(runes) {
+						want = runes[consumed]
+					}
+					if got := ctx.NextRune(); got != want {
+						t.Fatalf("%v: NextRune() after %v runes = %q, want %q", name, consumed, got, want)
+					}
+				}
+				if !IsEOF(ctx) {
+					t.Fatalf("%v: IsEOF() = false after consuming all runes", name)
+				}
+			}
+		})
+	}
+}
+
+func TestStringContextPeekString(t *testing.T) {
+	ctx := NewStringContext("añb☃c", "test")
+	ctx.NextRune()
+	if got := ctx.PeekString(3); got != "ñb☃" {
+		t.Fatalf("PeekString(3) = %q, want %q", got, "ñb☃")
+	}
+	if got := ctx.PeekString(10); got != "ñb☃c" {
+		t.Fatalf("PeekString(10) = %q, want %q", got, "ñb☃c")
+	}
+}
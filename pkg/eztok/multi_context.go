@@ -0,0 +1,149 @@
+package eztok
+
+import (
+	"bufio"
+	"io"
+	"log"
+)
+
+// Represents a reader of input along with the name used as the Origin.Name of
+// its runes.
+type NamedReader struct {
+	// The name of the input. Often this is the filepath of the input.
+	Name string
+	// The reader of the input.
+	Reader io.Reader
+}
+
+// A Context whose input rune stream is the concatenation of several named
+// readers. Each reader has its own Origin information, so Origin.Name switches
+// and Origin.LineNum/Origin.ColNum reset at each boundary between readers.
+type MultiContext struct {
+	// A ReaderContext per reader, in-order.
+	contexts []*ReaderContext
+	// The rune returned between each pair of readers, or NilRune if none.
+	separator rune
+	// The index of the ReaderContext runes are currently consumed from.
+	current int
+	// True once the separator following the current ReaderContext has been
+	// consumed.
+	separatorDone bool
+}
+
+// Returns a new MultiContext with the provided parameters. If separator is
+// not NilRune, it is returned between the runes of each pair of sources, with
+// the Origin of the end of the preceding source. Each source is read with a
+// ReaderContext created with options.
+func NewMultiContext(sources []NamedReader, separator rune, options ReaderContextOptions) *MultiContext {
+	if len(sources) <= 0 {
+		log.Panicf("Cannot create a NewMultiContext with no sources.")
+	}
+	contexts := make([]*ReaderContext, len(sources))
+	for i, source := range sources {
+		contexts[i] = NewReaderContextWithOptions(bufio.NewReader(source.Reader), source.Name, options)
+	}
+	return &MultiContext{contexts, separator, 0, false}
+}
+
+// Return the rune that is relative runes ahead of the current
+// rune in the input, across all sources. Returns NilRune if there is none.
+// If relative is greater than ReaderContextOptions.MaxLookahead, the input
+// stops as if it had ended, and MultiContext.Err returns an error wrapping
+// ErrLookaheadLimit.
+func (ctx *MultiContext) PeekRune(relative int) rune {
+	r, _ := ctx.peekRune(relative)
+	return r
+}
+
+// Returns the rune that is relative runes ahead of the current rune in the
+// input, across all sources, and true if there is such a rune. See
+// MultiContext.PeekRune.
+func (ctx *MultiContext) peekRune(relative int) (rune, bool) {
+	if relative < 0 {
+		log.Panicf("PeekRune cannot peek negatively; tried peeking a relative '%v' runes", relative)
+	}
+	ctx.skipFinishedSources()
+	if relative > ctx.MaxLookahead() {
+		// The current source records the failure, which stops the whole
+		// MultiContext.
+		return ctx.contexts[ctx.current].peekRune(relative)
+	}
+	for i := ctx.current; i < len(ctx.contexts); i++ {
+		source := ctx.contexts[i]
+		if r, ok := source.peekRune(relative); ok {
+			return r, true
+		}
+		relative -= source.ringCount
+		if source.failed() {
+			return NilRune, false
+		}
+		if ctx.hasSeparatorAfter(i) && !(i == ctx.current && ctx.separatorDone) {
+			if relative == 0 {
+				return ctx.separator, true
+			}
+			relative--
+		}
+	}
+	return NilRune, false
+}
+
+// Returns the maximum relative that may be passed to MultiContext.PeekRune.
+func (ctx *MultiContext) MaxLookahead() int {
+	return ctx.contexts[0].MaxLookahead()
+}
+
+// Consume (i.e. advance the input stream by 1 rune) and return the
+// consumed rune. Returns NilRune if there is none.
+func (ctx *MultiContext) NextRune() rune {
+	ctx.skipFinishedSources()
+	source := ctx.contexts[ctx.current]
+	if !source.IsEOF() {
+		return source.NextRune()
+	}
+	if ctx.hasSeparatorAfter(ctx.current) && !ctx.separatorDone && source.Err() == nil {
+		ctx.separatorDone = true
+		return ctx.separator
+	}
+	return NilRune
+}
+
+// Returns the Origin information of the rune that would be returned
+// by a call to NextRune().
+func (ctx *MultiContext) GetNextOrigin() *Origin {
+	ctx.skipFinishedSources()
+	return ctx.contexts[ctx.current].GetNextOrigin()
+}
+
+// Returns true if no runes remain in any source.
+func (ctx *MultiContext) IsEOF() bool {
+	ctx.skipFinishedSources()
+	source := ctx.contexts[ctx.current]
+	return source.IsEOF() && (source.Err() != nil || ctx.separatorDone || !ctx.hasSeparatorAfter(ctx.current))
+}
+
+// Returns the error that stopped the current source from being read, once all
+// runes before the failure have been consumed. Returns nil otherwise. A failed
+// source stops the whole MultiContext.
+func (ctx *MultiContext) Err() error {
+	ctx.skipFinishedSources()
+	return ctx.contexts[ctx.current].Err()
+}
+
+// Returns true if a separator rune follows the source at index.
+func (ctx *MultiContext) hasSeparatorAfter(index int) bool {
+	return ctx.separator != NilRune && index < len(ctx.contexts)-1
+}
+
+// Advances MultiContext.current past each source whose runes, and following
+// separator, have all been consumed. Never advances past the last source or
+// a source that failed.
+func (ctx *MultiContext) skipFinishedSources() {
+	for ctx.current < len(ctx.contexts)-1 {
+		source := ctx.contexts[ctx.current]
+		if !source.IsEOF() || source.Err() != nil || (ctx.hasSeparatorAfter(ctx.current) && !ctx.separatorDone) {
+			return
+		}
+		ctx.current++
+		ctx.separatorDone = false
+	}
+}
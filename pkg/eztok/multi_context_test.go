@@ -0,0 +1,106 @@
+package eztok
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestMultiContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		sources   []string
+		separator rune
+		// The runes of the MultiContext.
+		want string
+		// The Origin before each rune and at the end, as "name:line:col".
+		origins []string
+	}{
+		{"no separator", []string{"ab", "c\nd"}, NilRune, "abc\nd",
+			[]string{"0:1:1", "0:1:2", "1:1:1", "1:1:2", "1:2:1", "1:2:2"}},
+		{"separator", []string{"ab", "cd"}, ';', "ab;cd",
+			[]string{"0:1:1", "0:1:2", "0:1:3", "1:1:1", "1:1:2", "1:1:3"}},
+		{"empty source", []string{"ab", "", "cd"}, NilRune, "abcd",
+			[]string{"0:1:1", "0:1:2", "2:1:1", "2:1:2", "2:1:3"}},
+		{"empty source with separator", []string{"ab", "", "cd"}, ';', "ab;;cd",
+			[]string{"0:1:1", "0:1:2", "0:1:3", "1:1:1", "2:1:1", "2:1:2", "2:1:3"}},
+		{"empty first and last sources", []string{"", "ab", ""}, NilRune, "ab",
+			[]string{"1:1:1", "1:1:2", "2:1:1"}},
+		{"only empty sources", []string{"", ""}, NilRune, "", []string{"1:1:1"}},
+		{"only empty sources with separator", []string{"", ""}, ';', ";", []string{"0:1:1", "1:1:1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sources := make([]NamedReader, len(test.sources))
+			for i, source := range test.sources {
+				sources[i] = NamedReader{string(rune('0' + i)), strings.NewReader(source)}
+			}
+			ctx := NewMultiContext(sources, test.separator, ReaderContextOptions{})
+			runes := []rune(test.want)
+			for consumed := 0; consumed <= len(runes); consumed++ {
+				for relative := 0; relative <= len(runes); relative++ {
+					want := NilRune
+					if consumed+relative < len(runes) {
+						want = runes[consumed+relative]
+					}
+					if got := ctx.PeekRune(relative); got != want {
+						t.Fatalf("PeekRune(%v) after %v runes = %q, want %q", relative, consumed, got, want)
+					}
+				}
+				if got := ctx.GetNextOrigin().ToString(); got != test.origins[consumed] {
+					t.Fatalf("GetNextOrigin() after %v runes = %v, want %v", consumed, got, test.origins[consumed])
+				}
+				if got, want := ctx.IsEOF(), consumed == len(runes); got != want {
+					t.Fatalf("IsEOF() after %v runes = %v, want %v", consumed, got, want)
+				}
+				want := NilRune
+				if consumed < len(runes) {
+					want = runes[consumed]
+				}
+				if got := ctx.NextRune(); got != want {
+					t.Fatalf("NextRune() after %v runes = %q, want %q", consumed, got, want)
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				t.Fatalf("Err() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestMultiContextErr(t *testing.T) {
+	errTest := errors.New("test error")
+	for _, separator := range []rune{NilRune, ';'} {
+		sources := []NamedReader{
+			{"a", strings.NewReader("ab")},
+			{"b", io.MultiReader(strings.NewReader("cd"), iotest.ErrReader(errTest))},
+			{"c", strings.NewReader("ef")},
+		}
+		ctx := NewMultiContext(sources, separator, ReaderContextOptions{})
+		want := "abcd"
+		if separator != NilRune {
+			want = "ab;cd"
+		}
+		if got := ctx.PeekRune(len(want)); got != NilRune {
+			t.Fatalf("PeekRune(%v) = %q past the failed source, want NilRune", len(want), got)
+		}
+		got := strings.Builder{}
+		for !ctx.IsEOF() {
+			if err := ctx.Err(); err != nil {
+				t.Fatalf("Err() after %q = %v, want nil", got.String(), err)
+			}
+			got.WriteRune(ctx.NextRune())
+		}
+		if got.String() != want {
+			t.Fatalf("runes = %q, want %q", got.String(), want)
+		}
+		if r := ctx.NextRune(); r != NilRune {
+			t.Fatalf("NextRune() after the failed source = %q, want NilRune", r)
+		}
+		if err := ctx.Err(); err == nil || err.Error() != "test error at b:1:3" {
+			t.Fatalf("Err() = %v, want \"test error at b:1:3\"", err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package eztok
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
@@ -37,8 +38,12 @@ func IsNumericTokenType(tokenType TokenType) bool {
 	return false
 }
 
+// A regular expression matching any rune for which unicode.IsSpace returns true.
+const whitespacePattern = `[\t\n\v\f\r \x{85}\x{A0}\x{1680}\x{2000}-\x{200A}\x{2028}\x{2029}\x{202F}\x{205F}\x{3000}]`
+
 // A node that matches any whitespace rune and skips it (i.e. generates no Token).
-var SkipWhitespaceNode = NewCallbackNode(
+var SkipWhitespaceNode = NewCallbackNodeWithPattern(
+	whitespacePattern,
 	func(ctx Context) bool {
 		return unicode.IsSpace(ctx.PeekRune(0))
 	},
@@ -52,7 +57,8 @@ var SkipWhitespaceNode = NewCallbackNode(
 
 // A node that matches a C-style identifier. This means a word that begins with either
 // a letter or '_', and is followed by 0 or more letters, digits, or '_'s.
-var IdentifierNode = NewCallbackNode(
+var IdentifierNode = NewCallbackNodeWithPattern(
+	`[\p{L}_]`,
 	func(ctx Context) bool {
 		r := ctx.PeekRune(0)
 		return unicode.IsLetter(r) || r == '_'
@@ -74,7 +80,8 @@ var IdentifierNode = NewCallbackNode(
 // - 0b### (base-2, binary)
 // - 0o### (base-8, octal)
 // - 0x### (base-16, hexadecimal)
-var NumberNode = NewCallbackNode(
+var NumberNode = NewCallbackNodeWithPattern(
+	`[+\-]?\p{Nd}`,
 	func(ctx Context) bool {
 		r := ctx.PeekRune(0)
 		return unicode.IsDigit(r) || ((r == '+' || r == '-') && unicode.IsDigit(ctx.PeekRune(1)))
@@ -139,7 +146,8 @@ var SingleQuotedEscapedStringNode = newStringNode('\'')
 // Returns a CallbackNode that can parse an escaped string encased in
 // the provided quoteRune rune.
 func newStringNode(quoteRune rune) Node {
-	return NewCallbackNode(
+	return NewCallbackNodeWithPattern(
+		regexp.QuoteMeta(string(quoteRune)),
 		func(ctx Context) bool {
 			return ctx.PeekRune(0) == quoteRune
 		},
@@ -148,7 +156,7 @@ func newStringNode(quoteRune rune) Node {
 			str := ""
 			nextIsEscaped := false
 
-			for r := ctx.PeekRune(0); r != NilRune && (nextIsEscaped || r != quoteRune); r = ctx.PeekRune(0) {
+			for !IsEOF(ctx) && (nextIsEscaped || ctx.PeekRune(0) != quoteRune) {
 				check := ctx.NextRune()
 				if nextIsEscaped {
 					replStr, ok := escapedRuneToString[check]
@@ -163,8 +171,7 @@ func newStringNode(quoteRune rune) Node {
 					str += string(check)
 				}
 			}
-			r := ctx.NextRune()
-			if r != quoteRune || nextIsEscaped {
+			if IsEOF(ctx) || ctx.NextRune() != quoteRune || nextIsEscaped {
 				return nil, fmt.Errorf("unterminated string '%v'", str)
 			}
 
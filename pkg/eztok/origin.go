@@ -12,14 +12,51 @@ type Origin struct {
 	LineNum int
 	// The column number of where something was parsed from.
 	ColNum int
+	// The byte offset from the start of the input of where something was
+	// parsed from.
+	Offset int
+	// The Origin of the macro invocation that something was expanded from.
+	// Nil if it was not produced by a macro expansion.
+	ExpandedFrom *Origin
+	// The Origin of the include (such as an '#include' directive) that the
+	// origin's source was included from. Nil if the source was not included.
+	IncludedFrom *Origin
 }
 
 // Returns a new Origin object with the given parameters.
 func NewOrigin(name string, lineNum int, colNum int) *Origin {
-	return &Origin{name, lineNum, colNum}
+	return &Origin{Name: name, LineNum: lineNum, ColNum: colNum}
 }
 
-// Returns an error-friendly String representation of the Origin.
+// Returns an error-friendly String representation of the Origin. If the Origin
+// was produced by an include or a macro expansion, the chain of include and
+// expansion sites follows, such as:
+//
+//	Include2:2:3, included from Include1:3:3, included from <string>:5:2
+//	math.h:3:21, included from <string>:2:2; expanded from <string>:6:9
+//
+// Unlike the "In file included from ..." lines that C compilers print before a
+// diagnostic, the chain follows the Origin on the same line, innermost first,
+// so that the result can be embedded within a single-line error message.
 func (origin Origin) ToString() string {
-	return fmt.Sprintf("%v:%v:%v", origin.Name, origin.LineNum, origin.ColNum)
+	str := fmt.Sprintf("%v:%v:%v", origin.Name, origin.LineNum, origin.ColNum)
+	if origin.IncludedFrom != nil {
+		str += ", included from " + origin.IncludedFrom.ToString()
+	}
+	if origin.ExpandedFrom != nil {
+		str += "; expanded from " + origin.ExpandedFrom.ToString()
+	}
+	return str
+}
+
+// Sets the Origin.IncludedFrom of each Token in tokens to site, for each Token
+// whose Origin is not nil and has no Origin.IncludedFrom yet. This should be
+// called on the Token objects of an included source before they are spliced
+// into the including stream.
+func SetIncludedFrom(tokens []*Token, site *Origin) {
+	for _, tok := range tokens {
+		if tok.Origin != nil && tok.Origin.IncludedFrom == nil {
+			tok.Origin.IncludedFrom = site
+		}
+	}
 }
@@ -0,0 +1,171 @@
+package eztok
+
+import "unicode"
+
+// Represents the unit that Origin.ColNum is counted in.
+type ColumnMode int
+
+// ColumnMode definitions.
+const (
+	// Each rune is one column.
+	ColumnModeRune ColumnMode = iota
+	// Each byte of a rune's UTF-8 encoding is one column.
+	ColumnModeByte
+	// Each UTF-16 code unit of a rune is one column, as used by the
+	// Language Server Protocol.
+	ColumnModeUTF16
+	// Each rune is as many columns as it occupies when displayed, as used by
+	// editors. Tabs advance to the next tab stop, East Asian wide runes are
+	// two columns, and combining runes are zero columns.
+	ColumnModeVisual
+)
+
+// The PositionOptions.TabWidth used when none is provided.
+const DefaultTabWidth = 8
+
+// Holds the options that control how a Context tracks Origin information.
+type PositionOptions struct {
+	// The unit that Origin.ColNum is counted in. Defaults to ColumnModeRune.
+	ColumnMode ColumnMode
+	// The distance between tab stops when ColumnMode is ColumnModeVisual. A
+	// value <= 0 means DefaultTabWidth.
+	TabWidth int
+}
+
+// Tracks the line number, column number and byte offset of a rune stream.
+// Line breaks are "\n", "\r\n", a lone "\r", U+2028 and U+2029.
+type positionTracker struct {
+	options PositionOptions
+	lineNum int
+	colNum  int
+	offset  int
+	// True if the previous rune was a '\r', so a following '\n' completes
+	// a "\r\n" line break rather than starting a new one.
+	afterCR bool
+}
+
+// Returns a new positionTracker at the start of a rune stream.
+func newPositionTracker(options PositionOptions) positionTracker {
+	if options.TabWidth <= 0 {
+		options.TabWidth = DefaultTabWidth
+	}
+	return positionTracker{options, 1, 1, 0, false}
+}
+
+// Advances the position past the rune r, whose UTF-8 encoding is size bytes.
+func (tracker *positionTracker) advance(r rune, size int) {
+	tracker.offset += size
+	if r == '\n' && tracker.afterCR {
+		tracker.afterCR = false
+		return
+	}
+	tracker.afterCR = r == '\r'
+	if isLineBreakRune(r) {
+		tracker.lineNum++
+		tracker.colNum = 1
+		return
+	}
+
+	switch tracker.options.ColumnMode {
+	case ColumnModeByte:
+		tracker.colNum += size
+	case ColumnModeUTF16:
+		if r >= 0x10000 {
+			tracker.colNum += 2
+		} else {
+			tracker.colNum++
+		}
+	case ColumnModeVisual:
+		if r == '\t' {
+			tabWidth := tracker.options.TabWidth
+			tracker.colNum = ((tracker.colNum-1)/tabWidth+1)*tabWidth + 1
+		} else {
+			tracker.colNum += runeDisplayWidth(r)
+		}
+	default:
+		tracker.colNum++
+	}
+}
+
+// Returns the Origin of the current position, with the given Origin.Name.
+func (tracker *positionTracker) origin(name string) *Origin {
+	origin := NewOrigin(name, tracker.lineNum, tracker.colNum)
+	origin.Offset = tracker.offset
+	return origin
+}
+
+// Returns true if r ends a line.
+func isLineBreakRune(r rune) bool {
+	return r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029'
+}
+
+// Returns the number of columns r occupies when displayed.
+func runeDisplayWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) || (r >= 0x1160 && r <= 0x11FF) {
+		return 0
+	}
+	if unicode.Is(eastAsianWide, r) {
+		return 2
+	}
+	return 1
+}
+
+// The runes that are East Asian Wide or Fullwidth (i.e. displayed as two
+// columns). This covers the commonly used blocks rather than every rune.
+var eastAsianWide = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115F, Stride: 1},
+		{Lo: 0x231A, Hi: 0x231B, Stride: 1},
+		{Lo: 0x2329, Hi: 0x232A, Stride: 1},
+		{Lo: 0x23E9, Hi: 0x23EC, Stride: 1},
+		{Lo: 0x23F0, Hi: 0x23F3, Stride: 3},
+		{Lo: 0x25FD, Hi: 0x25FE, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2615, Stride: 1},
+		{Lo: 0x2648, Hi: 0x2653, Stride: 1},
+		{Lo: 0x267F, Hi: 0x2693, Stride: 20},
+		{Lo: 0x26A1, Hi: 0x26A1, Stride: 1},
+		{Lo: 0x26AA, Hi: 0x26AB, Stride: 1},
+		{Lo: 0x26BD, Hi: 0x26BE, Stride: 1},
+		{Lo: 0x26C4, Hi: 0x26C5, Stride: 1},
+		{Lo: 0x26CE, Hi: 0x26D4, Stride: 6},
+		{Lo: 0x26EA, Hi: 0x26EA, Stride: 1},
+		{Lo: 0x26F2, Hi: 0x26F3, Stride: 1},
+		{Lo: 0x26F5, Hi: 0x26FA, Stride: 5},
+		{Lo: 0x26FD, Hi: 0x26FD, Stride: 1},
+		{Lo: 0x2705, Hi: 0x2705, Stride: 1},
+		{Lo: 0x270A, Hi: 0x270B, Stride: 1},
+		{Lo: 0x2728, Hi: 0x2728, Stride: 1},
+		{Lo: 0x274C, Hi: 0x274E, Stride: 2},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27B0, Hi: 0x27BF, Stride: 15},
+		{Lo: 0x2B1B, Hi: 0x2B1C, Stride: 1},
+		{Lo: 0x2B50, Hi: 0x2B55, Stride: 5},
+		{Lo: 0x2E80, Hi: 0x303E, Stride: 1},
+		{Lo: 0x3041, Hi: 0x4DBF, Stride: 1},
+		{Lo: 0x4E00, Hi: 0xA4CF, Stride: 1},
+		{Lo: 0xA960, Hi: 0xA97F, Stride: 1},
+		{Lo: 0xAC00, Hi: 0xD7A3, Stride: 1},
+		{Lo: 0xF900, Hi: 0xFAFF, Stride: 1},
+		{Lo: 0xFE10, Hi: 0xFE19, Stride: 1},
+		{Lo: 0xFE30, Hi: 0xFE6F, Stride: 1},
+		{Lo: 0xFF00, Hi: 0xFF60, Stride: 1},
+		{Lo: 0xFFE0, Hi: 0xFFE6, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x16FE0, Hi: 0x16FE4, Stride: 1},
+		{Lo: 0x17000, Hi: 0x18AFF, Stride: 1},
+		{Lo: 0x1B000, Hi: 0x1B2FF, Stride: 1},
+		{Lo: 0x1F004, Hi: 0x1F004, Stride: 1},
+		{Lo: 0x1F0CF, Hi: 0x1F0CF, Stride: 1},
+		{Lo: 0x1F18E, Hi: 0x1F18E, Stride: 1},
+		{Lo: 0x1F191, Hi: 0x1F19A, Stride: 1},
+		{Lo: 0x1F200, Hi: 0x1F251, Stride: 1},
+		{Lo: 0x1F300, Hi: 0x1F64F, Stride: 1},
+		{Lo: 0x1F680, Hi: 0x1F6FF, Stride: 1},
+		{Lo: 0x1F900, Hi: 0x1F9FF, Stride: 1},
+		{Lo: 0x20000, Hi: 0x2FFFD, Stride: 1},
+		{Lo: 0x30000, Hi: 0x3FFFD, Stride: 1},
+	},
+}
@@ -0,0 +1,64 @@
+package eztok
+
+import (
+	"fmt"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPositionTracker(t *testing.T) {
+	runeMode := PositionOptions{ColumnMode: ColumnModeRune}
+	byteMode := PositionOptions{ColumnMode: ColumnModeByte}
+	utf16Mode := PositionOptions{ColumnMode: ColumnModeUTF16}
+	visualMode := PositionOptions{ColumnMode: ColumnModeVisual, TabWidth: 4}
+	tests := []struct {
+		name    string
+		input   string
+		options PositionOptions
+		// The line, column and offset after the input, as "line:col@offset".
+		want string
+	}{
+		{"ASCII runes", "ab", runeMode, "1:3@2"},
+		{"ASCII bytes", "ab", byteMode, "1:3@2"},
+		{"ASCII UTF-16", "ab", utf16Mode, "1:3@2"},
+		{"ASCII visual", "ab", visualMode, "1:3@2"},
+		{"multi-byte runes", "é☃😀", runeMode, "1:4@9"},
+		{"multi-byte bytes", "é☃😀", byteMode, "1:10@9"},
+		{"multi-byte UTF-16", "é☃😀", utf16Mode, "1:5@9"},
+		{"multi-byte visual", "é☃😀", visualMode, "1:5@9"},
+		{"wide runes", "中a", runeMode, "1:3@4"},
+		{"wide visual", "中a", visualMode, "1:4@4"},
+		{"combining runes", "e\u0301x", runeMode, "1:4@4"},
+		{"combining visual", "e\u0301x", visualMode, "1:3@4"},
+		{"tab runes", "a\tb", runeMode, "1:4@3"},
+		{"tab visual", "a\tb", visualMode, "1:6@3"},
+		{"tab at a tab stop", "abcd\tb", visualMode, "1:10@6"},
+		{"default tab width", "\t", PositionOptions{ColumnMode: ColumnModeVisual}, "1:9@1"},
+		{"LF", "a\nb", runeMode, "2:2@3"},
+		{"CRLF", "a\r\nb", runeMode, "2:2@4"},
+		{"lone CR", "a\rb", runeMode, "2:2@3"},
+		{"LF CR", "a\n\rb", runeMode, "3:2@4"},
+		{"CR CRLF", "a\r\r\nb", runeMode, "3:2@5"},
+		{"CR at the end", "a\r", runeMode, "2:1@2"},
+		{"U+2028", "a\u2028b", runeMode, "2:2@5"},
+		{"U+2029", "a\u2029b", byteMode, "2:2@5"},
+		{"CRLF bytes", "é\r\né", byteMode, "2:3@6"},
+		{"lone CR bytes", "é\ré", byteMode, "2:3@5"},
+		{"CRLF UTF-16", "😀\r\n😀", utf16Mode, "2:3@10"},
+		{"lone CR UTF-16", "😀\r😀", utf16Mode, "2:3@9"},
+		{"CRLF visual", "中\r\n中", visualMode, "2:3@8"},
+		{"lone CR visual", "中\r\t中", visualMode, "2:7@8"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracker := newPositionTracker(test.options)
+			for _, r := range test.input {
+				tracker.advance(r, utf8.RuneLen(r))
+			}
+			origin := tracker.origin("test")
+			if got := fmt.Sprintf("%v:%v@%v", origin.LineNum, origin.ColNum, origin.Offset); got != test.want {
+				t.Fatalf("position after %q = %v, want %v", test.input, got, test.want)
+			}
+		})
+	}
+}
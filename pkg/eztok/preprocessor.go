@@ -0,0 +1,570 @@
+package eztok
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The TokenType of Token objects returned by PreprocessorDirectiveNode. The
+// Value of such a Token is a *Directive.
+const TokenTypeDirective TokenType = "directive"
+
+// The string used as the Origin.Name of macros defined by Preprocessor.Define.
+const PreprocessorDefineOriginName = "<define>"
+
+// Represents a preprocessor directive, such as '#define NAME value'.
+type Directive struct {
+	// The name of the directive, such as "define" or "include".
+	Name string
+	// The remainder of the directive's line following its name. Any
+	// backslash-newline line continuations have had their backslash removed.
+	Body string
+	// The Origin information of the first rune of Body.
+	BodyOrigin *Origin
+}
+
+// A node that matches a preprocessor directive. A directive begins with a '#'
+// that is the first non-whitespace rune of its line, followed by a directive
+// name, and continues until the end of the line. A line ending in a backslash
+// ('\') continues the directive onto the next line. Returns a Token with a
+// TokenType of TokenTypeDirective.
+//
+// Whether the '#' begins its line is known for the Context a Preprocessor
+// passes to its Tokenizer. For any other Context, the '#' must be in the first
+// column of its line. Since this depends on more than the next runes, the node
+// has no pattern (see PatternNode).
+var PreprocessorDirectiveNode = NewCallbackNode(
+	func(ctx Context) bool {
+		return ctx.PeekRune(0) == '#' && isAtLineStart(ctx)
+	},
+	func(ctx Context) (*Token, error) {
+		if r := ctx.NextRune(); r != '#' {
+			return nil, fmt.Errorf("expected a '#' rune but got a '%c' rune", r)
+		}
+		ReadRunesUntilNot(ctx, isHorizontalSpace)
+		name := ReadRunesUntilNot(ctx, isIdentifierRune)
+		if len(name) <= 0 {
+			return nil, fmt.Errorf("expected a directive name after '#'")
+		}
+		bodyOrigin := ctx.GetNextOrigin()
+		body := ""
+		for !IsEOF(ctx) && !isLineBreakRune(ctx.PeekRune(0)) {
+			r := ctx.NextRune()
+			if r == '\\' && ctx.PeekRune(0) == '\r' && ctx.PeekRune(1) == '\n' {
+				r = ctx.NextRune()
+				body += string(r)
+				r = ctx.NextRune()
+			} else if r == '\\' && isLineBreakRune(ctx.PeekRune(0)) {
+				r = ctx.NextRune()
+			}
+			body += string(r)
+		}
+		return NewToken(TokenTypeDirective, &Directive{name, body, bodyOrigin}), nil
+	},
+)
+
+// A Tokenizer that runs a C-style preprocessor over the Token objects produced
+// by another Tokenizer. The following directives are supported:
+//   - #define NAME body
+//   - #define NAME(param1, param2, ...) body
+//   - #undef NAME
+//   - #ifdef NAME / #ifndef NAME / #else / #endif
+//   - #include "path" (or #include <path>)
+//
+// The wrapped Tokenizer must include PreprocessorDirectiveNode, and must
+// produce TokenTypeIdentifier Token objects for macro names. Function-like
+// macros additionally require the paren and comma TokenType fields to be set.
+//
+// Token objects produced by a macro expansion have an Origin of the macro
+// definition site, with Origin.ExpandedFrom set to the expansion site. Token
+// objects of an included source have Origin.IncludedFrom set to the site of
+// the #include directive.
+type Preprocessor struct {
+	// The Tokenizer used to tokenize input, includes and macro bodies.
+	Tokenizer Tokenizer
+	// The IncludeResolver used to look up #include paths. May be nil, in
+	// which case any #include is an error.
+	Resolver IncludeResolver
+	// The SourceRegistry to register the content of each included source
+	// with, under its include path. May be nil, in which case nothing is
+	// registered.
+	Registry *SourceRegistry
+	// The TokenType of a '(' Token. Required for function-like macros.
+	OpenParenType TokenType
+	// The TokenType of a ')' Token. Required for function-like macros.
+	CloseParenType TokenType
+	// The TokenType of a ',' Token. Required for function-like macros.
+	CommaType TokenType
+	// The macros defined before any input is preprocessed.
+	macros map[string]*macro
+}
+
+// Represents a macro defined by a #define directive.
+type macro struct {
+	// The name of the macro.
+	name string
+	// True if the macro was defined with a parameter list.
+	isFunction bool
+	// The names of the macro's parameters.
+	params []string
+	// The Token objects the macro expands to.
+	body []*Token
+}
+
+// Represents the state of an #ifdef or #ifndef directive.
+type preprocessorCondition struct {
+	// The Origin information of the directive.
+	origin *Origin
+	// True if the enclosing region is being kept.
+	parentActive bool
+	// True if the current branch is being kept.
+	active bool
+	// True once an #else directive has been seen.
+	seenElse bool
+}
+
+// The state of a single Preprocessor.Tokenize call.
+type preprocessorRun struct {
+	pp         *Preprocessor
+	macros     map[string]*macro
+	conditions []*preprocessorCondition
+}
+
+// Returns a new Preprocessor with the provided parameters.
+func NewPreprocessor(tokenizer Tokenizer, resolver IncludeResolver) *Preprocessor {
+	return &Preprocessor{
+		Tokenizer: tokenizer,
+		Resolver:  resolver,
+		macros:    map[string]*macro{},
+	}
+}
+
+// Defines a macro that is available to all input preprocessed afterwards.
+// The definition takes the same form as the body of a #define directive, such
+// as "DEBUG 1" or "SQUARE(x) ((x) * (x))".
+func (pp *Preprocessor) Define(definition string) error {
+	m, err := pp.parseMacro(definition, NewOrigin(PreprocessorDefineOriginName, 1, 1))
+	if err != nil {
+		return err
+	}
+	if pp.macros == nil {
+		pp.macros = map[string]*macro{}
+	}
+	pp.macros[m.name] = m
+	return nil
+}
+
+// Tokenizes ctx using Preprocessor.Tokenizer, then runs all directives and
+// expands all macros within the resulting Token objects. Returns an error if
+// tokenization or preprocessing fails.
+func (pp *Preprocessor) Tokenize(ctx Context) ([]*Token, error) {
+	name := ctx.GetNextOrigin().Name
+	toks, err := pp.Tokenizer.Tokenize(&lineStartContext{ctx, true})
+	if err != nil {
+		return nil, err
+	}
+	run := &preprocessorRun{pp, make(map[string]*macro, len(pp.macros)), nil}
+	for macroName, m := range pp.macros {
+		run.macros[macroName] = m
+	}
+	return run.run(NewSpliceTraverser(name, toks))
+}
+
+// Tokenizes text using Preprocessor.Tokenizer, as if text began at origin.
+func (pp *Preprocessor) tokenizeAt(text string, origin *Origin) ([]*Token, error) {
+	return pp.Tokenizer.Tokenize(&lineStartContext{&offsetContext{NewStringContext(text, origin.Name), origin}, false})
+}
+
+// Parses a macro definition such as "NAME(a, b) body", whose first rune is
+// at origin.
+func (pp *Preprocessor) parseMacro(definition string, origin *Origin) (*macro, error) {
+	rest := strings.TrimLeftFunc(definition, unicode.IsSpace)
+	nameEnd := strings.IndexFunc(rest, func(r rune) bool { return !isIdentifierRune(r) })
+	if nameEnd < 0 {
+		nameEnd = len(rest)
+	}
+	m := &macro{name: rest[:nameEnd]}
+	if len(m.name) <= 0 || unicode.IsDigit([]rune(m.name)[0]) {
+		return nil, fmt.Errorf("expected a macro name at %v", origin.ToString())
+	}
+	rest = rest[nameEnd:]
+
+	if strings.HasPrefix(rest, "(") {
+		paramsEnd := strings.IndexRune(rest, ')')
+		if paramsEnd < 0 {
+			return nil, fmt.Errorf("unterminated parameter list of macro '%v' at %v", m.name, origin.ToString())
+		}
+		if pp.OpenParenType == "" || pp.CloseParenType == "" || pp.CommaType == "" {
+			return nil, fmt.Errorf("function-like macro '%v' requires the Preprocessor paren and comma TokenTypes at %v",
+				m.name, origin.ToString())
+		}
+		m.isFunction = true
+		if params := strings.TrimSpace(rest[1:paramsEnd]); len(params) > 0 {
+			for _, param := range strings.Split(params, ",") {
+				param = strings.TrimSpace(param)
+				if len(param) <= 0 || strings.IndexFunc(param, func(r rune) bool { return !isIdentifierRune(r) }) >= 0 {
+					return nil, fmt.Errorf("invalid parameter '%v' of macro '%v' at %v", param, m.name, origin.ToString())
+				}
+				m.params = append(m.params, param)
+			}
+		}
+		rest = rest[paramsEnd+1:]
+	}
+
+	body, err := pp.tokenizeAt(rest, advanceOrigin(origin, definition[:len(definition)-len(rest)]))
+	if err != nil {
+		return nil, err
+	}
+	for _, tok := range body {
+		if tok.TokenType == TokenTypeDirective {
+			return nil, fmt.Errorf("unexpected directive in body of macro '%v' at %v", m.name, tok.Origin.ToString())
+		}
+	}
+	m.body = body
+	return m, nil
+}
+
+// Runs all directives and expands all macros of the Token objects in trav.
+func (run *preprocessorRun) run(trav *SpliceTraverser) ([]*Token, error) {
+	toks := []*Token{}
+	for tok := trav.NextToken(); tok != nil; tok = trav.NextToken() {
+		if tok.TokenType == TokenTypeDirective {
+			if err := run.runDirective(tok, trav); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if run.skipping() {
+			continue
+		}
+		expanded, err := run.expandToken(tok, trav, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, expanded...)
+	}
+	if len(run.conditions) > 0 {
+		return nil, fmt.Errorf("unterminated conditional directive at %v",
+			run.conditions[len(run.conditions)-1].origin.ToString())
+	}
+	return toks, nil
+}
+
+// Returns true if Token objects are currently being skipped due to a
+// conditional directive.
+func (run *preprocessorRun) skipping() bool {
+	return len(run.conditions) > 0 && !run.conditions[len(run.conditions)-1].active
+}
+
+// Runs the directive held by tok.
+func (run *preprocessorRun) runDirective(tok *Token, trav *SpliceTraverser) error {
+	dir := tok.Value.(*Directive)
+	switch dir.Name {
+	case "ifdef", "ifndef":
+		name, err := parseDirectiveName(dir)
+		if err != nil {
+			return err
+		}
+		_, defined := run.macros[name]
+		parentActive := !run.skipping()
+		run.conditions = append(run.conditions, &preprocessorCondition{
+			tok.Origin, parentActive, parentActive && defined == (dir.Name == "ifdef"), false})
+		return nil
+	case "else":
+		if len(run.conditions) <= 0 {
+			return fmt.Errorf("#else without #ifdef at %v", tok.Origin.ToString())
+		}
+		cond := run.conditions[len(run.conditions)-1]
+		if cond.seenElse {
+			return fmt.Errorf("duplicate #else at %v", tok.Origin.ToString())
+		}
+		cond.active = cond.parentActive && !cond.active
+		cond.seenElse = true
+		return nil
+	case "endif":
+		if len(run.conditions) <= 0 {
+			return fmt.Errorf("#endif without #ifdef at %v", tok.Origin.ToString())
+		}
+		run.conditions = run.conditions[:len(run.conditions)-1]
+		return nil
+	}
+
+	if run.skipping() {
+		return nil
+	}
+	switch dir.Name {
+	case "define":
+		m, err := run.pp.parseMacro(dir.Body, dir.BodyOrigin)
+		if err != nil {
+			return err
+		}
+		run.macros[m.name] = m
+	case "undef":
+		name, err := parseDirectiveName(dir)
+		if err != nil {
+			return err
+		}
+		delete(run.macros, name)
+	case "include":
+		return run.include(tok, trav)
+	default:
+		return fmt.Errorf("unknown directive '#%v' at %v", dir.Name, tok.Origin.ToString())
+	}
+	return nil
+}
+
+// Runs the #include directive held by tok, splicing the included Token
+// objects into trav.
+func (run *preprocessorRun) include(tok *Token, trav *SpliceTraverser) error {
+	dir := tok.Value.(*Directive)
+	path := strings.TrimSpace(dir.Body)
+	if len(path) < 2 || !((path[0] == '"' && path[len(path)-1] == '"') ||
+		(path[0] == '<' && path[len(path)-1] == '>')) {
+		return fmt.Errorf("expected #include \"path\" or #include <path> at %v", tok.Origin.ToString())
+	}
+	path = path[1 : len(path)-1]
+	if run.pp.Resolver == nil {
+		return fmt.Errorf("cannot include '%v' without an IncludeResolver at %v", path, tok.Origin.ToString())
+	}
+	content, err := run.pp.Resolver.ResolveInclude(path)
+	if err != nil {
+		return fmt.Errorf("%v at %v", err, tok.Origin.ToString())
+	}
+	if run.pp.Registry != nil {
+		run.pp.Registry.Register(path, content)
+	}
+	toks, err := run.pp.Tokenizer.Tokenize(&lineStartContext{NewStringContext(content, path), true})
+	if err != nil {
+		return fmt.Errorf("%v, included from %v", err, tok.Origin.ToString())
+	}
+	SetIncludedFrom(toks, tok.Origin)
+	for _, includedTok := range toks {
+		if includedDir, ok := includedTok.Value.(*Directive); ok && includedDir.BodyOrigin.IncludedFrom == nil {
+			includedDir.BodyOrigin.IncludedFrom = tok.Origin
+		}
+	}
+	if err := trav.Splice(path, toks); err != nil {
+		return fmt.Errorf("%v at %v", err, tok.Origin.ToString())
+	}
+	return nil
+}
+
+// Returns the macro tok refers to. Returns nil if tok does not refer to a macro.
+func (run *preprocessorRun) lookupMacro(tok *Token) *macro {
+	if tok.TokenType != TokenTypeIdentifier {
+		return nil
+	}
+	name, ok := tok.Value.(string)
+	if !ok {
+		return nil
+	}
+	return run.macros[name]
+}
+
+// Returns the Token objects tok expands to. Arguments of a function-like macro
+// are consumed from trav. Macros named in hidden are not expanded, which stops
+// a macro from expanding within its own expansion.
+func (run *preprocessorRun) expandToken(tok *Token, trav Traverser, hidden map[string]bool) ([]*Token, error) {
+	m := run.lookupMacro(tok)
+	if m == nil || hidden[m.name] {
+		return []*Token{tok}, nil
+	}
+	var args [][]*Token
+	if m.isFunction {
+		if !PeekTokenTypeIs(trav, run.pp.OpenParenType) {
+			return []*Token{tok}, nil
+		}
+		var err error
+		if args, err = run.readMacroArgs(m, tok, trav); err != nil {
+			return nil, err
+		}
+		for i, arg := range args {
+			if args[i], err = run.expandTokens(NewTokenTraverser(arg), hidden); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	body := []*Token{}
+	for _, bodyTok := range m.body {
+		if param := indexOfParam(m, bodyTok); param >= 0 {
+			body = append(body, args[param]...)
+			continue
+		}
+		expandedTok := *bodyTok
+		origin := *bodyTok.Origin
+		origin.ExpandedFrom = tok.Origin
+		expandedTok.Origin = &origin
+		body = append(body, &expandedTok)
+	}
+
+	innerHidden := make(map[string]bool, len(hidden)+1)
+	for name := range hidden {
+		innerHidden[name] = true
+	}
+	innerHidden[m.name] = true
+	return run.expandTokens(NewTokenTraverser(body), innerHidden)
+}
+
+// Returns the Token objects of trav with all macros expanded.
+func (run *preprocessorRun) expandTokens(trav Traverser, hidden map[string]bool) ([]*Token, error) {
+	toks := []*Token{}
+	for tok := trav.NextToken(); tok != nil; tok = trav.NextToken() {
+		expanded, err := run.expandToken(tok, trav, hidden)
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, expanded...)
+	}
+	return toks, nil
+}
+
+// Consumes the parenthesized argument list of an invocation of m at tok from
+// trav, and returns the Token objects of each argument.
+func (run *preprocessorRun) readMacroArgs(m *macro, tok *Token, trav Traverser) ([][]*Token, error) {
+	trav.NextToken()
+	args := [][]*Token{{}}
+	depth := 0
+	for {
+		argTok := trav.NextToken()
+		if argTok == nil {
+			return nil, fmt.Errorf("unterminated invocation of macro '%v' at %v", m.name, tok.Origin.ToString())
+		}
+		switch argTok.TokenType {
+		case run.pp.OpenParenType:
+			depth++
+		case run.pp.CloseParenType:
+			if depth == 0 {
+				if len(m.params) == 0 && len(args) == 1 && len(args[0]) == 0 {
+					args = nil
+				}
+				if len(args) != len(m.params) {
+					return nil, fmt.Errorf("macro '%v' expects %v argument(s) but got %v at %v",
+						m.name, len(m.params), len(args), tok.Origin.ToString())
+				}
+				return args, nil
+			}
+			depth--
+		case run.pp.CommaType:
+			if depth == 0 {
+				args = append(args, []*Token{})
+				continue
+			}
+		}
+		args[len(args)-1] = append(args[len(args)-1], argTok)
+	}
+}
+
+// Returns the index of the parameter of m that tok refers to. Returns -1 if
+// tok does not refer to a parameter of m.
+func indexOfParam(m *macro, tok *Token) int {
+	if tok.TokenType != TokenTypeIdentifier {
+		return -1
+	}
+	for i, param := range m.params {
+		if tok.Value == param {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the single macro name held by the body of dir.
+func parseDirectiveName(dir *Directive) (string, error) {
+	name := strings.TrimSpace(dir.Body)
+	if len(name) <= 0 || strings.IndexFunc(name, func(r rune) bool { return !isIdentifierRune(r) }) >= 0 {
+		return "", fmt.Errorf("expected a single macro name after #%v at %v", dir.Name, dir.BodyOrigin.ToString())
+	}
+	return name, nil
+}
+
+// Returns the Origin of the rune following text, given that text begins at origin.
+func advanceOrigin(origin *Origin, text string) *Origin {
+	tracker := newPositionTracker(PositionOptions{})
+	tracker.lineNum, tracker.colNum, tracker.offset = origin.LineNum, origin.ColNum, origin.Offset
+	for _, r := range text {
+		tracker.advance(r, utf8.RuneLen(r))
+	}
+	advanced := *origin
+	advanced.LineNum, advanced.ColNum, advanced.Offset = tracker.lineNum, tracker.colNum, tracker.offset
+	return &advanced
+}
+
+// Returns true if r is a space or tab rune.
+func isHorizontalSpace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// Returns true if r may appear within a C-style identifier.
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// A Context that offsets the Origin information of another Context, as if
+// its input began at origin.
+type offsetContext struct {
+	Context
+	origin *Origin
+}
+
+// Returns the Origin information of the rune that would be returned
+// by a call to NextRune(), offset by offsetContext.origin.
+func (ctx *offsetContext) GetNextOrigin() *Origin {
+	inner := ctx.Context.GetNextOrigin()
+	origin := *ctx.origin
+	origin.Offset += inner.Offset
+	if inner.LineNum == 1 {
+		origin.ColNum += inner.ColNum - 1
+	} else {
+		origin.LineNum += inner.LineNum - 1
+		origin.ColNum = inner.ColNum
+	}
+	return &origin
+}
+
+// Returns true if no runes remain in the wrapped Context.
+func (ctx *offsetContext) IsEOF() bool {
+	return IsEOF(ctx.Context)
+}
+
+// A Context that tracks whether only whitespace has been consumed from another
+// Context since the start of the current line.
+type lineStartContext struct {
+	Context
+	// True if no rune other than whitespace has been consumed since the last
+	// line break (see isLineBreakRune).
+	atLineStart bool
+}
+
+// Consume (i.e. advance the input stream by 1 rune) and return the consumed
+// rune, updating lineStartContext.atLineStart.
+func (ctx *lineStartContext) NextRune() rune {
+	r := ctx.Context.NextRune()
+	if isLineBreakRune(r) {
+		ctx.atLineStart = true
+	} else if !unicode.IsSpace(r) {
+		ctx.atLineStart = false
+	}
+	return r
+}
+
+// Returns true if no runes remain in the wrapped Context.
+func (ctx *lineStartContext) IsEOF() bool {
+	return IsEOF(ctx.Context)
+}
+
+// Returns the error of the wrapped Context if it is a FallibleContext.
+func (ctx *lineStartContext) Err() error {
+	return contextErr(ctx.Context)
+}
+
+// Returns true if no rune other than whitespace precedes the next rune of ctx
+// on its line. See PreprocessorDirectiveNode.
+func isAtLineStart(ctx Context) bool {
+	if lineCtx, ok := ctx.(*lineStartContext); ok {
+		return lineCtx.atLineStart
+	}
+	return ctx.GetNextOrigin().ColNum == 1
+}
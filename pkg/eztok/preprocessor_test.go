@@ -0,0 +1,99 @@
+package eztok
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Returns a Tokenizer for preprocessor tests, which tokenizes a '#' that does
+// not begin a directive as a "#" Token.
+func newPreprocessorTestTokenizer() Tokenizer {
+	return NewInOrderNodeTokenizer(
+		SkipWhitespaceNode,
+		PreprocessorDirectiveNode,
+		NewRuneMatchNode("#", '#'),
+		NewRuneMatchNode("(", '('),
+		NewRuneMatchNode(")", ')'),
+		NewRuneMatchNode(",", ','),
+		NumberNode,
+		IdentifierNode,
+	)
+}
+
+func TestPreprocessorDirectivesAtLineStart(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"first column", "#define X 1\nX", "1"},
+		{"indented", "a\n  \t#define X 1\nX", "a 1"},
+		{"mid-line", "a #define X 1\nX", "a 35 define X 1 X"},
+		{"after a line continuation", "#define X \\\n 1\nX", "1"},
+		{"after CRLF", "a\r\n#define X 1\r\nX", "a 1"},
+		{"after a lone CR", "a\r #define X 1\rX", "a 1"},
+		{"after U+2028", "a\u2028#define X 1\u2028X", "a 1"},
+		{"after U+2029", "a\u2029#define X 1\u2029X", "a 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := TokenizeString(NewPreprocessor(newPreprocessorTestTokenizer(), nil), test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := preprocessorTestValues(toks); got != test.want {
+				t.Fatalf("values = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPreprocessorDirectiveNodeWithoutPreprocessor(t *testing.T) {
+	toks, err := TokenizeString(newPreprocessorTestTokenizer(), "#a\n x #b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := []TokenType{}
+	for _, tok := range toks {
+		types = append(types, tok.TokenType)
+	}
+	if want := []TokenType{TokenTypeDirective, TokenTypeIdentifier, "#", TokenTypeIdentifier}; !reflect.DeepEqual(types, want) {
+		t.Fatalf("TokenTypes = %v, want %v", types, want)
+	}
+}
+
+func TestPreprocessorZeroValueDefine(t *testing.T) {
+	pp := &Preprocessor{Tokenizer: newPreprocessorTestTokenizer()}
+	if err := pp.Define("DEBUG 1"); err != nil {
+		t.Fatal(err)
+	}
+	toks, err := TokenizeString(pp, "DEBUG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := preprocessorTestValues(toks); got != "1" {
+		t.Fatalf("values = %q, want \"1\"", got)
+	}
+}
+
+func TestPreprocessorIncludes(t *testing.T) {
+	resolver := MapIncludeResolver{
+		"a.h": "#define A 1\n#include \"b.h\"",
+		"b.h": "#include \"a.h\"",
+	}
+	_, err := TokenizeString(NewPreprocessor(newPreprocessorTestTokenizer(), resolver), "#include \"a.h\"")
+	if err == nil || !strings.Contains(err.Error(), "cyclic splice of 'a.h'") {
+		t.Fatalf("cyclic include returned error %v", err)
+	}
+}
+
+// Returns the Value of each Token in toks, separated by spaces.
+func preprocessorTestValues(toks []*Token) string {
+	values := make([]string, len(toks))
+	for i, tok := range toks {
+		values[i] = fmt.Sprint(tok.Value)
+	}
+	return strings.Join(values, " ")
+}
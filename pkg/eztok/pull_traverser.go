@@ -0,0 +1,109 @@
+package eztok
+
+import "log"
+
+// The type of the callback function required by NewPullTraverser. Returns the
+// next Token in the stream, or nil if no Tokens remain.
+type PullTokenCallback func() *Token
+
+// The type of the callback function required by Filter. Returns true if the
+// Token should be kept in the stream.
+type TokenPredicate func(tok *Token) bool
+
+// The type of the callback function required by Map. Returns the Token that
+// should replace tok in the stream, or nil if tok should be dropped.
+type TokenMapCallback func(tok *Token) *Token
+
+// A Traverser whose Token stream is lazily pulled from a callback. Tokens are
+// only pulled when they are peeked or consumed, and each pulled Token is
+// buffered so that repeated calls to PeekToken return the same Token.
+type PullTraverser struct {
+	// The function to call when another Token is needed.
+	pull PullTokenCallback
+	// The Tokens that have been pulled but not yet consumed.
+	buffer []*Token
+	// True once pull has returned nil.
+	done bool
+}
+
+// Returns a new PullTraverser with the provided parameters.
+func NewPullTraverser(pull PullTokenCallback) *PullTraverser {
+	return &PullTraverser{pull, []*Token{}, false}
+}
+
+// Return the Token that is relative Tokens ahead of the current Token in
+// the stream. Returns nil if there is none.
+func (trav *PullTraverser) PeekToken(relative int) *Token {
+	if relative < 0 {
+		log.Panicf("PeekToken cannot peek negatively; tried peeking a relative '%v' tokens", relative)
+	}
+	for !trav.done && relative >= len(trav.buffer) {
+		tok := trav.pull()
+		if tok == nil {
+			trav.done = true
+			break
+		}
+		trav.buffer = append(trav.buffer, tok)
+	}
+	if relative < len(trav.buffer) {
+		return trav.buffer[relative]
+	}
+	return nil
+}
+
+// Consume (i.e. advance the stream by 1 Token) and return the consumed
+// Token. Returns nil if no Tokens remain.
+func (trav *PullTraverser) NextToken() *Token {
+	tok := trav.PeekToken(0)
+	if tok == nil {
+		return nil
+	}
+	trav.buffer[0] = nil
+	trav.buffer = trav.buffer[1:]
+	return tok
+}
+
+// Returns a new PullTraverser over the Tokens of trav for which predicate
+// returns true. Tokens of trav are consumed as the returned PullTraverser
+// needs them, so trav should not be used directly afterwards.
+func Filter(trav Traverser, predicate TokenPredicate) *PullTraverser {
+	return NewPullTraverser(func() *Token {
+		for tok := trav.NextToken(); tok != nil; tok = trav.NextToken() {
+			if predicate(tok) {
+				return tok
+			}
+		}
+		return nil
+	})
+}
+
+// Returns a new PullTraverser over the Tokens of trav as rewritten by fn. If
+// fn returns nil for a Token, that Token is dropped from the stream. Tokens
+// of trav are consumed as the returned PullTraverser needs them, so trav
+// should not be used directly afterwards.
+func Map(trav Traverser, fn TokenMapCallback) *PullTraverser {
+	return NewPullTraverser(func() *Token {
+		for tok := trav.NextToken(); tok != nil; tok = trav.NextToken() {
+			if mapped := fn(tok); mapped != nil {
+				return mapped
+			}
+		}
+		return nil
+	})
+}
+
+// Returns a new PullTraverser over the Tokens of each Traverser in travs,
+// in-order. The Tokens of travs[i+1] follow once travs[i] has no Tokens
+// remaining. Tokens of travs are consumed as the returned PullTraverser
+// needs them, so travs should not be used directly afterwards.
+func Concat(travs ...Traverser) *PullTraverser {
+	return NewPullTraverser(func() *Token {
+		for len(travs) > 0 {
+			if tok := travs[0].NextToken(); tok != nil {
+				return tok
+			}
+			travs = travs[1:]
+		}
+		return nil
+	})
+}
@@ -0,0 +1,114 @@
+package eztok
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPullTraverserPullsLazily(t *testing.T) {
+	toks := spliceTestTokens("a", 3)
+	pulls := 0
+	trav := NewPullTraverser(func() *Token {
+		pulls++
+		if pulls > len(toks) {
+			return nil
+		}
+		return toks[pulls-1]
+	})
+	if pulls != 0 {
+		t.Fatalf("NewPullTraverser pulled %v Tokens, want 0", pulls)
+	}
+	if tok := trav.PeekToken(1); tok.Value != "a1" || pulls != 2 {
+		t.Fatalf("PeekToken(1) = %v after %v pulls, want a1 after 2", tok.Value, pulls)
+	}
+	if tok := trav.PeekToken(0); tok.Value != "a0" || pulls != 2 {
+		t.Fatalf("PeekToken(0) = %v after %v pulls, want a0 after 2", tok.Value, pulls)
+	}
+	if got := pullTestValues(trav); !reflect.DeepEqual(got, []any{"a0", "a1", "a2"}) {
+		t.Fatalf("NextToken values = %v, want [a0 a1 a2]", got)
+	}
+	if tok := trav.PeekToken(5); tok != nil || pulls != 4 {
+		t.Fatalf("PeekToken(5) after the end = %v after %v pulls, want nil after 4", tok, pulls)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		keep string
+		want []any
+	}{
+		{"keeps order", "a0 a2 a3", []any{"a0", "a2", "a3"}},
+		{"keeps everything", "a0 a1 a2 a3", []any{"a0", "a1", "a2", "a3"}},
+		{"filters everything out", "", []any{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trav := Filter(NewTokenTraverser(spliceTestTokens("a", 4)), func(tok *Token) bool {
+				return strings.Contains(test.keep, tok.Value.(string))
+			})
+			if got := pullTestValues(trav); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("NextToken values = %v, want %v", got, test.want)
+			}
+			if tok := trav.PeekToken(0); tok != nil {
+				t.Fatalf("PeekToken(0) after the end = %v, want nil", tok)
+			}
+		})
+	}
+}
+
+func TestMap(t *testing.T) {
+	trav := Map(NewTokenTraverser(spliceTestTokens("a", 4)), func(tok *Token) *Token {
+		if tok.Value == "a1" {
+			return nil
+		}
+		return NewToken(tok.TokenType, strings.ToUpper(tok.Value.(string)))
+	})
+	if got, want := pullTestValues(trav), []any{"A0", "A2", "A3"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("NextToken values = %v, want %v", got, want)
+	}
+
+	trav = Map(NewTokenTraverser(spliceTestTokens("a", 2)), func(tok *Token) *Token { return nil })
+	if tok := trav.NextToken(); tok != nil {
+		t.Fatalf("NextToken() with every Token dropped = %v, want nil", tok)
+	}
+}
+
+func TestConcat(t *testing.T) {
+	exhausted := NewTokenTraverser(spliceTestTokens("x", 2))
+	exhausted.NextToken()
+	exhausted.NextToken()
+	tests := []struct {
+		name  string
+		travs []Traverser
+		want  []any
+	}{
+		{"keeps order", []Traverser{NewTokenTraverser(spliceTestTokens("a", 2)), NewTokenTraverser(spliceTestTokens("b", 2))},
+			[]any{"a0", "a1", "b0", "b1"}},
+		{"exhausted first traverser", []Traverser{exhausted, NewTokenTraverser(spliceTestTokens("b", 2))}, []any{"b0", "b1"}},
+		{"empty traverser in between", []Traverser{NewTokenTraverser(spliceTestTokens("a", 1)), NewTokenTraverser(nil),
+			NewTokenTraverser(spliceTestTokens("c", 1))}, []any{"a0", "c0"}},
+		{"no traversers", nil, []any{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trav := Concat(test.travs...)
+			if got := pullTestValues(trav); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("NextToken values = %v, want %v", got, test.want)
+			}
+			if tok := trav.PeekToken(0); tok != nil {
+				t.Fatalf("PeekToken(0) after the end = %v, want nil", tok)
+			}
+		})
+	}
+}
+
+// Consumes the remaining Token objects of trav and returns their values.
+func pullTestValues(trav Traverser) []any {
+	values := []any{}
+	for tok := trav.NextToken(); tok != nil; tok = trav.NextToken() {
+		values = append(values, tok.Value)
+	}
+	return values
+}
@@ -0,0 +1,208 @@
+package eztok
+
+import (
+	"log"
+	"unicode/utf8"
+)
+
+// The type of the callback function required by NewPushTokenizer. Called with
+// each Token as soon as it is complete.
+type PushTokenCallback func(tok *Token)
+
+// Tokenizes input that is written to it in chunks, such as input arriving over
+// a network connection or typed into a REPL, rather than read from a Context.
+// Each Token is passed to a callback as soon as it is complete. A Token that
+// reaches the end of the input written so far may be continued by the next
+// chunk, so it is held back until more input is written, or until
+// PushTokenizer.Flush or PushTokenizer.Close is called.
+type PushTokenizer struct {
+	// The StepTokenizer that Token objects are parsed with.
+	tokenizer StepTokenizer
+	// The function to call with each complete Token.
+	callback   PushTokenCallback
+	originName string
+	// The input written but not yet tokenized.
+	pending []byte
+	// The position of the start of pending.
+	position positionTracker
+	// The error that stopped tokenization, if any.
+	err    error
+	closed bool
+}
+
+// Returns a new PushTokenizer with the provided parameters and default
+// PositionOptions. The originName will be used as the Origin.Name for all runes.
+func NewPushTokenizer(tokenizer StepTokenizer, originName string, callback PushTokenCallback) *PushTokenizer {
+	return NewPushTokenizerWithOptions(tokenizer, originName, PositionOptions{}, callback)
+}
+
+// Returns a new PushTokenizer with the provided parameters. The originName will
+// be used as the Origin.Name for all runes.
+func NewPushTokenizerWithOptions(tokenizer StepTokenizer, originName string, options PositionOptions,
+	callback PushTokenCallback) *PushTokenizer {
+	return &PushTokenizer{tokenizer, callback, originName, []byte{}, newPositionTracker(options), nil, false}
+}
+
+// Appends chunk to the input, and passes each Token completed by it to the
+// callback. A chunk may end part way through a rune's UTF-8 encoding. Returns
+// an error if tokenization fails, after which all further calls fail with the
+// same error. Implements io.Writer.
+func (tizer *PushTokenizer) Write(chunk []byte) (int, error) {
+	if tizer.err != nil {
+		return 0, tizer.err
+	}
+	if tizer.closed {
+		log.Panicf("Cannot Write to a PushTokenizer that has been closed.")
+	}
+	tizer.pending = append(tizer.pending, chunk...)
+	return len(chunk), tizer.tokenize(false)
+}
+
+// Appends chunk to the input. See PushTokenizer.Write.
+func (tizer *PushTokenizer) WriteString(chunk string) (int, error) {
+	return tizer.Write([]byte(chunk))
+}
+
+// Tokenizes all input held back by the PushTokenizer as if the input ended
+// here, and passes each resulting Token to the callback. Further input may
+// still be written, but no Token will span this point. Returns an error if
+// tokenization fails.
+func (tizer *PushTokenizer) Flush() error {
+	if tizer.err != nil {
+		return tizer.err
+	}
+	return tizer.tokenize(true)
+}
+
+// Ends the input, flushing any input held back by the PushTokenizer. Returns
+// an error if tokenization fails. Implements io.Closer.
+func (tizer *PushTokenizer) Close() error {
+	if tizer.closed {
+		return tizer.err
+	}
+	err := tizer.Flush()
+	tizer.closed = true
+	return err
+}
+
+// Returns the Origin information of the start of the input held back by the
+// PushTokenizer.
+func (tizer *PushTokenizer) GetNextOrigin() *Origin {
+	return tizer.position.origin(tizer.originName)
+}
+
+// Parses Token objects from the start of pending until it is empty, or until
+// a Token reaches the end of pending and final is false.
+func (tizer *PushTokenizer) tokenize(final bool) error {
+	for {
+		ctx := newChunkContext(tizer.pending, tizer.originName, tizer.position, final)
+		if ctx.IsEOF() {
+			break
+		}
+		tok, err := tizer.tokenizer.TokenizeNext(ctx)
+		if ctx.hitEnd {
+			// More input could change the result, so rewind and wait for it.
+			break
+		}
+		if err != nil {
+			tizer.err = err
+			return err
+		}
+		tizer.pending = tizer.pending[ctx.offset:]
+		tizer.position = ctx.position
+		if tok != nil {
+			tizer.callback(tok)
+		}
+	}
+	if len(tizer.pending) == 0 {
+		// Release the memory of input that has been tokenized.
+		tizer.pending = []byte{}
+	}
+	return nil
+}
+
+// A Context over the input held by a PushTokenizer, which records whether it
+// was asked for a rune beyond the end of that input.
+type chunkContext struct {
+	content []byte
+	// The length of content excluding any incomplete rune at its end.
+	length     int
+	final      bool
+	originName string
+	offset     int
+	position   positionTracker
+	// True once a rune beyond the end of the input has been peeked or
+	// consumed, or chunkContext.IsEOF has returned true, when final is false.
+	hitEnd bool
+}
+
+// Returns a new chunkContext with the provided parameters. If final is false,
+// the input may be continued, so an incomplete rune at the end of content is
+// excluded from the input.
+func newChunkContext(content []byte, originName string, position positionTracker, final bool) *chunkContext {
+	length := len(content)
+	if !final {
+		start := length - 1
+		for start > 0 && start > length-utf8.UTFMax && !utf8.RuneStart(content[start]) {
+			start--
+		}
+		if start >= 0 && !utf8.FullRune(content[start:]) {
+			length = start
+		}
+	}
+	return &chunkContext{content, length, final, originName, 0, position, false}
+}
+
+// Return the rune that is relative runes ahead of the current
+// rune in the input. Returns NilRune if there is none.
+func (ctx *chunkContext) PeekRune(relative int) rune {
+	r, _ := ctx.peekRune(relative)
+	return r
+}
+
+// Returns the rune that is relative runes ahead of the current rune in the
+// input, and true if there is such a rune.
+func (ctx *chunkContext) peekRune(relative int) (rune, bool) {
+	if relative < 0 {
+		log.Panicf("PeekRune cannot peek negatively; tried peeking a relative '%v' runes", relative)
+	}
+	offset := ctx.offset
+	for ; relative > 0 && offset < ctx.length; relative-- {
+		_, size := utf8.DecodeRune(ctx.content[offset:ctx.length])
+		offset += size
+	}
+	if offset >= ctx.length {
+		ctx.hitEnd = !ctx.final
+		return NilRune, false
+	}
+	r, _ := utf8.DecodeRune(ctx.content[offset:ctx.length])
+	return r, true
+}
+
+// Consume (i.e. advance the input by 1 rune) and return the
+// consumed rune. Returns NilRune if there is none.
+func (ctx *chunkContext) NextRune() rune {
+	if ctx.offset >= ctx.length {
+		ctx.hitEnd = !ctx.final
+		return NilRune
+	}
+	r, size := utf8.DecodeRune(ctx.content[ctx.offset:ctx.length])
+	ctx.offset += size
+	ctx.position.advance(r, size)
+	return r
+}
+
+// Returns the Origin information of the rune that would be returned
+// by a call to NextRune().
+func (ctx *chunkContext) GetNextOrigin() *Origin {
+	return ctx.position.origin(ctx.originName)
+}
+
+// Returns true if no runes remain in the input.
+func (ctx *chunkContext) IsEOF() bool {
+	if ctx.offset >= ctx.length {
+		ctx.hitEnd = !ctx.final
+		return true
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package eztok
+
+import (
+	"strings"
+	"testing"
+)
+
+// Returns a StepTokenizer for PushTokenizer tests.
+func newPushTestTokenizer() *InOrderNodeTokenizer {
+	return NewInOrderNodeTokenizer(
+		SkipWhitespaceNode,
+		NewStringMatchNode("==", "=="),
+		NewStringMatchNode("=", "="),
+		NewStringMatchNode("→", "→"),
+		NumberNode,
+		IdentifierNode,
+	)
+}
+
+// Returns a string representation of each Token in toks and its Origin.
+func pushTestStrings(toks []*Token) []string {
+	strs := make([]string, len(toks))
+	for i, tok := range toks {
+		strs[i] = tok.ToString() + " at " + tok.Origin.ToString()
+	}
+	return strs
+}
+
+func TestPushTokenizerChunkBoundaries(t *testing.T) {
+	inputs := []string{
+		"abc == 12",
+		"x=y==z",
+		"héllo → wörld\n  123 =",
+		"ab\r\ncd",
+	}
+	for _, input := range inputs {
+		want, err := TokenizeString(newPushTestTokenizer(), input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantStrs := strings.Join(pushTestStrings(want), "\n")
+		// Split the input into two chunks at every byte, including part way
+		// through a rune, and into chunks of one byte.
+		splits := [][]string{}
+		for i := 0; i <= len(input); i++ {
+			splits = append(splits, []string{input[:i], input[i:]})
+		}
+		bytes := []string{}
+		for i := 0; i < len(input); i++ {
+			bytes = append(bytes, input[i:i+1])
+		}
+		splits = append(splits, bytes)
+		for _, chunks := range splits {
+			got := []*Token{}
+			tizer := NewPushTokenizer(newPushTestTokenizer(), TokenizeStringOriginName, func(tok *Token) {
+				got = append(got, tok)
+			})
+			for _, chunk := range chunks {
+				if _, err := tizer.WriteString(chunk); err != nil {
+					t.Fatalf("Write of chunks %q failed: %v", chunks, err)
+				}
+			}
+			if err := tizer.Close(); err != nil {
+				t.Fatalf("Close after chunks %q failed: %v", chunks, err)
+			}
+			if gotStrs := strings.Join(pushTestStrings(got), "\n"); gotStrs != wantStrs {
+				t.Fatalf("chunks %q produced:\n%v\nwant:\n%v", chunks, gotStrs, wantStrs)
+			}
+		}
+	}
+}
+
+func TestPushTokenizerHoldsBackIncompleteTokens(t *testing.T) {
+	got := []string{}
+	tizer := NewPushTokenizer(newPushTestTokenizer(), "test", func(tok *Token) {
+		got = append(got, tok.ToString())
+	})
+	steps := []struct {
+		chunk string
+		want  int
+	}{
+		// "ab" may continue, so it is held back.
+		{"ab", 0},
+		{"c ", 1},
+		// "=" may become "==".
+		{"=", 1},
+		{"= 1", 2},
+	}
+	for _, step := range steps {
+		if _, err := tizer.WriteString(step.chunk); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != step.want {
+			t.Fatalf("after writing %q got %v Token objects %v, want %v", step.chunk, len(got), got, step.want)
+		}
+	}
+	if err := tizer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"identifier (abc)", "== (==)", "integer (1)"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if origin := tizer.GetNextOrigin(); origin.ColNum != 9 {
+		t.Fatalf("GetNextOrigin() = %v, want column 9", origin.ToString())
+	}
+}
+
+func TestPushTokenizerErrors(t *testing.T) {
+	tizer := NewPushTokenizer(newPushTestTokenizer(), "test", func(tok *Token) {})
+	if _, err := tizer.WriteString("a $ b"); err == nil || !strings.Contains(err.Error(), "test:1:3") {
+		t.Fatalf("Write returned error %v, want an unexpected rune at test:1:3", err)
+	}
+	if _, err := tizer.WriteString("c"); err == nil {
+		t.Fatalf("Write after a failure returned no error")
+	}
+	if err := tizer.Close(); err == nil {
+		t.Fatalf("Close after a failure returned no error")
+	}
+}
@@ -2,73 +2,273 @@ package eztok
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"strings"
+	"unicode/utf8"
 )
 
-// A Context whose input rune stream is a bufio.Reader.
+// The ReaderContextOptions.MaxLookahead used when none is provided.
+const DefaultReaderContextMaxLookahead = 256
+
+// The error wrapped by the error of a Context that was peeked further than its
+// maximum lookahead, such as ReaderContext.Err. Use errors.Is to check for it.
+var ErrLookaheadLimit = errors.New("lookahead limit reached")
+
+// Represents how a ReaderContext handles input that is not valid UTF-8.
+type InvalidEncodingPolicy int
+
+// InvalidEncodingPolicy definitions.
+const (
+	// Each invalid byte is replaced by utf8.RuneError (U+FFFD).
+	InvalidEncodingReplace InvalidEncodingPolicy = iota
+	// The input stops at the first invalid byte, and ReaderContext.Err
+	// returns an error holding the Origin of the invalid byte.
+	InvalidEncodingError
+	// Each invalid byte is passed through as the rune with the same value
+	// (i.e. as if the byte were Latin-1).
+	InvalidEncodingRaw
+)
+
+// Holds the options of a ReaderContext.
+type ReaderContextOptions struct {
+	// The maximum relative that may be passed to ReaderContext.PeekRune. The
+	// ReaderContext only ever holds this many runes (plus one) in memory, so
+	// arbitrarily large input can be read in constant memory. A value <= 0
+	// means DefaultReaderContextMaxLookahead.
+	MaxLookahead int
+	// The Decoder used to convert the input into UTF-8. If nil, the Decoder
+	// is chosen by DetectEncoding, which strips any byte order mark. Since
+	// runes are read from the converted input, Origin.Offset is a byte offset
+	// into the UTF-8 form of the input.
+	Decoder Decoder
+	// How input that is not valid UTF-8 is handled. Defaults to
+	// InvalidEncodingReplace.
+	InvalidEncoding InvalidEncodingPolicy
+	// How Origin information is tracked.
+	Position PositionOptions
+	// The SourceRegistry to register the input with, or nil if it should
+	// not be registered. The SourceFile is registered when the ReaderContext
+	// is created, and its content grows as the input is read, so it must not
+	// be used concurrently with the ReaderContext. See SourceRegistry.
+	Registry *SourceRegistry
+}
+
+// A Context whose input rune stream is a bufio.Reader. Peeked runes are held
+// in a fixed-capacity ring buffer, whose capacity is set by
+// ReaderContextOptions.MaxLookahead.
 type ReaderContext struct {
-	reader      *bufio.Reader
-	originName  string
-	nextLineNum int
-	nextColNum  int
-	runeQueue   []rune
+	reader          *bufio.Reader
+	decoder         Decoder
+	decoded         bool
+	invalidEncoding InvalidEncodingPolicy
+	originName      string
+	position        positionTracker
+	// The ring buffer of peeked runes, and their sizes in bytes.
+	runeRing []rune
+	sizeRing []int
+	// The index in the ring buffer of the next rune, and the number of
+	// peeked runes held in the ring buffer.
+	ringHead  int
+	ringCount int
+	// True once reader has no runes remaining.
+	readerDone bool
+	// The error that stopped reader from being read, if any.
+	readerErr error
+	// The invalid byte that stopped reader from being read, or -1 if none.
+	invalidByte int
+	// The error recording the first peek further than the maximum lookahead,
+	// if any. See ErrLookaheadLimit.
+	lookaheadErr error
+	// The SourceFile registered with ReaderContextOptions.Registry, or nil,
+	// and the content read so far.
+	source        *SourceFile
+	sourceContent strings.Builder
+}
+
+// Returns a new ReaderContext with the provided parameters and default
+// options. The originName will be used as the Origin.Name for all runes.
+func NewReaderContext(reader *bufio.Reader, originName string) *ReaderContext {
+	return NewReaderContextWithOptions(reader, originName, ReaderContextOptions{})
 }
 
 // Returns a new ReaderContext with the provided parameters. The originName will
 // be used as the Origin.Name for all runes.
-func NewReaderContext(reader *bufio.Reader, originName string) *ReaderContext {
-	return &ReaderContext{reader, originName, 1, 1, []rune{}}
+func NewReaderContextWithOptions(reader *bufio.Reader, originName string, options ReaderContextOptions) *ReaderContext {
+	maxLookahead := options.MaxLookahead
+	if maxLookahead <= 0 {
+		maxLookahead = DefaultReaderContextMaxLookahead
+	}
+	ctx := &ReaderContext{
+		reader:          reader,
+		decoder:         options.Decoder,
+		invalidEncoding: options.InvalidEncoding,
+		originName:      originName,
+		position:        newPositionTracker(options.Position),
+		runeRing:        make([]rune, maxLookahead+1),
+		sizeRing:        make([]int, maxLookahead+1),
+		invalidByte:     -1,
+	}
+	if options.Registry != nil {
+		ctx.source = NewSourceFile(originName, "", options.Position)
+		options.Registry.registerFile(ctx.source)
+	}
+	return ctx
+}
+
+// Returns the maximum relative that may be passed to ReaderContext.PeekRune.
+func (ctx *ReaderContext) MaxLookahead() int {
+	return len(ctx.runeRing) - 1
 }
 
 // Return the rune that is relative runes ahead of the current
-// rune in the input. Returns NilRune if there is none.
+// rune in the input. Returns NilRune if there is none. If relative is greater
+// than ReaderContext.MaxLookahead(), the input stops as if it had ended, and
+// ReaderContext.Err returns an error wrapping ErrLookaheadLimit.
 func (ctx *ReaderContext) PeekRune(relative int) rune {
 	if relative < 0 {
-		log.Panicf("PeekRuneAhead cannot peek negatively; tried peeking a relative '%v' runes", relative)
+		log.Panicf("PeekRune cannot peek negatively; tried peeking a relative '%v' runes", relative)
 	}
-	if relative < len(ctx.runeQueue) {
-		return ctx.runeQueue[relative]
-	} else if len(ctx.runeQueue) > 0 && ctx.runeQueue[len(ctx.runeQueue)-1] == NilRune {
+	if relative >= len(ctx.runeRing) && ctx.lookaheadErr == nil {
+		ctx.lookaheadErr = fmt.Errorf("%w: cannot peek a relative '%v' runes ahead of %v with a maximum lookahead of '%v' runes"+
+			" (see ReaderContextOptions.MaxLookahead)", ErrLookaheadLimit, relative, ctx.GetNextOrigin().ToString(), ctx.MaxLookahead())
+	}
+	if ctx.lookaheadErr != nil {
 		return NilRune
 	}
-
-	for r, size, err := ctx.reader.ReadRune(); err == nil && size > 0; r, size, err = ctx.reader.ReadRune() {
-		ctx.runeQueue = append(ctx.runeQueue, r)
-		if relative < len(ctx.runeQueue) {
-			return ctx.runeQueue[relative]
-		}
+	ctx.fillRing(relative + 1)
+	if relative < ctx.ringCount {
+		return ctx.runeRing[(ctx.ringHead+relative)%len(ctx.runeRing)]
 	}
-	ctx.runeQueue = append(ctx.runeQueue, NilRune)
 	return NilRune
 }
 
 // Consume (i.e. advance the input stream by 1 rune) and return the
 // consumed rune. Returns NilRune if there is none.
 func (ctx *ReaderContext) NextRune() rune {
-	if len(ctx.runeQueue) <= 0 {
-		ctx.PeekRune(0)
-		if len(ctx.runeQueue) <= 0 {
-			log.Panicf("NextRune expects len(runeQueue) > 0 after a call to PeekRune always")
-		}
-	}
-
-	r := ctx.runeQueue[0]
-	if r == NilRune {
-		return r
-	}
-	ctx.runeQueue = ctx.runeQueue[1:]
-
-	if r == '\n' {
-		ctx.nextLineNum++
-		ctx.nextColNum = 1
-	} else {
-		ctx.nextColNum++
+	ctx.fillRing(1)
+	if ctx.ringCount <= 0 || ctx.lookaheadErr != nil {
+		return NilRune
 	}
+	r := ctx.runeRing[ctx.ringHead]
+	ctx.position.advance(r, ctx.sizeRing[ctx.ringHead])
+	ctx.ringHead = (ctx.ringHead + 1) % len(ctx.runeRing)
+	ctx.ringCount--
 	return r
 }
 
 // Returns the Origin information of the rune that would be returned
 // by a call to NextRune().
 func (ctx *ReaderContext) GetNextOrigin() *Origin {
-	return NewOrigin(ctx.originName, ctx.nextLineNum, ctx.nextColNum)
+	return ctx.position.origin(ctx.originName)
+}
+
+// Returns true if no runes remain in the input.
+func (ctx *ReaderContext) IsEOF() bool {
+	ctx.fillRing(1)
+	return ctx.ringCount <= 0 || ctx.lookaheadErr != nil
+}
+
+// Returns the error that stopped the input from being read, once all runes
+// before the failure have been consumed. Returns nil otherwise. The error is
+// either an I/O error of the reader, an invalid byte when
+// ReaderContextOptions.InvalidEncoding is InvalidEncodingError, or a peek
+// further than ReaderContext.MaxLookahead() (which stops the input at once).
+func (ctx *ReaderContext) Err() error {
+	if ctx.lookaheadErr != nil {
+		return ctx.lookaheadErr
+	}
+	if !ctx.IsEOF() {
+		return nil
+	}
+	if ctx.invalidByte >= 0 {
+		return fmt.Errorf("invalid UTF-8 byte 0x%02X at %v", ctx.invalidByte, ctx.GetNextOrigin().ToString())
+	}
+	if ctx.readerErr != nil {
+		return fmt.Errorf("%v at %v", ctx.readerErr, ctx.GetNextOrigin().ToString())
+	}
+	return nil
+}
+
+// Returns true if the input was stopped by an error, even if runes before the
+// failure remain to be consumed. See ReaderContext.Err.
+func (ctx *ReaderContext) failed() bool {
+	return ctx.lookaheadErr != nil || ctx.invalidByte >= 0 || ctx.readerErr != nil
+}
+
+// Returns the rune that is relative runes ahead of the current rune in the
+// input, and true if there is such a rune. See ReaderContext.PeekRune.
+func (ctx *ReaderContext) peekRune(relative int) (rune, bool) {
+	r := ctx.PeekRune(relative)
+	return r, relative < ctx.ringCount && ctx.lookaheadErr == nil
+}
+
+// Reads runes from the reader into the ring buffer until it holds at least
+// count runes, or the reader has no runes remaining.
+func (ctx *ReaderContext) fillRing(count int) {
+	if !ctx.decoded {
+		ctx.decodeReader()
+	}
+	if ctx.source != nil && ctx.ringCount < count && !ctx.readerDone {
+		defer ctx.updateSource(ctx.sourceContent.Len())
+	}
+	for ctx.ringCount < count && !ctx.readerDone {
+		r, size, err := ctx.reader.ReadRune()
+		if err != nil || size <= 0 {
+			if err != io.EOF {
+				ctx.readerErr = err
+			}
+			ctx.readerDone = true
+			return
+		}
+		if r == utf8.RuneError && size == 1 {
+			ctx.reader.UnreadRune()
+			b, _ := ctx.reader.ReadByte()
+			if ctx.invalidEncoding == InvalidEncodingError {
+				ctx.invalidByte = int(b)
+				ctx.readerDone = true
+				return
+			} else if ctx.invalidEncoding == InvalidEncodingRaw {
+				r = rune(b)
+			}
+			if ctx.source != nil {
+				ctx.sourceContent.WriteByte(b)
+			}
+		} else if ctx.source != nil {
+			ctx.sourceContent.WriteRune(r)
+		}
+		index := (ctx.ringHead + ctx.ringCount) % len(ctx.runeRing)
+		ctx.runeRing[index] = r
+		ctx.sizeRing[index] = size
+		ctx.ringCount++
+	}
+}
+
+// Appends the content read since its length was start to the SourceFile
+// registered with ReaderContextOptions.Registry.
+func (ctx *ReaderContext) updateSource(start int) {
+	content := ctx.sourceContent.String()
+	ctx.source.appendContent(content, content[start:])
+}
+
+// Wraps the reader with the Decoder from the ReaderContextOptions, or the
+// Decoder detected from the reader's byte order mark. This is done on the
+// first read, so that creating a ReaderContext never blocks on its reader.
+func (ctx *ReaderContext) decodeReader() {
+	ctx.decoded = true
+	decoder := ctx.decoder
+	if decoder == nil {
+		var bom []byte
+		if decoder, bom = detectByteOrderMark(ctx.reader); decoder == nil {
+			return
+		} else if bytes.Equal(bom, utf8ByteOrderMark) {
+			// UTF-8 input only needs its byte order mark stripped.
+			ctx.reader.Discard(len(bom))
+			return
+		}
+	}
+	ctx.reader = bufio.NewReader(decoder(ctx.reader))
 }
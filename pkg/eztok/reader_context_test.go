@@ -0,0 +1,79 @@
+package eztok
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReaderContextPeekRune(t *testing.T) {
+	content := "héllo\x00wörld"
+	runes := []rune(content)
+	ctx := NewReaderContextWithOptions(bufio.NewReader(strings.NewReader(content)), "test",
+		ReaderContextOptions{MaxLookahead: 4})
+	for consumed := 0; consumed <= len(runes); consumed++ {
+		for relative := 0; relative <= 4; relative++ {
+			want := NilRune
+			if consumed+relative < len(runes) {
+				want = runes[consumed+relative]
+			}
+			if got := ctx.PeekRune(relative); got != want {
+				t.Fatalf("PeekRune(%v) after %v runes = %q, want %q", relative, consumed, got, want)
+			}
+		}
+		if got, want := ctx.IsEOF(), consumed == len(runes); got != want {
+			t.Fatalf("IsEOF() after %v runes = %v, want %v", consumed, got, want)
+		}
+		ctx.NextRune()
+	}
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+}
+
+func TestReaderContextLookaheadLimit(t *testing.T) {
+	ctx := NewReaderContextWithOptions(bufio.NewReader(strings.NewReader("abcdefgh")), "test",
+		ReaderContextOptions{MaxLookahead: 4})
+	ctx.NextRune()
+	if r := ctx.PeekRune(5); r != NilRune {
+		t.Fatalf("PeekRune(5) = %q, want NilRune", r)
+	}
+	if !ctx.IsEOF() {
+		t.Fatalf("IsEOF() = false after peeking past the maximum lookahead")
+	}
+	if r := ctx.NextRune(); r != NilRune {
+		t.Fatalf("NextRune() = %q after peeking past the maximum lookahead, want NilRune", r)
+	}
+	err := ctx.Err()
+	if !errors.Is(err, ErrLookaheadLimit) || !strings.Contains(err.Error(), "test:1:2") {
+		t.Fatalf("Err() = %v, want an ErrLookaheadLimit at test:1:2", err)
+	}
+}
+
+func TestReaderContextLongStringMatchNode(t *testing.T) {
+	long := strings.Repeat("x", DefaultReaderContextMaxLookahead+10)
+	tizer := NewInOrderNodeTokenizer(
+		NewStringMatchNode("long", long),
+		NewRuneMatchNode("x", 'x'),
+	)
+	tests := []struct {
+		name string
+		ctx  Context
+	}{
+		{"ReaderContext", NewReaderContext(bufio.NewReader(strings.NewReader(long)), "test")},
+		{"MultiContext", NewMultiContext([]NamedReader{{"test", strings.NewReader(long)}}, NilRune, ReaderContextOptions{})},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := tizer.Tokenize(test.ctx)
+			if !errors.Is(err, ErrLookaheadLimit) {
+				t.Fatalf("Tokenize returned %v Token objects and error %v, want an ErrLookaheadLimit", len(toks), err)
+			}
+		})
+	}
+	toks, err := TokenizeString(tizer, long)
+	if err != nil || len(toks) != 1 || toks[0].TokenType != "long" {
+		t.Fatalf("TokenizeString returned %v Token objects and error %v, want one long Token", len(toks), err)
+	}
+}
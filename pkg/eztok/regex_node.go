@@ -0,0 +1,107 @@
+package eztok
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+)
+
+// Returns a new CallbackNode whose CanParseToken function returns true if
+// the regular expression pattern matches one or more of the next runes in the
+// Context, and whose ParseToken function returns a Token with a TokenType of
+// tokenType and a Value of the longest such match. The pattern uses the syntax
+// of the regexp package. Panics if the pattern is invalid.
+func NewRegexMatchNode(tokenType TokenType, pattern string) *CallbackNode {
+	re := compilePrefixRegex(pattern)
+	node := NewCallbackNode(
+		func(ctx Context) bool {
+			// A failed match is left for ParseToken to report.
+			_, runeCount, err := matchPrefixRegex(ctx, re)
+			return runeCount > 0 || err != nil
+		},
+		func(ctx Context) (*Token, error) {
+			value, runeCount, err := matchPrefixRegex(ctx, re)
+			if err != nil {
+				return nil, err
+			}
+			if runeCount <= 0 {
+				return nil, fmt.Errorf("expected a match of pattern '%v'", pattern)
+			}
+			SkipRunes(ctx, runeCount)
+			return NewToken(tokenType, value), nil
+		},
+	)
+	node.canParsePattern = pattern
+	return node
+}
+
+// Returns a new CallbackNode whose CanParseToken function returns true if the
+// regular expression canParsePattern matches one or more of the next runes in
+// the Context, and whose ParseToken function is parseCallback. The pattern
+// uses the syntax of the regexp package, and is also returned by
+// CallbackNode.CanParsePattern. Panics if the pattern is invalid.
+func NewPatternNode(canParsePattern string, parseCallback ParseTokenCallback) *CallbackNode {
+	re := compilePrefixRegex(canParsePattern)
+	node := NewCallbackNode(
+		func(ctx Context) bool {
+			_, runeCount, _ := matchPrefixRegex(ctx, re)
+			return runeCount > 0
+		},
+		parseCallback,
+	)
+	node.canParsePattern = canParsePattern
+	return node
+}
+
+// Returns the regular expression pattern compiled to match only at the start
+// of its input, preferring the longest match. Panics if the pattern is invalid.
+func compilePrefixRegex(pattern string) *regexp.Regexp {
+	re, err := regexp.Compile(`\A(?:` + pattern + `)`)
+	if err != nil {
+		log.Panicf("Cannot compile the pattern '%v': %v", pattern, err)
+	}
+	re.Longest()
+	return re
+}
+
+// Returns the longest match of re (compiled by compilePrefixRegex) against the
+// next runes in the Context, and the number of runes in the match. Does not
+// consume any runes. Returns an error wrapping ErrLookaheadLimit if the match
+// could continue beyond the maximum lookahead of the Context.
+func matchPrefixRegex(ctx Context, re *regexp.Regexp) (string, int, error) {
+	reader := &recordingRuneReader{reader: NewPeekRuneReader(ctx)}
+	loc := re.FindReaderIndex(reader)
+	if reader.err != nil && reader.err != io.EOF {
+		return "", 0, reader.err
+	}
+	if loc == nil {
+		return "", 0, nil
+	}
+	runeCount := 0
+	for size := 0; size < loc[1]; runeCount++ {
+		size += reader.sizes[runeCount]
+	}
+	return string(reader.runes[:runeCount]), runeCount, nil
+}
+
+// An io.RuneReader that records the runes read from the reader it wraps.
+type recordingRuneReader struct {
+	reader io.RuneReader
+	runes  []rune
+	sizes  []int
+	// The error that ended the wrapped reader, if any.
+	err error
+}
+
+// Reads and records the next rune of the wrapped reader.
+func (reader *recordingRuneReader) ReadRune() (rune, int, error) {
+	r, size, err := reader.reader.ReadRune()
+	if err == nil {
+		reader.runes = append(reader.runes, r)
+		reader.sizes = append(reader.sizes, size)
+	} else {
+		reader.err = err
+	}
+	return r, size, err
+}
@@ -0,0 +1,85 @@
+package eztok
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Returns a ReaderContext of content with a maximum lookahead of 8 runes.
+func newLookaheadTestContext(content string) *ReaderContext {
+	return NewReaderContextWithOptions(bufio.NewReader(strings.NewReader(content)), "test",
+		ReaderContextOptions{MaxLookahead: 8})
+}
+
+func TestRegexMatchNodeLookaheadLimit(t *testing.T) {
+	tizer := NewInOrderNodeTokenizer(SkipWhitespaceNode, NewRegexMatchNode("id", "[a-z]+"))
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"within the lookahead", "abc defgh", "abc defgh", false},
+		// The regexp package reads two runes beyond a match, the last of
+		// which is the last rune within the lookahead.
+		{"match ends at the lookahead", "abcdef gh", "abcdef gh", false},
+		{"beyond the lookahead", "ab abcdefghijkl", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := tizer.Tokenize(newLookaheadTestContext(test.input))
+			if test.wantErr {
+				if !errors.Is(err, ErrLookaheadLimit) {
+					t.Fatalf("Tokenize returned %v Token objects and error %v, want an ErrLookaheadLimit", len(toks), err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			values := []string{}
+			for _, tok := range toks {
+				values = append(values, tok.Value.(string))
+			}
+			if got := strings.Join(values, " "); got != test.want {
+				t.Fatalf("Tokenize values = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeNextLookaheadLimit(t *testing.T) {
+	tizer := NewInOrderNodeTokenizer(NewRegexMatchNode("id", "[a-z]+"))
+	_, err := tizer.TokenizeNext(newLookaheadTestContext(strings.Repeat("a", 20)))
+	if !errors.Is(err, ErrLookaheadLimit) {
+		t.Fatalf("TokenizeNext returned error %v, want an ErrLookaheadLimit", err)
+	}
+}
+
+func TestSpecTokenizerLookaheadLimit(t *testing.T) {
+	spec, err := ParseLexerSpec("skip /[ ]+/\nregex id /[a-z]+/\nliteral long \"xxxxxxxxxxxx\"\n", "test.lex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tizer := NewSpecTokenizer(spec)
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"short tokens", "ab cd", false},
+		{"long literal does not match", "xxxy", false},
+		{"long regex match", "abcdefghijk", true},
+		{"long literal matches", "xxxxxxxxxxxx", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := tizer.Tokenize(newLookaheadTestContext(test.input))
+			if test.wantErr != errors.Is(err, ErrLookaheadLimit) || (!test.wantErr && err != nil) {
+				t.Fatalf("Tokenize returned error %v, want an ErrLookaheadLimit: %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package eztok
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Returns a new unicode.RangeTable holding the runes.
+func newRuneTable(runes ...rune) *unicode.RangeTable {
+	ranges := make([][2]rune, len(runes))
+	for i, r := range runes {
+		ranges[i] = [2]rune{r, r}
+	}
+	return newRangeTable(ranges)
+}
+
+// Returns a new unicode.RangeTable holding the runes within each pair of first
+// and last runes of ranges.
+func newRangeTable(ranges [][2]rune) *unicode.RangeTable {
+	sorted := append([][2]rune{}, ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i][0] < sorted[j][0] })
+	merged := [][2]rune{}
+	for _, r := range sorted {
+		if last := len(merged) - 1; last >= 0 && r[0] <= merged[last][1]+1 {
+			if r[1] > merged[last][1] {
+				merged[last][1] = r[1]
+			}
+		} else {
+			merged = append(merged, r)
+		}
+	}
+
+	table := &unicode.RangeTable{}
+	for _, r := range merged {
+		if r[0] <= 0xFFFF {
+			hi := r[1]
+			if hi > 0xFFFF {
+				hi = 0xFFFF
+			}
+			table.R16 = append(table.R16, unicode.Range16{Lo: uint16(r[0]), Hi: uint16(hi), Stride: 1})
+			if hi <= unicode.MaxLatin1 {
+				table.LatinOffset++
+			}
+		}
+		if r[1] > 0xFFFF {
+			lo := r[0]
+			if lo <= 0xFFFF {
+				lo = 0x10000
+			}
+			table.R32 = append(table.R32, unicode.Range32{Lo: uint32(lo), Hi: uint32(r[1]), Stride: 1})
+		}
+	}
+	return table
+}
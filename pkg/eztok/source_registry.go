@@ -0,0 +1,199 @@
+package eztok
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Represents the contents of a named source (such as a file), along with the
+// byte offset of the start of each of its lines.
+type SourceFile struct {
+	// The name of the source. Matches the Origin.Name of its runes.
+	Name string
+	// The contents of the source.
+	Content string
+	// The options that Origin information of the source is tracked with.
+	Position PositionOptions
+	// The byte offset of the start of each line.
+	lineStarts []int
+	// True if SourceFile.Content ends with a '\r'.
+	afterCR bool
+}
+
+// Returns a new SourceFile with the provided parameters.
+func NewSourceFile(name string, content string, options PositionOptions) *SourceFile {
+	file := &SourceFile{name, "", options, []int{0}, false}
+	file.appendContent(content, content)
+	return file
+}
+
+// Returns the number of lines in the SourceFile.
+func (file *SourceFile) LineCount() int {
+	return len(file.lineStarts)
+}
+
+// Returns the text of line lineNum (starting at 1), without its line break.
+// Returns an empty string if there is no such line.
+func (file *SourceFile) Line(lineNum int) string {
+	if lineNum < 1 || lineNum > len(file.lineStarts) {
+		return ""
+	}
+	return file.Content[file.lineStarts[lineNum-1]:file.lineEnd(lineNum)]
+}
+
+// Returns the byte offset into SourceFile.Content of the position held by
+// origin. Returns an error if the position is not within the SourceFile.
+func (file *SourceFile) OffsetOf(origin *Origin) (int, error) {
+	if origin.LineNum < 1 || origin.LineNum > len(file.lineStarts) {
+		return 0, fmt.Errorf("line %v is not within '%v'", origin.LineNum, file.Name)
+	}
+	tracker := file.trackerAtLine(origin.LineNum)
+	lineEnd := file.lineEnd(origin.LineNum)
+	for tracker.colNum < origin.ColNum && tracker.offset < lineEnd {
+		r, size := utf8.DecodeRuneInString(file.Content[tracker.offset:])
+		next := tracker
+		next.advance(r, size)
+		if next.colNum > origin.ColNum {
+			// The column is within a rune that spans several columns.
+			break
+		}
+		tracker = next
+	}
+	if tracker.colNum < origin.ColNum {
+		return 0, fmt.Errorf("column %v is not within line %v of '%v'", origin.ColNum, origin.LineNum, file.Name)
+	}
+	return tracker.offset, nil
+}
+
+// Returns the Origin of the byte offset into SourceFile.Content. Returns an
+// error if the offset is not within the SourceFile.
+func (file *SourceFile) OriginAt(offset int) (*Origin, error) {
+	if offset < 0 || offset > len(file.Content) {
+		return nil, fmt.Errorf("offset %v is not within '%v'", offset, file.Name)
+	}
+	lineNum := sort.Search(len(file.lineStarts), func(i int) bool { return file.lineStarts[i] > offset })
+	tracker := file.trackerAtLine(lineNum)
+	for tracker.offset < offset {
+		r, size := utf8.DecodeRuneInString(file.Content[tracker.offset:])
+		tracker.advance(r, size)
+	}
+	return tracker.origin(file.Name), nil
+}
+
+// Returns a snippet of the line holding origin, followed by a line with a
+// caret ('^') under the position of origin, such as:
+//
+//	3 | float apples = -55.3;
+//	  |       ^
+//
+// Returns an error if the position is not within the SourceFile.
+func (file *SourceFile) Snippet(origin *Origin) (string, error) {
+	offset, err := file.OffsetOf(origin)
+	if err != nil {
+		return "", err
+	}
+	line := file.Line(origin.LineNum)
+	gutter := fmt.Sprintf(" %v | ", origin.LineNum)
+	caretIndent := strings.Builder{}
+	for _, r := range line[:offset-file.lineStarts[origin.LineNum-1]] {
+		if r == '\t' {
+			caretIndent.WriteRune('\t')
+		} else {
+			caretIndent.WriteString(strings.Repeat(" ", runeDisplayWidth(r)))
+		}
+	}
+	return fmt.Sprintf("%v%v\n%v| %v^", gutter, line, strings.Repeat(" ", len(gutter)-2),
+		caretIndent.String()), nil
+}
+
+// Appends text to SourceFile.Content, extending the start of each line over
+// it. SourceFile.Content is replaced by content, which must be the previous
+// SourceFile.Content followed by text, so that a caller building the content
+// (such as with a strings.Builder) can avoid copying it.
+func (file *SourceFile) appendContent(content string, text string) {
+	start := len(file.Content)
+	file.Content = content
+	for i, r := range text {
+		if r == '\n' && file.afterCR {
+			file.lineStarts[len(file.lineStarts)-1]++
+		} else if isLineBreakRune(r) {
+			file.lineStarts = append(file.lineStarts, start+i+utf8.RuneLen(r))
+		}
+		file.afterCR = r == '\r'
+	}
+}
+
+// Returns the byte offset of the end of line lineNum, excluding its line break.
+func (file *SourceFile) lineEnd(lineNum int) int {
+	start := file.lineStarts[lineNum-1]
+	if end := strings.IndexFunc(file.Content[start:], isLineBreakRune); end >= 0 {
+		return start + end
+	}
+	return len(file.Content)
+}
+
+// Returns a positionTracker at the start of line lineNum.
+func (file *SourceFile) trackerAtLine(lineNum int) positionTracker {
+	tracker := newPositionTracker(file.Position)
+	tracker.lineNum = lineNum
+	tracker.offset = file.lineStarts[lineNum-1]
+	return tracker
+}
+
+// Holds SourceFile objects by name, so that they can be shared by error
+// renderers and editor tooling. Safe for concurrent use. Nothing is registered
+// unless a SourceRegistry is passed explicitly: content is registered by
+// SourceRegistry.Register, the input of a ReaderContext by
+// ReaderContextOptions.Registry, and included sources by
+// Preprocessor.Registry.
+type SourceRegistry struct {
+	// The options that registered SourceFile objects track Origin
+	// information with.
+	Position PositionOptions
+	mutex    sync.Mutex
+	files    map[string]*SourceFile
+}
+
+// Returns a new empty SourceRegistry.
+func NewSourceRegistry() *SourceRegistry {
+	return &SourceRegistry{files: map[string]*SourceFile{}}
+}
+
+// Registers content under name, replacing any SourceFile already registered
+// under name. Returns the registered SourceFile.
+func (registry *SourceRegistry) Register(name string, content string) *SourceFile {
+	file := NewSourceFile(name, content, registry.Position)
+	registry.registerFile(file)
+	return file
+}
+
+// Registers file under SourceFile.Name, replacing any SourceFile already
+// registered under it.
+func (registry *SourceRegistry) registerFile(file *SourceFile) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if registry.files == nil {
+		registry.files = map[string]*SourceFile{}
+	}
+	registry.files[file.Name] = file
+}
+
+// Returns the SourceFile registered under name, or nil if there is none.
+func (registry *SourceRegistry) Lookup(name string) *SourceFile {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	return registry.files[name]
+}
+
+// Returns the snippet of the SourceFile registered under Origin.Name for origin.
+// See SourceFile.Snippet. Returns an error if there is no such SourceFile.
+func (registry *SourceRegistry) Snippet(origin *Origin) (string, error) {
+	file := registry.Lookup(origin.Name)
+	if file == nil {
+		return "", fmt.Errorf("no source registered for '%v'", origin.Name)
+	}
+	return file.Snippet(origin)
+}
@@ -0,0 +1,171 @@
+package eztok
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceFileLines(t *testing.T) {
+	file := NewSourceFile("test", "ab\r\ncd\ne\rf\r\n", PositionOptions{})
+	want := []string{"ab", "cd", "e", "f", ""}
+	if got := file.LineCount(); got != len(want) {
+		t.Fatalf("LineCount() = %v, want %v", got, len(want))
+	}
+	for i, line := range want {
+		if got := file.Line(i + 1); got != line {
+			t.Fatalf("Line(%v) = %q, want %q", i+1, got, line)
+		}
+	}
+}
+
+func TestSourceFileOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		options PositionOptions
+		lineNum int
+		colNum  int
+		offset  int
+	}{
+		{"start", "ab\ncd", PositionOptions{}, 1, 1, 0},
+		{"second line", "ab\ncd", PositionOptions{}, 2, 2, 4},
+		{"after CRLF", "ab\r\ncd", PositionOptions{}, 2, 1, 4},
+		{"multibyte runes", "é☃x", PositionOptions{}, 1, 3, 5},
+		{"byte columns", "é☃x", PositionOptions{ColumnMode: ColumnModeByte}, 1, 6, 5},
+		{"visual tab", "\tx", PositionOptions{ColumnMode: ColumnModeVisual, TabWidth: 4}, 1, 5, 1},
+		{"end of content", "ab", PositionOptions{}, 1, 3, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := NewSourceFile("test", test.content, test.options)
+			offset, err := file.OffsetOf(NewOrigin("test", test.lineNum, test.colNum))
+			if err != nil || offset != test.offset {
+				t.Fatalf("OffsetOf(%v:%v) = %v, %v, want %v", test.lineNum, test.colNum, offset, err, test.offset)
+			}
+			origin, err := file.OriginAt(test.offset)
+			if err != nil || origin.LineNum != test.lineNum || origin.ColNum != test.colNum || origin.Offset != test.offset {
+				t.Fatalf("OriginAt(%v) = %v, %v, want %v:%v", test.offset, origin, err, test.lineNum, test.colNum)
+			}
+		})
+	}
+}
+
+func TestSourceFileSnippet(t *testing.T) {
+	file := NewSourceFile("test", "int x;\n\tfloat apples = -55.3;\n", PositionOptions{})
+	snippet, err := file.Snippet(NewOrigin("test", 2, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := " 2 | \tfloat apples = -55.3;\n   | \t      ^"; snippet != want {
+		t.Fatalf("Snippet() = %q, want %q", snippet, want)
+	}
+	if _, err := file.Snippet(NewOrigin("test", 5, 1)); err == nil {
+		t.Fatalf("Snippet of a line past the end returned no error")
+	}
+}
+
+func TestReaderContextRegistersSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options ReaderContextOptions
+		want    string
+	}{
+		{"UTF-8", "ab\r\ncd\n", ReaderContextOptions{}, "ab\r\ncd\n"},
+		{"UTF-8 BOM", "\xEF\xBB\xBFab\ncd", ReaderContextOptions{}, "ab\ncd"},
+		{"UTF-16LE", "\xFF\xFEa\x00\n\x00b\x00", ReaderContextOptions{}, "a\nb"},
+		{"invalid byte", "a\xffb\nc", ReaderContextOptions{}, "a\xffb\nc"},
+		{"small lookahead", strings.Repeat("abc\n", 100), ReaderContextOptions{MaxLookahead: 2}, strings.Repeat("abc\n", 100)},
+		{"byte columns", "é\nx", ReaderContextOptions{Position: PositionOptions{ColumnMode: ColumnModeByte}}, "é\nx"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := NewSourceRegistry()
+			registry.Position = PositionOptions{ColumnMode: ColumnModeUTF16}
+			test.options.Registry = registry
+			ctx := NewReaderContextWithOptions(bufio.NewReader(strings.NewReader(test.input)), "test", test.options)
+			origins := []*Origin{}
+			for prev := NilRune; !ctx.IsEOF(); prev = ctx.NextRune() {
+				// The '\n' of a "\r\n" shares the line and column of the
+				// start of the next line.
+				if !(prev == '\r' && ctx.PeekRune(0) == '\n') {
+					origins = append(origins, ctx.GetNextOrigin())
+				}
+			}
+			file := registry.Lookup("test")
+			if file == nil || file.Content != test.want {
+				t.Fatalf("registered %+v, want content %q", file, test.want)
+			}
+			if file.Position != test.options.Position {
+				t.Fatalf("registered PositionOptions %+v, want %+v", file.Position, test.options.Position)
+			}
+			for _, origin := range origins {
+				if offset, err := file.OffsetOf(origin); err != nil || offset != origin.Offset {
+					t.Fatalf("OffsetOf(%v) = %v, %v, want %v", origin.ToString(), offset, err, origin.Offset)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenizeFileWithOptionsRegistersSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte("ab\ncd"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	registry := NewSourceRegistry()
+	tizer := NewInOrderNodeTokenizer(SkipWhitespaceNode, IdentifierNode)
+	toks, err := TokenizeFileWithOptions(tizer, path, ReaderContextOptions{Registry: registry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snippet, err := registry.Snippet(toks[1].Origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := " 2 | cd\n   | ^"; snippet != want {
+		t.Fatalf("Snippet() = %q, want %q", snippet, want)
+	}
+}
+
+func TestTokenizeFilesWithOptionsRegistersSources(t *testing.T) {
+	dir := t.TempDir()
+	contents := map[string]string{"a.txt": "ab\ncd", "b.txt": "ef"}
+	paths := []string{}
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	registry := NewSourceRegistry()
+	tizer := NewInOrderNodeTokenizer(SkipWhitespaceNode, IdentifierNode)
+	if _, err := TokenizeFilesWithOptions(tizer, ReaderContextOptions{Registry: registry}, paths...); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		if file := registry.Lookup(path); file == nil || file.Content != content {
+			t.Fatalf("registered %+v for %v, want content %q", file, path, content)
+		}
+	}
+}
+
+func TestPreprocessorRegistersIncludes(t *testing.T) {
+	registry := NewSourceRegistry()
+	pp := NewPreprocessor(newPreprocessorTestTokenizer(), MapIncludeResolver{"a.h": "#define A 1\nx"})
+	pp.Registry = registry
+	if _, err := TokenizeString(pp, "#include \"a.h\"\nA"); err != nil {
+		t.Fatal(err)
+	}
+	if file := registry.Lookup("a.h"); file == nil || file.Content != "#define A 1\nx" {
+		t.Fatalf("registered %+v, want the content of a.h", file)
+	}
+	if file := registry.Lookup(""); file != nil {
+		t.Fatalf("registered the tokenized string %+v", file)
+	}
+}
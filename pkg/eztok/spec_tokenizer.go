@@ -0,0 +1,125 @@
+package eztok
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// A Tokenizer that operates on the rules of a LexerSpec. At each point in the
+// input, the rule of the current LexerMode with the longest match is applied,
+// with ties going to the earliest rule.
+type SpecTokenizer struct {
+	// The LexerSpec being operated on.
+	Spec  *LexerSpec
+	modes map[string]*specTokenizerMode
+}
+
+// The compiled rules of a LexerMode.
+type specTokenizerMode struct {
+	rules []*LexerRule
+	// The compiled pattern of each rule, or nil for a LexerRuleLiteral.
+	regexes []*regexp.Regexp
+	// The number of runes in the pattern of each LexerRuleLiteral.
+	literalLengths []int
+}
+
+// Returns a new SpecTokenizer with the provided parameters. The spec must be
+// one returned by ParseLexerSpec or LoadLexerSpec.
+func NewSpecTokenizer(spec *LexerSpec) *SpecTokenizer {
+	modes := map[string]*specTokenizerMode{}
+	for _, mode := range spec.Modes {
+		compiled := &specTokenizerMode{mode.Rules, make([]*regexp.Regexp, len(mode.Rules)), make([]int, len(mode.Rules))}
+		for i, rule := range mode.Rules {
+			if rule.Kind == LexerRuleLiteral {
+				compiled.literalLengths[i] = utf8.RuneCountInString(rule.Pattern)
+			} else {
+				compiled.regexes[i] = compilePrefixRegex(rule.Pattern)
+			}
+		}
+		modes[mode.Name] = compiled
+	}
+	return &SpecTokenizer{spec, modes}
+}
+
+// Returns a new SpecTokenizer for the lexer spec in the file at path. See
+// LoadLexerSpec.
+func LoadSpecTokenizer(path string) (*SpecTokenizer, error) {
+	spec, err := LoadLexerSpec(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSpecTokenizer(spec), nil
+}
+
+// For as long as IsEOF does not return true, applies the rule of the
+// current LexerMode with the longest match. A rule that is not a LexerRuleSkip
+// generates a Token whose Origin is the start of the match. Returns an error if
+// no rule matches, or a rule pops the initial LexerMode.
+func (tizer *SpecTokenizer) Tokenize(ctx Context) ([]*Token, error) {
+	toks := []*Token{}
+	modeStack := []*specTokenizerMode{tizer.modes[tizer.Spec.Modes[0].Name]}
+	for !IsEOF(ctx) {
+		mode := modeStack[len(modeStack)-1]
+		rule, value, runeCount, err := mode.match(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if rule == nil {
+			if err := contextErr(ctx); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("unexpected rune '%c' at %v", ctx.PeekRune(0), ctx.GetNextOrigin().ToString())
+		}
+		origin := ctx.GetNextOrigin()
+		SkipRunes(ctx, runeCount)
+		if rule.Kind != LexerRuleSkip {
+			tok := NewToken(rule.TokenType, value)
+			tok.Origin = origin
+			toks = append(toks, tok)
+		}
+
+		switch rule.Action {
+		case LexerActionPush:
+			modeStack = append(modeStack, tizer.modes[rule.PushMode])
+		case LexerActionPop:
+			if len(modeStack) <= 1 {
+				return nil, fmt.Errorf("cannot pop the initial mode '%v' at %v", tizer.Spec.Modes[0].Name, origin.ToString())
+			}
+			modeStack = modeStack[:len(modeStack)-1]
+		}
+	}
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
+	return toks, nil
+}
+
+// Returns the rule with the longest match against the next runes in the
+// Context, along with the matched string and its number of runes. Ties go to
+// the earliest rule. Returns a nil rule if no rule matches. Returns an error
+// wrapping ErrLookaheadLimit if a match could continue beyond the maximum
+// lookahead of the Context.
+func (mode *specTokenizerMode) match(ctx Context) (*LexerRule, string, int, error) {
+	var bestRule *LexerRule
+	bestValue, bestCount := "", 0
+	for i, rule := range mode.rules {
+		value, runeCount := "", 0
+		if re := mode.regexes[i]; re != nil {
+			var err error
+			if value, runeCount, err = matchPrefixRegex(ctx, re); err != nil {
+				return nil, "", 0, err
+			}
+		} else if length := mode.literalLengths[i]; length > bestCount && HasPrefix(ctx, rule.Pattern) {
+			value, runeCount = rule.Pattern, length
+		}
+		if err := contextErr(ctx); err != nil && errors.Is(err, ErrLookaheadLimit) {
+			return nil, "", 0, err
+		}
+		if runeCount > bestCount {
+			bestRule, bestValue, bestCount = rule, value, runeCount
+		}
+	}
+	return bestRule, bestValue, bestCount, nil
+}
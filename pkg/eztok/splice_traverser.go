@@ -0,0 +1,118 @@
+package eztok
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// The default SpliceTraverser.MaxDepth used by NewSpliceTraverser.
+const DefaultMaxSpliceDepth = 200
+
+// Represents one source of Token objects within a SpliceTraverser.
+type spliceSource struct {
+	// The name of this source. Often this is the path of an included file.
+	name string
+	// The Token objects of this source.
+	tokens []*Token
+	// The index of the next Token to consume from tokens.
+	index int
+	// The source that was being consumed when this source was spliced in.
+	// Nil for the root source.
+	parent *spliceSource
+	// The number of sources in the chain from the root source to this one.
+	depth int
+}
+
+// A Traverser that operates on a stack of named Token sources. New sources
+// can be spliced in ahead of the remaining Tokens in O(1), which makes it
+// suitable for implementing include statements and macro expansion.
+//
+// Each spliced source remembers the source it was spliced from, forming an
+// include chain. Splicing a source whose name already appears in the include
+// chain of the most recently consumed Token is reported as a cycle.
+type SpliceTraverser struct {
+	// The maximum length of an include chain. A value <= 0 means there is
+	// no maximum.
+	MaxDepth int
+	// The stack of sources with Tokens remaining. The last source is the
+	// one Tokens are consumed from next.
+	stack []*spliceSource
+	// The source of the most recently consumed Token.
+	current *spliceSource
+}
+
+// Returns a new SpliceTraverser whose root source is named name and holds
+// tokens. MaxDepth defaults to DefaultMaxSpliceDepth.
+func NewSpliceTraverser(name string, tokens []*Token) *SpliceTraverser {
+	root := &spliceSource{name, tokens, 0, nil, 1}
+	return &SpliceTraverser{DefaultMaxSpliceDepth, []*spliceSource{root}, root}
+}
+
+// Return the Token that is relative Tokens ahead of the current Token across
+// all sources. Returns nil if there is none.
+func (trav *SpliceTraverser) PeekToken(relative int) *Token {
+	if relative < 0 {
+		log.Panicf("PeekToken cannot peek negatively; tried peeking a relative '%v' tokens", relative)
+	}
+	for i := len(trav.stack) - 1; i >= 0; i-- {
+		source := trav.stack[i]
+		remaining := len(source.tokens) - source.index
+		if relative < remaining {
+			return source.tokens[source.index+relative]
+		}
+		relative -= remaining
+	}
+	return nil
+}
+
+// Consume (i.e. advance the stream by 1 Token) and return the consumed
+// Token. Returns nil if no Tokens remain in any source.
+func (trav *SpliceTraverser) NextToken() *Token {
+	for len(trav.stack) > 0 {
+		source := trav.stack[len(trav.stack)-1]
+		if source.index < len(source.tokens) {
+			tok := source.tokens[source.index]
+			source.index++
+			trav.current = source
+			return tok
+		}
+		trav.stack = trav.stack[:len(trav.stack)-1]
+	}
+	return nil
+}
+
+// Splices tokens in ahead of all remaining Tokens as a new source named name.
+// The new source's parent is the source of the most recently consumed Token.
+// Returns an error if name already appears in that source's include chain,
+// or if the include chain would become longer than SpliceTraverser.MaxDepth.
+func (trav *SpliceTraverser) Splice(name string, tokens []*Token) error {
+	for source := trav.current; source != nil; source = source.parent {
+		if source.name == name {
+			return fmt.Errorf("cyclic splice of '%v' (%v)", name,
+				strings.Join(append(trav.IncludeChain(), name), " -> "))
+		}
+	}
+	depth := trav.current.depth + 1
+	if trav.MaxDepth > 0 && depth > trav.MaxDepth {
+		return fmt.Errorf("splice of '%v' exceeds the maximum depth of %v", name, trav.MaxDepth)
+	}
+	trav.stack = append(trav.stack, &spliceSource{name, tokens, 0, trav.current, depth})
+	return nil
+}
+
+// Returns the number of sources in the include chain of the most recently
+// consumed Token. The root source has a depth of 1.
+func (trav *SpliceTraverser) IncludeDepth() int {
+	return trav.current.depth
+}
+
+// Returns the names of the sources in the include chain of the most recently
+// consumed Token, starting with the root source.
+func (trav *SpliceTraverser) IncludeChain() []string {
+	chain := make([]string, trav.current.depth)
+	for source := trav.current; source != nil; source = source.parent {
+		chain[source.depth-1] = source.name
+	}
+	return chain
+}
@@ -0,0 +1,117 @@
+package eztok
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpliceTraverserCycles(t *testing.T) {
+	tests := []struct {
+		name string
+		// The names of the sources to splice, each after consuming one Token
+		// of the previous source.
+		splices []string
+		// The index of the splice expected to fail, or -1 if none.
+		failAt  int
+		errText string
+	}{
+		{"no cycle", []string{"a.h", "b.h", "c.h"}, -1, ""},
+		{"root cycle", []string{"a.h", "main.c"}, 1, "cyclic splice of 'main.c' (main.c -> a.h -> main.c)"},
+		{"self cycle", []string{"a.h", "a.h"}, 1, "cyclic splice of 'a.h' (main.c -> a.h -> a.h)"},
+		{"indirect cycle", []string{"a.h", "b.h", "a.h"}, 2, "cyclic splice of 'a.h' (main.c -> a.h -> b.h -> a.h)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trav := NewSpliceTraverser("main.c", spliceTestTokens("main", 2))
+			trav.NextToken()
+			for i, name := range test.splices {
+				err := trav.Splice(name, spliceTestTokens(name, 2))
+				if i == test.failAt {
+					if err == nil || err.Error() != test.errText {
+						t.Fatalf("Splice(%q) returned error %v, want %q", name, err, test.errText)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Splice(%q) returned unexpected error: %v", name, err)
+				}
+				trav.NextToken()
+			}
+			if test.failAt >= 0 {
+				t.Fatalf("expected splice %v to fail", test.failAt)
+			}
+		})
+	}
+}
+
+func TestSpliceTraverserSiblingsAreNotCycles(t *testing.T) {
+	trav := NewSpliceTraverser("main.c", spliceTestTokens("main", 3))
+	trav.NextToken()
+	if err := trav.Splice("a.h", spliceTestTokens("a", 1)); err != nil {
+		t.Fatal(err)
+	}
+	trav.NextToken()
+	// Consuming the next Token of main.c returns to the root source, so a.h
+	// may be spliced again.
+	trav.NextToken()
+	if err := trav.Splice("a.h", spliceTestTokens("a", 1)); err != nil {
+		t.Fatalf("re-including a sibling returned unexpected error: %v", err)
+	}
+	trav.NextToken()
+	if got, want := trav.IncludeChain(), []string{"main.c", "a.h"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("IncludeChain() = %v, want %v", got, want)
+	}
+}
+
+func TestSpliceTraverserMaxDepth(t *testing.T) {
+	trav := NewSpliceTraverser("main.c", spliceTestTokens("main", 1))
+	trav.MaxDepth = 3
+	trav.NextToken()
+	for _, name := range []string{"a.h", "b.h"} {
+		if err := trav.Splice(name, spliceTestTokens(name, 1)); err != nil {
+			t.Fatal(err)
+		}
+		trav.NextToken()
+	}
+	if got := trav.IncludeDepth(); got != 3 {
+		t.Fatalf("IncludeDepth() = %v, want 3", got)
+	}
+	err := trav.Splice("c.h", spliceTestTokens("c", 1))
+	if err == nil || !strings.Contains(err.Error(), "maximum depth of 3") {
+		t.Fatalf("Splice past MaxDepth returned error %v", err)
+	}
+}
+
+func TestSpliceTraverserOrder(t *testing.T) {
+	trav := NewSpliceTraverser("main.c", spliceTestTokens("main", 2))
+	if tok := trav.PeekToken(0); tok.Value != "main0" {
+		t.Fatalf("PeekToken(0) = %v, want main0", tok.Value)
+	}
+	trav.NextToken()
+	if err := trav.Splice("a.h", spliceTestTokens("a", 2)); err != nil {
+		t.Fatal(err)
+	}
+	if tok := trav.PeekToken(2); tok.Value != "main1" {
+		t.Fatalf("PeekToken(2) = %v, want main1", tok.Value)
+	}
+	got := []any{}
+	for tok := trav.NextToken(); tok != nil; tok = trav.NextToken() {
+		got = append(got, tok.Value)
+	}
+	if want := []any{"a0", "a1", "main1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("NextToken values = %v, want %v", got, want)
+	}
+	if tok := trav.PeekToken(0); tok != nil {
+		t.Fatalf("PeekToken(0) after the end = %v, want nil", tok)
+	}
+}
+
+// Returns n Token objects whose values are prefix followed by their index.
+func spliceTestTokens(prefix string, n int) []*Token {
+	toks := make([]*Token, n)
+	for i := range toks {
+		toks[i] = NewToken("test", prefix+string(rune('0'+i)))
+	}
+	return toks
+}
@@ -0,0 +1,90 @@
+package eztok
+
+// Represents one element of a Token pattern used by MatchPattern.
+type TokenMatcher struct {
+	// Returns true if tok matches this element. tok is never nil.
+	Match func(tok *Token) bool
+	// True if this element may be skipped when it does not match.
+	Optional bool
+}
+
+// Returns a TokenMatcher that matches a Token whose Token.TokenType is tokenType.
+func MatchType(tokenType TokenType) TokenMatcher {
+	return TokenMatcher{func(tok *Token) bool {
+		return tok.TokenType == tokenType
+	}, false}
+}
+
+// Returns a TokenMatcher that matches a Token whose Token.TokenType is tokenType
+// and whose Token.Value is value.
+func MatchValue(tokenType TokenType, value any) TokenMatcher {
+	return TokenMatcher{func(tok *Token) bool {
+		return tok.TokenType == tokenType && tok.Value == value
+	}, false}
+}
+
+// Returns a TokenMatcher that matches a Token whose Token.TokenType is any of
+// tokenTypes.
+func MatchAnyType(tokenTypes ...TokenType) TokenMatcher {
+	return TokenMatcher{func(tok *Token) bool {
+		for _, tokenType := range tokenTypes {
+			if tok.TokenType == tokenType {
+				return true
+			}
+		}
+		return false
+	}, false}
+}
+
+// Returns a TokenMatcher that matches any Token (i.e. a wildcard).
+func MatchAny() TokenMatcher {
+	return TokenMatcher{func(tok *Token) bool {
+		return true
+	}, false}
+}
+
+// Returns a copy of matcher that may be skipped when it does not match.
+func MatchOptional(matcher TokenMatcher) TokenMatcher {
+	matcher.Optional = true
+	return matcher
+}
+
+// Returns true if the Token objects at the front of trav match the pattern
+// described by matchers, along with the number of Token objects that matched.
+// Returns false and 0 otherwise. No Token objects are consumed.
+//
+// Optional matchers match their Token if they can, but are skipped if doing so
+// is the only way for the rest of the pattern to match.
+func MatchPattern(trav Traverser, matchers ...TokenMatcher) (bool, int) {
+	return matchPatternAt(trav, 0, matchers)
+}
+
+// Returns true if the Token objects at the front of trav have the given
+// TokenType values, in-order. No Token objects are consumed.
+func PeekSequence(trav Traverser, tokenTypes ...TokenType) bool {
+	for i, tokenType := range tokenTypes {
+		tok := trav.PeekToken(i)
+		if tok == nil || tok.TokenType != tokenType {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if the Token objects starting relative Tokens ahead in trav match
+// matchers, along with the number of Token objects that matched.
+func matchPatternAt(trav Traverser, relative int, matchers []TokenMatcher) (bool, int) {
+	if len(matchers) <= 0 {
+		return true, 0
+	}
+	matcher := matchers[0]
+	if tok := trav.PeekToken(relative); tok != nil && matcher.Match(tok) {
+		if ok, count := matchPatternAt(trav, relative+1, matchers[1:]); ok {
+			return true, count + 1
+		}
+	}
+	if matcher.Optional {
+		return matchPatternAt(trav, relative, matchers[1:])
+	}
+	return false, 0
+}
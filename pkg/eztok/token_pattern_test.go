@@ -0,0 +1,118 @@
+package eztok
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	optionalB := MatchOptional(MatchType("b"))
+	tests := []struct {
+		name     string
+		tokens   string
+		matchers []TokenMatcher
+		ok       bool
+		count    int
+	}{
+		{"full match", "a b c", []TokenMatcher{MatchType("a"), MatchType("b"), MatchType("c")}, true, 3},
+		{"prefix match", "a b c", []TokenMatcher{MatchType("a"), MatchType("b")}, true, 2},
+		{"partial match", "a b c", []TokenMatcher{MatchType("a"), MatchType("x"), MatchType("c")}, false, 0},
+		{"no matchers", "a", nil, true, 0},
+		{"no tokens", "", []TokenMatcher{MatchAny()}, false, 0},
+		{"match reaching EOF", "a b", []TokenMatcher{MatchType("a"), MatchType("b")}, true, 2},
+		{"match past EOF", "a b", []TokenMatcher{MatchType("a"), MatchType("b"), MatchAny()}, false, 0},
+		{"optional past EOF", "a b", []TokenMatcher{MatchType("a"), MatchType("b"), MatchOptional(MatchAny())}, true, 2},
+		{"optional matched", "a b c", []TokenMatcher{MatchType("a"), optionalB, MatchType("c")}, true, 3},
+		{"optional skipped", "a c", []TokenMatcher{MatchType("a"), optionalB, MatchType("c")}, true, 2},
+		{"optional skipped for the rest", "a b", []TokenMatcher{MatchType("a"), optionalB, MatchType("b")}, true, 2},
+		{"value", "a b", []TokenMatcher{MatchValue("a", "a"), MatchValue("b", "x")}, false, 0},
+		{"any type", "a b", []TokenMatcher{MatchAnyType("x", "a"), MatchAnyType("b")}, true, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trav := NewTokenTraverser(patternTestTokens(test.tokens))
+			ok, count := MatchPattern(trav, test.matchers...)
+			if ok != test.ok || count != test.count {
+				t.Fatalf("MatchPattern returned %v, %v, want %v, %v", ok, count, test.ok, test.count)
+			}
+			if got := len(trav.Tokens); got != len(patternTestTokens(test.tokens)) {
+				t.Fatalf("MatchPattern consumed %v Tokens", len(patternTestTokens(test.tokens))-got)
+			}
+		})
+	}
+}
+
+func TestMatchPatternLookaheadLimit(t *testing.T) {
+	matchers := []TokenMatcher{MatchType("a"), MatchType("b"), MatchType("c")}
+	tests := []struct {
+		name  string
+		limit int
+		ok    bool
+		count int
+	}{
+		{"match within the limit", 3, true, 3},
+		{"match beyond the limit", 2, false, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trav := &patternTestTraverser{NewTokenTraverser(patternTestTokens("a b c d")), test.limit}
+			if ok, count := MatchPattern(trav, matchers...); ok != test.ok || count != test.count {
+				t.Fatalf("MatchPattern returned %v, %v, want %v, %v", ok, count, test.ok, test.count)
+			}
+			if ok := PeekSequence(trav, "a", "b", "c"); ok != test.ok {
+				t.Fatalf("PeekSequence returned %v, want %v", ok, test.ok)
+			}
+		})
+	}
+}
+
+func TestPeekSequence(t *testing.T) {
+	tests := []struct {
+		name       string
+		tokens     string
+		tokenTypes []TokenType
+		want       bool
+	}{
+		{"full match", "a b c", []TokenType{"a", "b", "c"}, true},
+		{"prefix match", "a b c", []TokenType{"a", "b"}, true},
+		{"partial match", "a b c", []TokenType{"a", "c"}, false},
+		{"no token types", "", nil, true},
+		{"match reaching EOF", "a b", []TokenType{"a", "b"}, true},
+		{"match past EOF", "a b", []TokenType{"a", "b", "c"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trav := NewTokenTraverser(patternTestTokens(test.tokens))
+			if got := PeekSequence(trav, test.tokenTypes...); got != test.want {
+				t.Fatalf("PeekSequence returned %v, want %v", got, test.want)
+			}
+			if got := len(trav.Tokens); got != len(patternTestTokens(test.tokens)) {
+				t.Fatalf("PeekSequence consumed %v Tokens", len(patternTestTokens(test.tokens))-got)
+			}
+		})
+	}
+}
+
+// A Traverser that cannot peek limit or more Tokens ahead, as if its Token
+// stream ended there.
+type patternTestTraverser struct {
+	*TokenTraverser
+	limit int
+}
+
+func (trav *patternTestTraverser) PeekToken(relative int) *Token {
+	if relative >= trav.limit {
+		return nil
+	}
+	return trav.TokenTraverser.PeekToken(relative)
+}
+
+// Returns a Token for each space-separated word of tokens, whose TokenType and
+// Value are the word.
+func patternTestTokens(tokens string) []*Token {
+	toks := []*Token{}
+	for _, word := range strings.Fields(tokens) {
+		toks = append(toks, NewToken(TokenType(word), word))
+	}
+	return toks
+}
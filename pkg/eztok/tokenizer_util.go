@@ -3,7 +3,6 @@ package eztok
 import (
 	"bufio"
 	"os"
-	"strings"
 )
 
 // The string used as the Origin.Name of a string that has been tokenized.
@@ -12,17 +11,61 @@ const TokenizeStringOriginName = "<string>"
 // Tokenize content using the provided Tokenizer. The Origin.Name of the Token objects
 // generated will be TokenizeStringOrigin.
 func TokenizeString(tokenizer Tokenizer, content string) ([]*Token, error) {
-	return tokenizer.Tokenize(NewReaderContext(
-		bufio.NewReader(strings.NewReader(content)), TokenizeStringOriginName))
+	return TokenizeNamedString(tokenizer, content, TokenizeStringOriginName)
+}
+
+// Tokenize content using the provided Tokenizer. The Origin.Name of the Token objects
+// generated will be originName. To keep content for error reporting, register it
+// with SourceRegistry.Register.
+func TokenizeNamedString(tokenizer Tokenizer, content string, originName string) ([]*Token, error) {
+	return tokenizer.Tokenize(NewStringContext(content, originName))
+}
+
+// Tokenize content using the provided Tokenizer. The Origin.Name of the Token objects
+// generated will be originName. To keep content for error reporting, register it
+// with SourceRegistry.Register.
+func TokenizeBytes(tokenizer Tokenizer, content []byte, originName string) ([]*Token, error) {
+	return tokenizer.Tokenize(NewByteContext(content, originName))
 }
 
 // Tokenize the contents of the file at path using the provided Tokenizer. The Origin.Name
-// of the Token objects generated will be path.
+// of the Token objects generated will be path. The encoding of the file is detected
+// from its byte order mark, defaulting to UTF-8.
 func TokenizeFile(tokenizer Tokenizer, path string) ([]*Token, error) {
+	return TokenizeFileWithOptions(tokenizer, path, ReaderContextOptions{})
+}
+
+// Tokenize the concatenated contents of the files at paths using the provided
+// Tokenizer, as if they were a single input. The Origin.Name of the Token objects
+// generated will be the path of the file they came from.
+func TokenizeFiles(tokenizer Tokenizer, paths ...string) ([]*Token, error) {
+	return TokenizeFilesWithOptions(tokenizer, ReaderContextOptions{}, paths...)
+}
+
+// Tokenize the concatenated contents of the files at paths using the provided
+// Tokenizer and ReaderContextOptions, as if they were a single input. The
+// Origin.Name of the Token objects generated will be the path of the file they
+// came from.
+func TokenizeFilesWithOptions(tokenizer Tokenizer, options ReaderContextOptions, paths ...string) ([]*Token, error) {
+	sources := make([]NamedReader, len(paths))
+	for i, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		sources[i] = NamedReader{path, file}
+	}
+	return tokenizer.Tokenize(NewMultiContext(sources, NilRune, options))
+}
+
+// Tokenize the contents of the file at path using the provided Tokenizer and
+// ReaderContextOptions. The Origin.Name of the Token objects generated will be path.
+func TokenizeFileWithOptions(tokenizer Tokenizer, path string, options ReaderContextOptions) ([]*Token, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
-	return tokenizer.Tokenize(NewReaderContext(bufio.NewReader(file), path))
+	return tokenizer.Tokenize(NewReaderContextWithOptions(bufio.NewReader(file), path, options))
 }